@@ -0,0 +1,275 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package cepstrum
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+
+	"github.com/zikichombo/dsp/fft"
+	"github.com/zikichombo/dsp/mathutil"
+)
+
+// DefaultFloor is the default floor applied to spectral magnitudes before
+// taking logarithms.
+const DefaultFloor = 1e-10
+
+// T computes cepstra of a fixed size.
+type T struct {
+	n     int
+	m     int // number of non-negative frequency bins
+	ft    *fft.Real
+	isc   float64 // scale of the unscaled inverse of ft
+	buf   []float64
+	ph    []float64
+	floor float64
+}
+
+// New creates a new T for cepstra of length n.  n must be positive or New
+// panics.
+func New(n int) *T {
+	if n <= 0 {
+		panic("non-positive size")
+	}
+	ft := fft.NewReal(n)
+	ft.Scale(false)
+	m := n/2 + 1
+	// unscaled, the inverse gives n times the data for odd n and n/2
+	// times for even n.
+	isc := 1 / float64(n)
+	if n&1 == 0 {
+		isc *= 2
+	}
+	return &T{
+		n:     n,
+		m:     m,
+		ft:    ft,
+		isc:   isc,
+		buf:   make([]float64, n),
+		ph:    make([]float64, m),
+		floor: DefaultFloor}
+}
+
+// N returns the length of the cepstra of t.
+func (t *T) N() int {
+	return t.n
+}
+
+// SetFloor sets the floor applied to spectral magnitudes before taking
+// logarithms, which defaults to DefaultFloor.  Magnitudes are those of an
+// unscaled transform.
+func (t *T) SetFloor(v float64) {
+	t.floor = v
+}
+
+func (t *T) ck(d []float64) error {
+	if len(d) != t.n {
+		return fmt.Errorf("wrong length %d != %d", len(d), t.n)
+	}
+	return nil
+}
+
+func (t *T) logMag(c complex128) float64 {
+	m := cmplx.Abs(c)
+	if !(m >= t.floor) {
+		m = t.floor
+	}
+	return math.Log(m)
+}
+
+// inv inverse transforms hc, which is t.buf, to dst.
+func (t *T) inv(dst []float64, hc fft.HalfComplex) []float64 {
+	t.ft.Inv(hc)
+	if cap(dst) < t.n {
+		dst = make([]float64, t.n)
+	}
+	dst = dst[:t.n]
+	for i, v := range t.buf {
+		dst[i] = v * t.isc
+	}
+	return dst
+}
+
+// Real places the real cepstrum of d, the inverse transform of the log
+// magnitude of the spectrum of d, in dst, returning dst if it has
+// sufficient capacity or a new slice otherwise.  dst may be d.
+//
+// Real returns a non-nil error if d does not have length t.N().
+func (t *T) Real(dst, d []float64) ([]float64, error) {
+	if e := t.ck(d); e != nil {
+		return nil, e
+	}
+	copy(t.buf, d)
+	hc := t.ft.Do(t.buf)
+	for i := 0; i < t.m; i++ {
+		hc.SetCmplx(i, complex(t.logMag(hc.Cmplx(i)), 0))
+	}
+	return t.inv(dst, hc), nil
+}
+
+// Complex places the complex cepstrum of d, the inverse transform of the
+// complex log of the spectrum of d, in dst as in Real.
+//
+// The phase of the spectrum is unwrapped by accumulating the principal
+// value of the phase differences of successive frequency bins, so d should
+// be zero padded such that phases change by much less than pi between
+// bins.  The linear phase of a circular shift, which would otherwise
+// dominate the cepstrum, is removed and Complex returns the shift, delay,
+// in samples.  If the sum of d is negative, then Complex returns the
+// complex cepstrum of -d.
+//
+// Complex returns a non-nil error if d does not have length t.N().
+func (t *T) Complex(dst, d []float64) (c []float64, delay int, e error) {
+	if e := t.ck(d); e != nil {
+		return nil, 0, e
+	}
+	copy(t.buf, d)
+	hc := t.ft.Do(t.buf)
+	if hc[0] < 0 {
+		for i := range hc {
+			hc[i] = -hc[i]
+		}
+	}
+	ph := t.ph
+	ph[0] = 0
+	for i := 1; i < t.m; i++ {
+		ph[i] = ph[i-1] + mathutil.PrincArg(cmplx.Phase(hc.Cmplx(i))-ph[i-1])
+	}
+	if last := t.m - 1; last > 0 {
+		delay = int(math.Floor(-ph[last]*float64(t.n)/(2*math.Pi*float64(last)) + 0.5))
+	}
+	w := 2 * math.Pi * float64(delay) / float64(t.n)
+	for i := 0; i < t.m; i++ {
+		hc.SetCmplx(i, complex(t.logMag(hc.Cmplx(i)), ph[i]+w*float64(i)))
+	}
+	return t.inv(dst, hc), delay, nil
+}
+
+// InvComplex places the signal whose complex cepstrum is c and whose
+// circular shift is delay, as returned by Complex, in dst, returning dst
+// if it has sufficient capacity or a new slice otherwise.
+//
+// InvComplex returns a non-nil error if c does not have length t.N().
+func (t *T) InvComplex(dst, c []float64, delay int) ([]float64, error) {
+	if e := t.ck(c); e != nil {
+		return nil, e
+	}
+	copy(t.buf, c)
+	hc := t.ft.Do(t.buf)
+	w := 2 * math.Pi * float64(delay) / float64(t.n)
+	for i := 0; i < t.m; i++ {
+		v := hc.Cmplx(i)
+		hc.SetCmplx(i, cmplx.Rect(math.Exp(real(v)), imag(v)-w*float64(i)))
+	}
+	return t.inv(dst, hc), nil
+}
+
+// LogMag places the log magnitude spectrum at frequency bins 0 through
+// t.N()/2 corresponding to the real cepstrum c in dst, returning dst if it
+// has sufficient capacity or a new slice otherwise.  Applied to a
+// liftered cepstrum, LogMag gives a smoothed spectral envelope.
+//
+// LogMag returns a non-nil error if c does not have length t.N().
+func (t *T) LogMag(dst, c []float64) ([]float64, error) {
+	if e := t.ck(c); e != nil {
+		return nil, e
+	}
+	copy(t.buf, c)
+	hc := t.ft.Do(t.buf)
+	if cap(dst) < t.m {
+		dst = make([]float64, t.m)
+	}
+	dst = dst[:t.m]
+	for i := range dst {
+		dst[i] = hc.Real(i)
+	}
+	return dst, nil
+}
+
+// MinPhase places the minimum phase signal with the same magnitude
+// spectrum as d, computed by folding the real cepstrum of d, in dst as in
+// Real.  As with Complex, d should be zero padded so that the result does
+// not alias.
+//
+// MinPhase returns a non-nil error if d does not have length t.N().
+func (t *T) MinPhase(dst, d []float64) ([]float64, error) {
+	c, e := t.Real(dst, d)
+	if e != nil {
+		return nil, e
+	}
+	Fold(c)
+	copy(t.buf, c)
+	hc := t.ft.Do(t.buf)
+	for i := 0; i < t.m; i++ {
+		hc.SetCmplx(i, cmplx.Exp(hc.Cmplx(i)))
+	}
+	return t.inv(c, hc), nil
+}
+
+// Fold folds the cepstrum c in place, adding negative quefrencies to the
+// corresponding positive ones and zeroing them, so that c becomes the
+// cepstrum of a minimum phase signal.
+func Fold(c []float64) {
+	n := len(c)
+	for k := 1; 2*k < n; k++ {
+		c[k] += c[n-k]
+		c[n-k] = 0
+	}
+}
+
+// LowLifter zeros the coefficients of the cepstrum c with quefrencies,
+// positive or negative, of at least l samples in place.
+func LowLifter(c []float64, l int) {
+	if l < 1 {
+		l = 1
+	}
+	for k := l; k <= len(c)-l; k++ {
+		c[k] = 0
+	}
+}
+
+// HighLifter zeros the coefficients of the cepstrum c with quefrencies,
+// positive or negative, of less than l samples in place.
+func HighLifter(c []float64, l int) {
+	n := len(c)
+	for k := 0; k < l && k < n; k++ {
+		c[k] = 0
+		if k > 0 {
+			c[n-k] = 0
+		}
+	}
+}
+
+// SineLifter multiplies coefficient k of c by 1 + l/2*sin(pi*k/l) in place,
+// as is done by HTK to mel frequency cepstral coefficients.
+func SineLifter(c []float64, l int) {
+	if l <= 0 {
+		return
+	}
+	fl := float64(l)
+	for k := range c {
+		c[k] *= 1 + fl/2*math.Sin(math.Pi*float64(k)/fl)
+	}
+}
+
+// Peak returns the quefrency in [lo, hi) at which the cepstrum c is
+// largest, and its value there.  For the real cepstrum of a voiced sound
+// with lo and hi set to the range of possible pitch periods in samples,
+// Peak gives the pitch period.  If the range is empty, Peak returns -1.
+func Peak(c []float64, lo, hi int) (int, float64) {
+	if lo < 0 {
+		lo = 0
+	}
+	if hi > len(c) {
+		hi = len(c)
+	}
+	res, mx := -1, math.Inf(-1)
+	for k := lo; k < hi; k++ {
+		if c[k] > mx {
+			res, mx = k, c[k]
+		}
+	}
+	return res, mx
+}
@@ -0,0 +1,190 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package cepstrum
+
+import (
+	"math"
+	"math/cmplx"
+	"math/rand"
+	"testing"
+)
+
+func dft(d []float64) []complex128 {
+	n := len(d)
+	res := make([]complex128, n)
+	for k := range res {
+		for i, v := range d {
+			res[k] += complex(v, 0) * cmplx.Rect(1, -2*math.Pi*float64(i*k)/float64(n))
+		}
+	}
+	return res
+}
+
+func naiveReal(d []float64) []float64 {
+	n := len(d)
+	x := dft(d)
+	res := make([]float64, n)
+	for i := range res {
+		for k, c := range x {
+			res[i] += math.Log(cmplx.Abs(c)) * math.Cos(2*math.Pi*float64(i*k)/float64(n))
+		}
+		res[i] /= float64(n)
+	}
+	return res
+}
+
+func TestReal(t *testing.T) {
+	for _, n := range []int{64, 45} {
+		d := make([]float64, n)
+		for i := range d {
+			d[i] = rand.Float64()*2 - 1
+		}
+		ct := New(n)
+		c, e := ct.Real(nil, d)
+		if e != nil {
+			t.Fatal(e)
+		}
+		exp := naiveReal(d)
+		for i, v := range c {
+			if math.Abs(v-exp[i]) > 1e-9 {
+				t.Errorf("n=%d %d: got %f not %f", n, i, v, exp[i])
+			}
+		}
+		lm, e := ct.LogMag(nil, c)
+		if e != nil {
+			t.Fatal(e)
+		}
+		x := dft(d)
+		for i, v := range lm {
+			if g := math.Log(cmplx.Abs(x[i])); math.Abs(v-g) > 1e-9 {
+				t.Errorf("n=%d bin %d: got %f not %f", n, i, v, g)
+			}
+		}
+		if _, e := ct.Real(nil, d[1:]); e == nil {
+			t.Errorf("expected length error")
+		}
+	}
+}
+
+func TestComplex(t *testing.T) {
+	n := 256
+	ct := New(n)
+	// zeros at 2, 0.5 and -0.8, shifted by 3.  The sum is negative, so
+	// the cepstrum is that of -h.
+	h := []float64{1, -2.5, 1}
+	h = conv(h, []float64{1, 0.8})
+	for _, sh := range []int{0, 3} {
+		d := make([]float64, n)
+		copy(d[sh:], h)
+		c, delay, e := ct.Complex(nil, d)
+		if e != nil {
+			t.Fatal(e)
+		}
+		r, e := ct.InvComplex(nil, c, delay)
+		if e != nil {
+			t.Fatal(e)
+		}
+		for i, v := range r {
+			if math.Abs(v+d[i]) > 1e-9 {
+				t.Errorf("shift %d: %d: got %f not %f", sh, i, v, -d[i])
+			}
+		}
+		if sh == 3 {
+			_, d0, _ := ct.Complex(nil, append(d[3:], 0, 0, 0))
+			if delay-d0 != 3 {
+				t.Errorf("got delays %d %d", delay, d0)
+			}
+		}
+	}
+}
+
+func TestMinPhase(t *testing.T) {
+	n := 256
+	ct := New(n)
+	d := make([]float64, n)
+	d[0], d[1] = 1, -2
+	m, e := ct.MinPhase(nil, d)
+	if e != nil {
+		t.Fatal(e)
+	}
+	exp := []float64{2, -1}
+	for i, v := range m {
+		x := 0.0
+		if i < len(exp) {
+			x = exp[i]
+		}
+		if math.Abs(v-x) > 1e-6 {
+			t.Errorf("%d: got %f not %f", i, v, x)
+		}
+	}
+}
+
+func TestPitch(t *testing.T) {
+	n := 1024
+	period := 80
+	d := make([]float64, n)
+	for i := 0; i < n; i += period {
+		d[i] = 1
+	}
+	// a resonance
+	y1, y2 := 0.0, 0.0
+	for i, v := range d {
+		y := v + 1.6*y1 - 0.8*y2
+		y2, y1 = y1, y
+		d[i] = y
+	}
+	for i := range d {
+		d[i] *= 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(n))
+	}
+	ct := New(n)
+	c, e := ct.Real(nil, d)
+	if e != nil {
+		t.Fatal(e)
+	}
+	if p, _ := Peak(c, 40, 200); p < period-1 || p > period+1 {
+		t.Errorf("got period %d not %d", p, period)
+	}
+	env := append([]float64{}, c...)
+	LowLifter(env, 30)
+	for k := 30; k <= n-30; k++ {
+		if env[k] != 0 {
+			t.Fatalf("lifter left %d", k)
+		}
+	}
+	lm, _ := ct.LogMag(nil, env)
+	// resonance of 1/(1-1.6z+0.8z^2) is at about 0.1 cycles per sample.
+	mx := 0
+	for i, v := range lm {
+		if v > lm[mx] {
+			mx = i
+		}
+	}
+	if f := float64(mx) / float64(n); math.Abs(f-0.1) > 0.03 {
+		t.Errorf("got envelope peak at %f", f)
+	}
+	HighLifter(c, 30)
+	for k := 0; k < 30; k++ {
+		if c[k] != 0 || c[(n-k)%n] != 0 {
+			t.Fatalf("high lifter left %d", k)
+		}
+	}
+}
+
+func TestSineLifter(t *testing.T) {
+	c := []float64{1, 1, 1}
+	SineLifter(c, 22)
+	if c[0] != 1 || math.Abs(c[1]-(1+11*math.Sin(math.Pi/22))) > 1e-12 {
+		t.Errorf("got %v", c)
+	}
+}
+
+func conv(a, b []float64) []float64 {
+	res := make([]float64, len(a)+len(b)-1)
+	for i, u := range a {
+		for j, v := range b {
+			res[i+j] += u * v
+		}
+	}
+	return res
+}
@@ -0,0 +1,19 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+// Package cepstrum provides real and complex cepstra and liftering.
+//
+// Package cepstrum is part of http://zikichombo.org
+//
+// The real cepstrum of a signal is the inverse fourier transform of the log
+// of the magnitude of its spectrum.  Low quefrency coefficients describe the
+// spectral envelope and a peak at a higher quefrency indicates the period
+// of a voiced sound, so liftering, which windows the cepstrum, may be used
+// for envelope smoothing and pitch detection.
+//
+// The complex cepstrum also includes the unwrapped phase of the spectrum
+// and so may be inverted.  A minimum phase signal with the same magnitude
+// spectrum as a given signal is obtained by folding its real cepstrum.
+//
+// All transforms are computed with fft.Real on buffers of a fixed size.
+package cepstrum
@@ -3,4 +3,14 @@
 
 // Package convol provides convolution implementations.
 //
+// T and K compute linear convolutions by fft, and Ola and Fdl compute them
+// block by block for streaming.  Ola uses an fft of the block size plus the
+// kernel length, whereas Fdl partitions the kernel into blocks and uses an
+// fft of twice the block size, so that long kernels, such as reverberation
+// impulse responses, may be applied with small blocks and low latency.
+// Nup partitions the kernel non-uniformly, with a direct form head and
+// partitions growing with their offset in the kernel, which may be
+// convolved on background goroutines, for yet lower cost with long
+// kernels.
+//
 package convol
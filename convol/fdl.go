@@ -0,0 +1,180 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package convol
+
+import (
+	"fmt"
+
+	"github.com/zikichombo/dsp/fft"
+)
+
+// Fdl keeps state for implementing uniformly partitioned overlap-save block
+// convolution with a frequency-domain delay line.
+//
+// The kernel is split into partitions of the block size, each of which is
+// transformed once.  Each block of input is transformed together with the
+// previous block, and its spectrum is kept in a delay line, so that a
+// block of output is the sum of the products of the partition spectra with
+// the spectra of the current and previous input blocks.  The fft size is
+// twice the block size, independent of the kernel length, and the output
+// of a block is available as soon as the block is input.  For long kernels,
+// this allows block sizes, and hence latency, much smaller than with Ola.
+type Fdl struct {
+	m, n  int
+	ft    *fft.Real
+	parts []fft.HalfComplex // kernel partition spectra
+	dl    []fft.HalfComplex // delay line of input spectra
+	pos   int               // position of the latest input spectrum in dl
+	in    []float64         // previous and current input block
+	acc   []float64
+}
+
+// NewFdl creates a new partitioned block convolver based on the kernel krn
+// with processing block size N.  The underlying fft has size 2*N, so N is
+// best a power of 2.
+//
+// NewFdl panics if krn is empty or N < 1.
+func NewFdl(krn []float64, N int) *Fdl {
+	if len(krn) == 0 || N < 1 {
+		panic(fmt.Sprintf("invalid kernel length %d or block size %d", len(krn), N))
+	}
+	P := (len(krn) + N - 1) / N
+	res := &Fdl{
+		m:     len(krn),
+		n:     N,
+		ft:    fft.NewReal(2 * N),
+		parts: make([]fft.HalfComplex, P),
+		dl:    make([]fft.HalfComplex, P),
+		in:    make([]float64, 2*N),
+		acc:   make([]float64, 2*N)}
+	res.ft.Scale(false)
+	// the unscaled inverse of the real transform of even size 2N gives N
+	// times the data.
+	s := 1 / float64(N)
+	for p := range res.parts {
+		part := make([]float64, 2*N)
+		end := (p + 1) * N
+		if end > len(krn) {
+			end = len(krn)
+		}
+		for i, v := range krn[p*N : end] {
+			part[i] = v * s
+		}
+		res.parts[p] = res.ft.Do(part)
+		res.dl[p] = make([]float64, 2*N)
+	}
+	return res
+}
+
+// M returns the length of the kernel
+func (f *Fdl) M() int {
+	return f.m
+}
+
+// N returns the block length of the input and output
+func (f *Fdl) N() int {
+	return f.n
+}
+
+// P returns the number of kernel partitions.
+func (f *Fdl) P() int {
+	return len(f.parts)
+}
+
+// WinSrc takes a candidate window slice c and returns a slice properly
+// proportioned for passing as src arg of Block().
+//
+// The returned slice uses the backing store of c if possible and contains the
+// elements of c.
+func (f *Fdl) WinSrc(c []float64) []float64 {
+	return f.win(c)
+}
+
+// WinDst takes a candidate window slice c and returns a slice properly
+// proportioned for passing as dst arg of Block().
+//
+// The returned slice uses the backing store of c if possible and contains the
+// elements of c.
+func (f *Fdl) WinDst(c []float64) []float64 {
+	return f.win(c)
+}
+
+func (f *Fdl) win(c []float64) []float64 {
+	if cap(c) < f.n {
+		tmp := make([]float64, f.n)
+		copy(tmp, c)
+		return tmp
+	}
+	m := len(c)
+	c = c[:f.n]
+	for i := m; i < f.n; i++ {
+		c[i] = 0.0
+	}
+	return c
+}
+
+// Block processes one block of the convolution, placing in dst the block
+// of output ending with the response to the last sample of src.
+//
+// src and dst may be the same slice.
+//
+// Block returns a non-nil error if len(src) or len(dst) is not f.N().
+func (f *Fdl) Block(src, dst []float64) error {
+	N := f.n
+	if len(src) != N {
+		return fmt.Errorf("src dimension mismatch: %d != %d", len(src), N)
+	}
+	if len(dst) != N {
+		return fmt.Errorf("dst dimension mismatch: %d != %d", len(dst), N)
+	}
+	copy(f.in, f.in[N:])
+	copy(f.in[N:], src)
+	P := len(f.parts)
+	f.pos--
+	if f.pos < 0 {
+		f.pos = P - 1
+	}
+	x := f.dl[f.pos]
+	copy(x, f.in)
+	f.ft.Do(x)
+	acc := fft.HalfComplex(f.acc)
+	for i := range acc {
+		acc[i] = 0.0
+	}
+	for p, h := range f.parts {
+		mulAcc(acc, f.dl[(f.pos+p)%P], h)
+	}
+	y := f.ft.Inv(acc)
+	copy(dst, y[N:])
+	return nil
+}
+
+// Reset clears the input history of f, as if no blocks had been processed.
+func (f *Fdl) Reset() {
+	for i := range f.in {
+		f.in[i] = 0.0
+	}
+	for _, x := range f.dl {
+		for i := range x {
+			x[i] = 0.0
+		}
+	}
+	f.pos = 0
+}
+
+// mulAcc adds the elementwise product of a and b to acc.
+func mulAcc(acc, a, b fft.HalfComplex) {
+	n := len(acc)
+	acc[0] += a[0] * b[0]
+	h := (n + 1) / 2
+	for i := 1; i < h; i++ {
+		ar, ai := a[i], a[n-i]
+		br, bi := b[i], b[n-i]
+		acc[i] += ar*br - ai*bi
+		acc[n-i] += ar*bi + ai*br
+	}
+	if n&1 == 0 {
+		acc[n/2] += a[n/2] * b[n/2]
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package convol
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestFdl(t *testing.T) {
+	// we compare fdl to direct convolution on random inputs.
+	for _, N := range []int{1, 7, 64, 256} {
+		for _, m := range []int{1, 5, 64, 1000} {
+			krn := make([]float64, m)
+			for i := range krn {
+				krn[i] = rand.Float64()*2 - 1
+			}
+			seq := make([]float64, 3000)
+			for i := range seq {
+				seq[i] = rand.Float64()*2 - 1
+			}
+			exp := direct(krn, seq)
+			fdl := NewFdl(krn, N)
+			if fdl.P() != (m+N-1)/N {
+				t.Errorf("%d/%d: got %d partitions", m, N, fdl.P())
+			}
+			src := fdl.WinSrc(nil)
+			dst := fdl.WinDst(nil)
+			for i := 0; i < len(exp); i += N {
+				for j := range src {
+					src[j] = 0.0
+					if i+j < len(seq) {
+						src[j] = seq[i+j]
+					}
+				}
+				if e := fdl.Block(src, dst); e != nil {
+					t.Fatal(e)
+				}
+				end := i + N
+				if end > len(exp) {
+					end = len(exp)
+				}
+				if k := approxEq(exp[i:end], dst[:end-i], 1e-9); k != -1 {
+					t.Errorf("%d/%d: data error at %d: %f v %f", m, N, i+k, exp[i+k], dst[k])
+					break
+				}
+			}
+			fdl.Reset()
+			if e := fdl.Block(make([]float64, N), dst); e != nil {
+				t.Fatal(e)
+			}
+			for i, v := range dst {
+				if v > 1e-12 || v < -1e-12 {
+					t.Errorf("%d/%d: got %f at %d after reset", m, N, v, i)
+					break
+				}
+			}
+		}
+	}
+	if e := NewFdl([]float64{1}, 4).Block(make([]float64, 3), make([]float64, 4)); e == nil {
+		t.Errorf("expected dimension error")
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package convol
+
+import (
+	"fmt"
+
+	"github.com/zikichombo/dsp/fft"
+)
+
+// K32 is like K but for float32 data.
+type K32 struct {
+	t      *T32
+	kernel fft.HalfComplex32
+}
+
+// Conv is like K.Conv.
+func (k *K32) Conv(arg []float32) ([]float32, error) {
+	if len(arg) != k.t.n {
+		return nil, fmt.Errorf("arg dimension mismatch, %d != %d", len(arg), k.t.n)
+	}
+	arg = k.t.pad(k.t.WinB(arg), k.t.PadL())
+	hc := k.t.ft.Do(arg)
+	hc.MulElems(k.kernel)
+	arg = k.t.ft.Inv(hc)
+	return arg[:k.t.L()], nil
+}
+
+// ConvTo is like K.ConvTo.
+func (k *K32) ConvTo(dst, arg []float32) ([]float32, error) {
+	dst = k.t.WinDst(dst)
+	copy(dst, arg)
+	dst = dst[:len(arg)]
+	return k.Conv(dst)
+}
+
+// Win is like K.Win.
+func (k *K32) Win(c []float32) []float32 {
+	return k.t.WinB(c)
+}
+
+// M() returns the length of the kernel.
+func (k *K32) M() int {
+	return k.t.m
+}
+
+// N() returns the length of the argument.
+func (k *K32) N() int {
+	return k.t.n
+}
+
+// L() returns the length of the result, which is
+//
+//	M() + N() - 1
+func (k *K32) L() int {
+	return k.t.L()
+}
+
+// NewK32 creates a new float32 convolver using "kernel" as
+// the kernel.
+func NewK32(kernel []float32, argLen int) *K32 {
+	k, e := New32(len(kernel), argLen).K(kernel)
+	if e != nil {
+		panic(e)
+	}
+	return k
+}
+
+// K() creates a new kernel-convolver with using "kernel"
+// as the kernel.
+func (t *T32) K(kernel []float32) (*K32, error) {
+	if len(kernel) != t.m {
+		return nil, fmt.Errorf("kernel length wrong %d != %d", len(kernel), t.m)
+	}
+	krn := t.WinA(nil)
+	copy(krn, kernel)
+	krn = t.pad(krn, t.PadL())
+	t.ft.Scale(false)
+	hc := t.ft.Do(krn)
+	t.ft.Scale(true)
+	return &K32{t: t, kernel: hc}, nil
+}
@@ -0,0 +1,259 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package convol
+
+import "fmt"
+
+// nupDirect is the largest head computed in direct form by Nup.
+const nupDirect = 32
+
+// Nup keeps state for implementing non-uniformly partitioned block
+// convolution, in the manner of Gardner.
+//
+// The kernel is split into a head of the block size, which is convolved
+// in direct form or by Ola, followed by levels of partitions whose size
+// doubles from level to level, each of which is convolved by an Fdl.  The
+// first level has partitions of the block size and starts right after the
+// head.  Each further level with partitions of size S starts at 2*S in the
+// kernel, so that the convolution of a block of S input samples has S
+// samples of time before its output is needed.  Nup may compute these
+// levels on background goroutines, joining them only when the following
+// block of the level is complete.
+//
+// The output of a block is available as soon as the block is input, so
+// Nup adds no latency beyond the block size, while the cost per sample
+// grows only logarithmically with the kernel length.
+type Nup struct {
+	m, n  int
+	t     int // number of samples processed
+	head  []float64
+	hist  []float64 // direct form head history, nil if ola != nil
+	ola   *Ola
+	olaIn []float64
+	lvls  []*nupLevel
+	async bool
+}
+
+// nupLevel is a uniformly partitioned part of the kernel, whose output is
+// delayed by its offset in the kernel.
+type nupLevel struct {
+	fdl       *Fdl
+	o         int       // offset in the kernel
+	in, inB   []float64 // input being filled, and that being convolved
+	cur, next []float64 // output being added, and that being computed
+	done      chan struct{}
+}
+
+// NewNup creates a new non-uniformly partitioned block convolver based on
+// the kernel krn with processing block size N.  The largest partitions are
+// of size N*2^k <= maxN.  If async is true, levels with partitions larger
+// than N are convolved on background goroutines.
+//
+// NewNup panics if krn is empty or N < 1.
+func NewNup(krn []float64, N, maxN int, async bool) *Nup {
+	M := len(krn)
+	if M == 0 || N < 1 {
+		panic(fmt.Sprintf("invalid kernel length %d or block size %d", M, N))
+	}
+	h := N
+	if h > M {
+		h = M
+	}
+	res := &Nup{
+		m:     M,
+		n:     N,
+		head:  append([]float64{}, krn[:h]...),
+		async: async}
+	if h <= nupDirect {
+		res.hist = make([]float64, h-1+N)
+	} else {
+		res.ola = NewOla(res.head, N)
+		res.olaIn = res.ola.WinSrc(nil)
+	}
+	o, sz := h, N
+	for o < M {
+		end := M
+		if 2*sz <= maxN && 4*sz < M {
+			end = 4 * sz
+		}
+		res.lvls = append(res.lvls, newNupLevel(krn[o:end], sz, o))
+		o = end
+		sz *= 2
+	}
+	return res
+}
+
+func newNupLevel(seg []float64, sz, o int) *nupLevel {
+	return &nupLevel{
+		fdl:  NewFdl(seg, sz),
+		o:    o,
+		in:   make([]float64, sz),
+		inB:  make([]float64, sz),
+		cur:  make([]float64, sz),
+		next: make([]float64, sz)}
+}
+
+// M returns the length of the kernel
+func (p *Nup) M() int {
+	return p.m
+}
+
+// N returns the block length of the input and output
+func (p *Nup) N() int {
+	return p.n
+}
+
+// Sizes returns the partition size of each level of p.
+func (p *Nup) Sizes() []int {
+	res := make([]int, len(p.lvls))
+	for i, l := range p.lvls {
+		res[i] = l.fdl.N()
+	}
+	return res
+}
+
+// WinSrc takes a candidate window slice c and returns a slice properly
+// proportioned for passing as src arg of Block().
+//
+// The returned slice uses the backing store of c if possible and contains the
+// elements of c.
+func (p *Nup) WinSrc(c []float64) []float64 {
+	return p.win(c)
+}
+
+// WinDst takes a candidate window slice c and returns a slice properly
+// proportioned for passing as dst arg of Block().
+//
+// The returned slice uses the backing store of c if possible and contains the
+// elements of c.
+func (p *Nup) WinDst(c []float64) []float64 {
+	return p.win(c)
+}
+
+func (p *Nup) win(c []float64) []float64 {
+	if cap(c) < p.n {
+		tmp := make([]float64, p.n)
+		copy(tmp, c)
+		return tmp
+	}
+	m := len(c)
+	c = c[:p.n]
+	for i := m; i < p.n; i++ {
+		c[i] = 0.0
+	}
+	return c
+}
+
+// Block processes one block of the convolution, placing in dst the block
+// of output ending with the response to the last sample of src.
+//
+// src and dst may be the same slice.
+//
+// Block returns a non-nil error if len(src) or len(dst) is not p.N().
+func (p *Nup) Block(src, dst []float64) error {
+	N := p.n
+	if len(src) != N {
+		return fmt.Errorf("src dimension mismatch: %d != %d", len(src), N)
+	}
+	if len(dst) != N {
+		return fmt.Errorf("dst dimension mismatch: %d != %d", len(dst), N)
+	}
+	for _, l := range p.lvls {
+		i := p.t % len(l.in)
+		copy(l.in[i:i+N], src)
+	}
+	if e := p.doHead(src, dst); e != nil {
+		return e
+	}
+	for _, l := range p.lvls {
+		i := p.t % len(l.cur)
+		for j, v := range l.cur[i : i+N] {
+			dst[j] += v
+		}
+	}
+	p.t += N
+	for _, l := range p.lvls {
+		if p.t%len(l.in) != 0 {
+			continue
+		}
+		if e := p.complete(l); e != nil {
+			return e
+		}
+	}
+	return nil
+}
+
+func (p *Nup) doHead(src, dst []float64) error {
+	if p.ola != nil {
+		copy(p.olaIn, src)
+		return p.ola.Block(p.olaIn, dst)
+	}
+	m := len(p.head) - 1
+	h := p.hist
+	copy(h[m:], src)
+	for i := range dst {
+		s := 0.0
+		for k, v := range p.head {
+			s += v * h[m+i-k]
+		}
+		dst[i] = s
+	}
+	copy(h, h[len(h)-m:])
+	return nil
+}
+
+// complete handles a complete block of input to l.
+func (p *Nup) complete(l *nupLevel) error {
+	sz := len(l.in)
+	if l.o == sz {
+		// the output is needed immediately.
+		if e := l.fdl.Block(l.in, l.next); e != nil {
+			return e
+		}
+		l.cur, l.next = l.next, l.cur
+		return nil
+	}
+	// the output of the last block is needed now, and that of this
+	// block after sz more samples.
+	l.join()
+	l.cur, l.next = l.next, l.cur
+	l.in, l.inB = l.inB, l.in
+	if !p.async {
+		return l.fdl.Block(l.inB, l.next)
+	}
+	l.done = make(chan struct{})
+	go func(f *Fdl, src, dst []float64, done chan struct{}) {
+		// the lengths are always right.
+		f.Block(src, dst)
+		close(done)
+	}(l.fdl, l.inB, l.next, l.done)
+	return nil
+}
+
+func (l *nupLevel) join() {
+	if l.done != nil {
+		<-l.done
+		l.done = nil
+	}
+}
+
+// Reset clears the input history of p, as if no blocks had been processed.
+func (p *Nup) Reset() {
+	p.t = 0
+	for i := range p.hist {
+		p.hist[i] = 0.0
+	}
+	if p.ola != nil {
+		p.ola = NewOla(p.head, p.n)
+	}
+	for _, l := range p.lvls {
+		l.join()
+		l.fdl.Reset()
+		for _, d := range [][]float64{l.in, l.inB, l.cur, l.next} {
+			for i := range d {
+				d[i] = 0.0
+			}
+		}
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package convol
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestNup(t *testing.T) {
+	// we compare nup to direct convolution on random inputs.
+	for _, m := range []int{1, 10, 64, 100, 3000} {
+		krn := make([]float64, m)
+		for i := range krn {
+			krn[i] = rand.Float64()*2 - 1
+		}
+		seq := make([]float64, 4000)
+		for i := range seq {
+			seq[i] = rand.Float64()*2 - 1
+		}
+		exp := direct(krn, seq)
+		for _, N := range []int{1, 16, 64} {
+			for _, maxN := range []int{0, 4 * N, 1 << 20} {
+				for _, async := range []bool{false, true} {
+					nup := NewNup(krn, N, maxN, async)
+					buf := nup.WinSrc(nil)
+					for i := 0; i < len(exp); i += N {
+						for j := range buf {
+							buf[j] = 0.0
+							if i+j < len(seq) {
+								buf[j] = seq[i+j]
+							}
+						}
+						if e := nup.Block(buf, buf); e != nil {
+							t.Fatal(e)
+						}
+						end := i + N
+						if end > len(exp) {
+							end = len(exp)
+						}
+						if k := approxEq(exp[i:end], buf[:end-i], 1e-9); k != -1 {
+							t.Errorf("%d/%d/%d/%t: data error at %d: %f v %f", m, N, maxN, async, i+k, exp[i+k], buf[k])
+							break
+						}
+					}
+					nup.Reset()
+					if e := nup.Block(make([]float64, N), buf); e != nil {
+						t.Fatal(e)
+					}
+					for i, v := range buf {
+						if v > 1e-12 || v < -1e-12 {
+							t.Errorf("%d/%d: got %f at %d after reset", m, N, v, i)
+							break
+						}
+					}
+				}
+			}
+		}
+	}
+	sizes := NewNup(make([]float64, 1000), 16, 64, false).Sizes()
+	exp := []int{16, 32, 64}
+	if len(sizes) != len(exp) {
+		t.Fatalf("got sizes %v not %v", sizes, exp)
+	}
+	for i := range exp {
+		if sizes[i] != exp[i] {
+			t.Errorf("got sizes %v not %v", sizes, exp)
+		}
+	}
+}
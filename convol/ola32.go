@@ -0,0 +1,64 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package convol
+
+// Ola32 is like Ola but for float32 data.
+type Ola32 struct {
+	k    *K32
+	over []float32
+	conv []float32
+}
+
+// NewOla32 creates a new overlap block convolver
+// based on the kernel krn with processing block
+// size L.
+func NewOla32(krn []float32, L int) *Ola32 {
+	k := NewK32(krn, L)
+	return &Ola32{
+		k:    k,
+		over: make([]float32, len(krn)-1),
+		conv: k.Win(nil)}
+}
+
+// M returns the length of the kernel
+func (o *Ola32) M() int {
+	return o.k.M()
+}
+
+// N returns the block length of the input
+func (o *Ola32) N() int {
+	return o.k.N()
+}
+
+// L returns o.M() + o.N() - 1, the zero
+// padding size and size of the underlying fft.
+func (o *Ola32) L() int {
+	return o.M() + o.N() - 1
+}
+
+// WinSrc is like Ola.WinSrc.
+func (o *Ola32) WinSrc(c []float32) []float32 {
+	return o.k.Win(c)
+}
+
+// WinDst is like Ola.WinDst.
+func (o *Ola32) WinDst(c []float32) []float32 {
+	return o.k.t.win(c, o.k.t.n)
+}
+
+// Block processes one block of the convolution
+func (o *Ola32) Block(src, dst []float32) error {
+	conv, e := o.k.ConvTo(o.conv, src)
+	if e != nil {
+		return e
+	}
+	o.conv = conv
+	M := o.k.t.m - 1
+	for i := 0; i < M; i++ {
+		dst[i] = o.over[i] + conv[i]
+	}
+	copy(o.over, conv[o.N():])
+	copy(dst[M:], conv[M:o.N()])
+	return nil
+}
@@ -0,0 +1,133 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package convol
+
+import (
+	"fmt"
+
+	"github.com/zikichombo/dsp/fft"
+)
+
+// T32 is like T but for float32 data.  It is based on fft.Real32, and so
+// has the precision described for fft.T32.
+type T32 struct {
+	n, m int
+	winB []float32
+	ft   *fft.Real32
+}
+
+// New32 creates a new convolver object for repeatedly performing
+// linear convolution of two float32 arguments of length m, n.
+func New32(m, n int) *T32 {
+	res := &T32{
+		n: n,
+		m: m}
+	res.ft = fft.NewReal32(res.PadL())
+	res.winB = make([]float32, res.ft.N())[:res.L()]
+	return res
+}
+
+// M returns the length of the first argument
+func (t *T32) M() int {
+	return t.m
+}
+
+// N returns the length of the second argument.
+func (t *T32) N() int {
+	return t.n
+}
+
+// L returns the length of the result, which is
+//
+//	t.N() + t.M() - 1
+func (t *T32) L() int {
+	return t.n + t.m - 1
+}
+
+// PadL returns the fft padded length (can be
+// > L).
+func (t *T32) PadL() int {
+	L := t.L()
+	res := 1
+	for res < L {
+		res *= 2
+	}
+	return res
+}
+
+// Conv is like T.Conv.
+func (t *T32) Conv(a, b []float32) ([]float32, error) {
+	if len(a) != t.m {
+		return nil, fmt.Errorf("operand dimension mismatch: %d != %d", len(a), t.m)
+	}
+	if len(b) != t.n {
+		return nil, fmt.Errorf("kernel dimension mismatch: %d != %d", len(b), t.n)
+	}
+	copy(t.winB, b)
+	return t.conv(a, t.winB[:len(b)])
+}
+
+// clobbers b with ifft.
+func (t *T32) conv(a, b []float32) ([]float32, error) {
+	L := t.PadL()
+	a = t.pad(a, L)
+	b = t.pad(b, L)
+	hcb := t.ft.Do(b)
+	t.ft.Scale(false)
+	hca := t.ft.Do(a)
+	t.ft.Scale(true)
+	hca = hca.MulElems(hcb)
+	a = t.ft.Inv(hca)
+	return a[:t.L()], nil
+}
+
+// ConvTo is like T.ConvTo.
+func (t *T32) ConvTo(dst, a, b []float32) ([]float32, error) {
+	dst = t.WinDst(dst)
+	copy(dst, a)
+	return t.Conv(dst[:t.m], t.WinB(b))
+}
+
+// WinA is like T.WinA.
+func (t *T32) WinA(d []float32) []float32 {
+	return t.win(d, t.m)
+}
+
+// WinB is like T.WinB.
+func (t *T32) WinB(d []float32) []float32 {
+	return t.win(d, t.n)
+}
+
+// WinDst is like T.WinDst.
+func (t *T32) WinDst(d []float32) []float32 {
+	return t.win(d, t.L())
+}
+
+func (t *T32) win(d []float32, trgLen int) []float32 {
+	m := len(d)
+	if cap(d) < t.ft.N() {
+		tmp := make([]float32, t.ft.N())
+		copy(tmp, d)
+		d = tmp
+	}
+	d = d[:cap(d)]
+	for i := m; i < len(d); i++ {
+		d[i] = 0.0
+	}
+	return d[:trgLen]
+}
+
+func (t *T32) pad(sl []float32, L int) []float32 {
+	n := len(sl)
+	if cap(sl) < L {
+		tmp := make([]float32, n, L)
+		copy(tmp, sl)
+		sl = tmp
+	}
+	sl = sl[:L]
+	for i := n; i < L; i++ {
+		sl[i] = 0.0
+	}
+	return sl
+}
@@ -0,0 +1,86 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package convol
+
+import (
+	"math"
+	"testing"
+)
+
+func to32(d []float64) []float32 {
+	res := make([]float32, len(d))
+	for i, v := range d {
+		res[i] = float32(v)
+	}
+	return res
+}
+
+// approxEq32 compares relative to the largest magnitude in a, since
+// float32 rounding errors of a transform spread across all outputs.
+func approxEq32(a []float64, b []float32, eps float64) int {
+	mx := 1.0
+	for _, v := range a {
+		mx = math.Max(mx, math.Abs(v))
+	}
+	for i := range a {
+		if math.Abs(a[i]-float64(b[i])) > eps*mx {
+			return i
+		}
+	}
+	return -1
+}
+
+func TestT32(t *testing.T) {
+	for i := 0; i < 64; i++ {
+		a, b := gen()
+		d := direct(a, b)
+		c := New32(len(a), len(b))
+		o, e := c.ConvTo(nil, to32(a), to32(b))
+		if e != nil {
+			t.Fatal(e)
+		}
+		if k := approxEq32(d, o, 1e-5); k != -1 {
+			t.Errorf("[%d] %v (*) %v @%d: %.2f v %.2f\n", i, a, b, k, d[k], o[k])
+		}
+	}
+}
+
+func TestK32(t *testing.T) {
+	for i := 0; i < 64; i++ {
+		a, b := gen()
+		d := direct(a, b)
+		kd, e := NewK32(to32(a), len(b)).ConvTo(nil, to32(b))
+		if e != nil {
+			t.Fatal(e)
+		}
+		if k := approxEq32(d, kd, 1e-5); k != -1 {
+			t.Errorf("%d kernel/direct mismatch %v (*) %v @%d: %.2f %.2f\n", i, a, b, k, d[k], kd[k])
+		}
+	}
+}
+
+func TestOla32(t *testing.T) {
+	for i := 0; i < 64; i++ {
+		krn, _ := gen()
+		seq := genLong()
+		blk := 2 * len(krn)
+		exp := direct(krn, seq)
+		ola := NewOla32(to32(krn), blk)
+		src, dst := ola.WinSrc(nil), ola.WinDst(nil)
+		for len(seq)%blk != 0 || len(seq) < len(exp) {
+			seq = append(seq, 0)
+		}
+		res := make([]float32, 0, len(seq))
+		for n := 0; n < len(seq); n += blk {
+			copy(src, to32(seq[n:n+blk]))
+			if e := ola.Block(src, dst); e != nil {
+				t.Fatal(e)
+			}
+			res = append(res, dst...)
+		}
+		if k := approxEq32(exp, res[:len(exp)], 1e-5); k != -1 {
+			t.Errorf("ola32 run %d: data error at %d. %.2f v %.2f", i, k, exp[k], res[k])
+		}
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"math/cmplx"
 
 	"github.com/zikichombo/dsp/fft"
+	"github.com/zikichombo/dsp/internal/tabcache"
 	"github.com/zikichombo/sound/freq"
 )
 
@@ -26,6 +27,9 @@ type T struct {
 // samples with nB bins, focusing on frequencies
 // from start to end.  Start and end are in radians
 // per sample.
+//
+// The chirp and kernel tables are shared with other transformers
+// of the same configuration via the fft table cache.
 func New(nS, nB int, start, end float64) *T {
 	nPad := findL(nS, nB)
 	step := (end - start) / float64(nB)
@@ -35,15 +39,33 @@ func New(nS, nB int, start, end float64) *T {
 		nPad:  nPad,
 		start: start,
 		step:  step,
-		ft:    fft.New(nPad),
-		kern:  make([]complex128, nPad),
-		aTab:  make([]complex128, nS),
-		wTab:  make([]complex128, nS)}
-	res.initWK()
-	res.initA()
+		ft:    fft.New(nPad)}
+	k := tabKey{nS: nS, nPad: nPad, start: start, step: step}
+	tb := tabcache.Get(k, func() (interface{}, int) {
+		tmp := *res
+		tmp.kern = make([]complex128, nPad)
+		tmp.aTab = make([]complex128, nS)
+		tmp.wTab = make([]complex128, nS)
+		tmp.initWK()
+		tmp.initA()
+		return &tabs{kern: tmp.kern, aTab: tmp.aTab, wTab: tmp.wTab}, 16 * (nPad + 2*nS)
+	}).(*tabs)
+	res.kern, res.aTab, res.wTab = tb.kern, tb.aTab, tb.wTab
 	return res
 }
 
+// tabKey is the key of the tables of a transformer in the table cache.
+type tabKey struct {
+	nS, nPad    int
+	start, step float64
+}
+
+// tabs holds the tables of a transformer, which are read only once
+// computed.
+type tabs struct {
+	kern, aTab, wTab []complex128
+}
+
 // Do performs the transform as configured in
 // the call to New() which created t.
 func (t *T) Do(src []complex128) []complex128 {
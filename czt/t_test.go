@@ -147,3 +147,20 @@ func cmplxApproxEq(a, b []complex128, eps float64) int {
 	}
 	return -1
 }
+
+func TestCztShare(t *testing.T) {
+	fft.PurgeCache()
+	a, b := New(100, 50, 0.1, 1.2), New(100, 50, 0.1, 1.2)
+	if &a.kern[0] != &b.kern[0] || &a.aTab[0] != &b.aTab[0] || &a.wTab[0] != &b.wTab[0] {
+		t.Errorf("tables not shared")
+	}
+	if c := New(100, 50, 0.2, 1.3); &c.aTab[0] == &a.aTab[0] {
+		t.Errorf("tables shared for different start")
+	}
+	d := genCmplx(100, 101)
+	e := append([]complex128{}, d...)
+	d, e = a.Do(d), b.Do(e)
+	if i := cmplxApproxEq(d, e, 1e-12); i != -1 {
+		t.Errorf("results differ at %d: %.3f v %.3f", i, d[i], e[i])
+	}
+}
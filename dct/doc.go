@@ -4,7 +4,9 @@
 // Package dct provides discrete cosine transform support.
 //
 // dct implements a naive O(n*n) algorithm for reference and
-// testing and also an O(n log n) algorithm by Byeong Gi Lee (1984).
+// testing and also an O(n log n) algorithm by Byeong Gi Lee (1984)
+// for sizes which are powers of 2.  Other sizes are supported by
+// direct O(n*n) computation.
 //
 // see http://citeseerx.ist.psu.edu/viewdoc/download?doi=10.1.1.118.3056&rep=rep1&type=pdf#page=34
 // and https://www.nayuki.io/page/fast-discrete-cosine-transform-algorithms
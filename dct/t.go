@@ -11,17 +11,26 @@ type T struct {
 	tmp    []float64
 	cosTbl [][]float64
 	scf    float64
+	// qCos holds cos(pi*k/(2n)) for k in [0, 4n) when n
+	// is not a power of 2.
+	qCos []float64
 }
 
 // New creates a new T for transforming data of
-// length n.  n must be a power of 2 or New panics.
+// length n.  n must be positive or New panics.
+//
+// If n is a power of 2, the transform is O(n log n),
+// otherwise it is computed directly in O(n*n).
 func New(n int) *T {
+	if n <= 0 {
+		panic("non-positive size")
+	}
 	p := uint(0)
 	for 1<<p < n {
 		p++
 	}
 	if n != 1<<p {
-		panic("not a power of 2")
+		return newDirect(n)
 	}
 	var ct [][]float64
 	if p >= uint(len(cosTbl)) {
@@ -36,7 +45,7 @@ func New(n int) *T {
 		ct = cosTbl
 	}
 	res := &T{tmp: make([]float64, n), cosTbl: ct, p: p}
-	res.scf = math.Sqrt(1.0 / float64(n/2))
+	res.scf = math.Sqrt(2 / float64(n))
 	return res
 }
 
@@ -48,6 +57,10 @@ func (t *T) Do(d []float64) {
 	if len(d) != len(t.tmp) {
 		panic("wrong size input")
 	}
+	if t.qCos != nil {
+		t.doDirect(d)
+		return
+	}
 	t.doRec(d, t.tmp, t.p)
 	t.scale(d)
 }
@@ -89,6 +102,10 @@ func (t *T) Inv(d []float64) {
 		panic("wrong input size")
 	}
 	d[0] /= 2
+	if t.qCos != nil {
+		t.invDirect(d)
+		return
+	}
 	t.invRec(d, t.tmp, t.p)
 	t.scale(d)
 }
@@ -125,3 +142,54 @@ func (t *T) invRec(d, e []float64, p uint) {
 		d[top-i] = x - y
 	}
 }
+
+func newDirect(n int) *T {
+	m := 4 * n
+	qc := make([]float64, m)
+	pion := math.Pi / float64(2*n)
+	for k := range qc {
+		qc[k] = math.Cos(float64(k) * pion)
+	}
+	return &T{
+		tmp:  make([]float64, n),
+		qCos: qc,
+		scf:  math.Sqrt(2 / float64(n))}
+}
+
+// doDirect computes the scaled type II dct of d in place
+// using cos((2j+1)i*pi/(2n)) = qCos[(2j+1)i mod 4n].
+func (t *T) doDirect(d []float64) {
+	n := len(d)
+	m := 4 * n
+	e := t.tmp
+	for i := range e {
+		ttl := 0.0
+		k, dk := i, 2*i
+		for _, v := range d {
+			ttl += v * t.qCos[k]
+			k = (k + dk) % m
+		}
+		e[i] = ttl
+	}
+	copy(d, e)
+	t.scale(d)
+}
+
+// invDirect computes the scaled type III dct of d, whose first
+// element has already been halved, in place.
+func (t *T) invDirect(d []float64) {
+	n := len(d)
+	m := 4 * n
+	e := t.tmp
+	for j := range e {
+		ttl := 0.0
+		k, dk := 0, 2*j+1
+		for _, v := range d {
+			ttl += v * t.qCos[k]
+			k = (k + dk) % m
+		}
+		e[j] = ttl
+	}
+	copy(d, e)
+	t.scale(d)
+}
@@ -31,6 +31,45 @@ func TestTNaive(t *testing.T) {
 	}
 }
 
+func TestTNonPow2(t *testing.T) {
+	for _, n := range []int{1, 3, 12, 13, 40} {
+		d := make([]float64, n)
+		org := make([]float64, n)
+		for i := range d {
+			d[i] = rand.Float64()
+		}
+		copy(org, d)
+		ct := New(n)
+		ct.Do(d)
+		exp := dct2(org)
+		for i, v := range d {
+			if math.Abs(v-exp[i]) > 1e-12 {
+				t.Errorf("n=%d %d: got %f not %f", n, i, v, exp[i])
+			}
+		}
+		ct.Inv(d)
+		for i, v := range d {
+			if math.Abs(v-org[i]) > 1e-12 {
+				t.Errorf("n=%d %d: inv got %f not %f", n, i, v, org[i])
+			}
+		}
+	}
+}
+
+// dct2 returns the DCT-II of d scaled by sqrt(2/len(d)).  Unlike Naive, it
+// scales correctly for odd lengths.
+func dct2(d []float64) []float64 {
+	n := float64(len(d))
+	res := make([]float64, len(d))
+	for i := range res {
+		for j, v := range d {
+			res[i] += v * math.Cos((float64(j)+0.5)*float64(i)*math.Pi/n)
+		}
+		res[i] *= math.Sqrt(2 / n)
+	}
+	return res
+}
+
 func TestCmp(t *testing.T) {
 	d := []float64{-0.999984, -0.736924, 0.511211, -0.082700}
 	dct := New(len(d))
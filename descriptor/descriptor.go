@@ -0,0 +1,300 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package descriptor
+
+import (
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/zikichombo/dsp/fft"
+	"github.com/zikichombo/dsp/stft"
+	"github.com/zikichombo/sound/freq"
+)
+
+// bins returns the number of bins of s from 0 through the Nyquist
+// frequency.
+func bins(s *fft.S) int {
+	return s.N()/2 + 1
+}
+
+// hz returns the frequency in Hertz of bin k of s at sample rate sr.
+func hz(s *fft.S, sr freq.T, k int) float64 {
+	return float64(k) * sr.Float64() / float64(s.N())
+}
+
+func toFreq(f float64) freq.T {
+	return freq.T(f * float64(freq.Hertz))
+}
+
+// moments returns the sum of the magnitudes of s and the centroid and the
+// 2nd, 3rd and 4th central moments in Hertz of the magnitude spectrum.
+func moments(s *fft.S, sr freq.T) (ttl, c, m2, m3, m4 float64) {
+	m := bins(s)
+	for k := 0; k < m; k++ {
+		a := s.Mag(k)
+		ttl += a
+		c += hz(s, sr, k) * a
+	}
+	if ttl == 0 {
+		return
+	}
+	c /= ttl
+	for k := 0; k < m; k++ {
+		a := s.Mag(k)
+		d := hz(s, sr, k) - c
+		d2 := d * d
+		m2 += d2 * a
+		m3 += d2 * d * a
+		m4 += d2 * d2 * a
+	}
+	m2 /= ttl
+	m3 /= ttl
+	m4 /= ttl
+	return
+}
+
+// Centroid returns the magnitude weighted mean frequency of s, a spectrum
+// of a signal with sample rate sr.
+func Centroid(s *fft.S, sr freq.T) freq.T {
+	_, c, _, _, _ := moments(s, sr)
+	return toFreq(c)
+}
+
+// Spread returns the magnitude weighted standard deviation of the
+// frequencies of s about its centroid.
+func Spread(s *fft.S, sr freq.T) freq.T {
+	_, _, m2, _, _ := moments(s, sr)
+	return toFreq(math.Sqrt(m2))
+}
+
+// Skewness returns the skewness of the magnitude spectrum of s, which is 0
+// for spectra symmetric about their centroid, positive for spectra with
+// more energy below the centroid and negative otherwise.
+func Skewness(s *fft.S) float64 {
+	_, _, m2, m3, _ := moments(s, freq.Hertz)
+	if m2 == 0 {
+		return 0
+	}
+	return m3 / math.Pow(m2, 1.5)
+}
+
+// Kurtosis returns the kurtosis of the magnitude spectrum of s, which is 3
+// for spectra shaped like a normal distribution, larger for more peaked
+// spectra and smaller for flatter ones.
+func Kurtosis(s *fft.S) float64 {
+	_, _, m2, _, m4 := moments(s, freq.Hertz)
+	if m2 == 0 {
+		return 0
+	}
+	return m4 / (m2 * m2)
+}
+
+// Rolloff returns the lowest frequency of a bin of s at or below which
+// lies the fraction p of the power of s, commonly 0.85 or 0.95.
+func Rolloff(s *fft.S, sr freq.T, p float64) freq.T {
+	m := bins(s)
+	ttl := 0.0
+	for k := 0; k < m; k++ {
+		a := s.Mag(k)
+		ttl += a * a
+	}
+	if ttl == 0 {
+		return 0
+	}
+	lim := p * ttl
+	acc := 0.0
+	for k := 0; k < m; k++ {
+		a := s.Mag(k)
+		acc += a * a
+		if acc >= lim {
+			return toFreq(hz(s, sr, k))
+		}
+	}
+	return toFreq(hz(s, sr, m-1))
+}
+
+// Flatness returns the ratio of the geometric mean to the arithmetic mean
+// of the power spectrum of s, which is 1 for a flat spectrum and near 0 for
+// tonal sounds.
+func Flatness(s *fft.S) float64 {
+	m := bins(s)
+	lg, ttl := 0.0, 0.0
+	for k := 0; k < m; k++ {
+		a := s.Mag(k)
+		p := a * a
+		ttl += p
+		lg += math.Log(p)
+	}
+	if ttl == 0 {
+		return 0
+	}
+	fm := float64(m)
+	return math.Exp(lg/fm) / (ttl / fm)
+}
+
+// Crest returns the ratio of the maximum to the mean of the magnitude
+// spectrum of s.
+func Crest(s *fft.S) float64 {
+	m := bins(s)
+	mx, ttl := 0.0, 0.0
+	for k := 0; k < m; k++ {
+		a := s.Mag(k)
+		ttl += a
+		if a > mx {
+			mx = a
+		}
+	}
+	if ttl == 0 {
+		return 0
+	}
+	return mx / (ttl / float64(m))
+}
+
+// Slope returns the slope per Hertz of the linear regression of the
+// magnitude spectrum of s against frequency, normalized by the sum of the
+// magnitudes.
+func Slope(s *fft.S, sr freq.T) float64 {
+	m := bins(s)
+	var sf, sa, sff, sfa float64
+	for k := 0; k < m; k++ {
+		f, a := hz(s, sr, k), s.Mag(k)
+		sf += f
+		sa += a
+		sff += f * f
+		sfa += f * a
+	}
+	fm := float64(m)
+	den := sa * (fm*sff - sf*sf)
+	if den == 0 {
+		return 0
+	}
+	return (fm*sfa - sf*sa) / den
+}
+
+// Decrease returns the average of the decrease of the magnitude spectrum
+// of s from bin 0 to each other bin divided by the index of the bin,
+// normalized by the sum of the magnitudes of the other bins, which
+// emphasizes the slope of the lower frequencies.
+func Decrease(s *fft.S) float64 {
+	m := bins(s)
+	a0 := s.Mag(0)
+	num, den := 0.0, 0.0
+	for k := 1; k < m; k++ {
+		a := s.Mag(k)
+		num += (a - a0) / float64(k)
+		den += a
+	}
+	if den == 0 {
+		return 0
+	}
+	return num / den
+}
+
+// BandRatios places in dst the fraction of the power of s in each band of
+// frequencies [edges[i], edges[i+1]) and returns dst if it has sufficient
+// capacity or a new slice otherwise.  The power of bins outside all bands
+// counts towards the total.
+//
+// BandRatios returns a non-nil error if edges are not ascending.
+func BandRatios(dst []float64, s *fft.S, sr freq.T, edges []freq.T) ([]float64, error) {
+	nb := len(edges) - 1
+	if nb < 0 {
+		nb = 0
+	}
+	for i := 0; i < nb; i++ {
+		if edges[i+1] < edges[i] {
+			return nil, fmt.Errorf("band edges not ascending at %d: %s > %s", i, edges[i], edges[i+1])
+		}
+	}
+	if cap(dst) < nb {
+		dst = make([]float64, nb)
+	}
+	dst = dst[:nb]
+	for i := range dst {
+		dst[i] = 0
+	}
+	m := bins(s)
+	ttl := 0.0
+	b := 0
+	for k := 0; k < m; k++ {
+		a := s.Mag(k)
+		p := a * a
+		ttl += p
+		f := hz(s, sr, k)
+		for b < nb && f >= edges[b+1].Float64() {
+			b++
+		}
+		if b < nb && f >= edges[b].Float64() {
+			dst[b] += p
+		}
+	}
+	if ttl == 0 {
+		return dst, nil
+	}
+	for i := range dst {
+		dst[i] /= ttl
+	}
+	return dst, nil
+}
+
+// Flux returns the euclidean distance between the magnitude spectra of
+// prev and cur.  If rectify is true, only increases in magnitude are
+// counted, which emphasizes onsets.
+//
+// Flux returns a non-nil error if prev and cur are of different sizes.
+func Flux(prev, cur *fft.S, rectify bool) (float64, error) {
+	if prev.N() != cur.N() {
+		return 0, fmt.Errorf("wrong length %d != %d", cur.N(), prev.N())
+	}
+	m := bins(cur)
+	ttl := 0.0
+	for k := 0; k < m; k++ {
+		d := cur.Mag(k) - prev.Mag(k)
+		if rectify && d < 0 {
+			continue
+		}
+		ttl += d * d
+	}
+	return math.Sqrt(ttl), nil
+}
+
+// Fluxes returns the flux, as in Flux, of each frame of fr relative to the
+// previous one, reading frames until fr returns io.EOF.  The flux of the
+// first frame is 0.  The magnitude of a bin of a frame of several channels
+// is the square root of the sum of the powers of the channels.
+//
+// Fluxes returns a non-nil error if fr returns an error other than io.EOF.
+func Fluxes(fr stft.Framer, rectify bool) ([]float64, error) {
+	m := fr.N()/2 + 1
+	prev := make([]float64, m)
+	cur := make([]float64, m)
+	var res []float64
+	for {
+		f, e := fr.Next()
+		if e == io.EOF {
+			return res, nil
+		}
+		if e != nil {
+			return nil, e
+		}
+		cur = f.Power(cur)
+		for k, p := range cur {
+			cur[k] = math.Sqrt(p)
+		}
+		if res == nil {
+			copy(prev, cur)
+		}
+		ttl := 0.0
+		for k, a := range cur {
+			d := a - prev[k]
+			if rectify && d < 0 {
+				continue
+			}
+			ttl += d * d
+		}
+		res = append(res, math.Sqrt(ttl))
+		prev, cur = cur, prev
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package descriptor
+
+import (
+	"math"
+	"testing"
+
+	"github.com/zikichombo/dsp/fft"
+	"github.com/zikichombo/dsp/stft"
+	"github.com/zikichombo/sound/freq"
+)
+
+const (
+	sr = 8000 * freq.Hertz
+	n  = 256 // 31.25Hz per bin
+)
+
+// spec returns a spectrum of size n with bin magnitudes mags and 0 above.
+func spec(mags ...float64) *fft.S {
+	s := fft.NewSN(n)
+	for k := 0; k < n; k++ {
+		s.SetMag(k, 0)
+	}
+	for k, m := range mags {
+		s.SetMag(k, m)
+		if k > 0 {
+			s.SetMag(n-k, m)
+		}
+	}
+	return s
+}
+
+func flat() *fft.S {
+	m := make([]float64, n/2+1)
+	for i := range m {
+		m[i] = 1
+	}
+	return spec(m...)
+}
+
+func near(a, b, tol float64) bool {
+	return math.Abs(a-b) <= tol
+}
+
+func TestShape(t *testing.T) {
+	s := spec(0, 0, 1, 2, 1)
+	if c := Centroid(s, sr); c != 3*31250*freq.MilliHertz {
+		t.Errorf("got centroid %s", c)
+	}
+	if sp := Spread(s, sr).Float64(); !near(sp, 31.25*math.Sqrt(0.5), 1e-6) {
+		t.Errorf("got spread %f", sp)
+	}
+	if sk := Skewness(s); !near(sk, 0, 1e-9) {
+		t.Errorf("got skewness %f", sk)
+	}
+	if k := Kurtosis(s); !near(k, 2, 1e-9) {
+		t.Errorf("got kurtosis %f", k)
+	}
+	if sk := Skewness(spec(0, 4, 2, 1)); !(sk > 0) {
+		t.Errorf("got skewness %f for a right tail", sk)
+	}
+	if c := Centroid(spec(), sr); c != 0 {
+		t.Errorf("got centroid %s of silence", c)
+	}
+	f := flat()
+	if c := Centroid(f, sr).Float64(); !near(c, 2000, 1e-6) {
+		t.Errorf("got flat centroid %f", c)
+	}
+}
+
+func TestEnergy(t *testing.T) {
+	f := flat()
+	if v := Flatness(f); !near(v, 1, 1e-12) {
+		t.Errorf("got flatness %f", v)
+	}
+	if v := Crest(f); !near(v, 1, 1e-12) {
+		t.Errorf("got crest %f", v)
+	}
+	if v := Slope(f, sr); !near(v, 0, 1e-12) {
+		t.Errorf("got slope %f", v)
+	}
+	if v := Decrease(f); !near(v, 0, 1e-12) {
+		t.Errorf("got decrease %f", v)
+	}
+	if r := Rolloff(f, sr, 0.5).Float64(); !near(r, 2000, 1e-6) {
+		t.Errorf("got rolloff %f", r)
+	}
+	tone := spec(0, 0, 0, 1)
+	if v := Flatness(tone); v != 0 {
+		t.Errorf("got tone flatness %f", v)
+	}
+	if v := Crest(tone); !near(v, float64(n/2+1), 1e-9) {
+		t.Errorf("got tone crest %f", v)
+	}
+	if r := Rolloff(tone, sr, 0.85).Float64(); !near(r, 93.75, 1e-6) {
+		t.Errorf("got tone rolloff %f", r)
+	}
+	m := make([]float64, n/2+1)
+	for i := range m {
+		m[i] = float64(len(m) - i)
+	}
+	if v := Slope(spec(m...), sr); !(v < 0) {
+		t.Errorf("got slope %f for a falling spectrum", v)
+	}
+	if v := Decrease(spec(m...)); !(v < 0) {
+		t.Errorf("got decrease %f for a falling spectrum", v)
+	}
+}
+
+func TestBandRatios(t *testing.T) {
+	f := flat()
+	edges := []freq.T{0, 1000 * freq.Hertz, 4000 * freq.Hertz}
+	rs, e := BandRatios(nil, f, sr, edges)
+	if e != nil {
+		t.Fatal(e)
+	}
+	// bins 0..31 and 32..127 of 129.
+	if !near(rs[0], 32.0/129, 1e-12) || !near(rs[1], 96.0/129, 1e-12) {
+		t.Errorf("got %v", rs)
+	}
+	if _, e := BandRatios(nil, f, sr, []freq.T{edges[1], edges[0]}); e == nil {
+		t.Errorf("expected edges error")
+	}
+}
+
+func TestFlux(t *testing.T) {
+	a, b := spec(1, 2, 3), spec(1, 4, 1)
+	v, e := Flux(a, b, false)
+	if e != nil {
+		t.Fatal(e)
+	}
+	if !near(v, math.Sqrt(8), 1e-12) {
+		t.Errorf("got flux %f", v)
+	}
+	if v, _ := Flux(a, b, true); !near(v, 2, 1e-12) {
+		t.Errorf("got rectified flux %f", v)
+	}
+	if _, e := Flux(a, fft.NewSN(n/2), false); e == nil {
+		t.Errorf("expected length error")
+	}
+}
+
+func TestFluxes(t *testing.T) {
+	d := make([]float64, 4096)
+	for i := 2048; i < len(d); i++ {
+		d[i] = math.Sin(2 * math.Pi * 440 * float64(i) / sr.Float64())
+	}
+	a, e := stft.NewSlice(d, sr, n, n/2, nil, 1)
+	if e != nil {
+		t.Fatal(e)
+	}
+	fs, e := Fluxes(a, true)
+	if e != nil {
+		t.Fatal(e)
+	}
+	if len(fs) == 0 || fs[0] != 0 {
+		t.Fatalf("got %v", fs)
+	}
+	mx := 0
+	for i, v := range fs {
+		if v > fs[mx] {
+			mx = i
+		}
+	}
+	// the onset at 2048 is in frames 15 and 16.
+	if mx < 14 || mx > 16 {
+		t.Errorf("got onset at frame %d", mx)
+	}
+}
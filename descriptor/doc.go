@@ -0,0 +1,18 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+// Package descriptor provides scalar descriptors of spectra.
+//
+// Package descriptor is part of http://zikichombo.org
+//
+// Descriptors are computed from the magnitudes of the frequency bins from
+// 0 through the Nyquist frequency of an *fft.S of a real signal.  The
+// shape descriptors, Centroid, Spread, Skewness and Kurtosis, treat the
+// magnitude spectrum as a distribution over frequency.  Rolloff, Flatness,
+// Crest, Slope, Decrease and BandRatios describe the distribution of
+// energy, and Flux describes the change between successive spectra, as
+// given for example by the frames of package stft.
+//
+// Frequencies are reported as freq.T given the sample rate.  Descriptors
+// of a spectrum with no energy are 0.
+package descriptor
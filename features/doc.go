@@ -0,0 +1,17 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+// Package features provides spectral features of sound for analysis.
+//
+// Package features is part of http://zikichombo.org
+//
+// A FilterBank maps power spectra, given as slices of bin powers, half
+// complex transforms or *fft.S, to the energies of triangular bands equally
+// spaced on a mel scale.  Both the HTK and Slaney variants of the mel scale
+// are supported.
+//
+// MFCC computes mel frequency cepstral coefficients from power spectra by
+// applying a filter bank, log compression and a type II dct, and
+// MFCCs computes them for the frames of a sound.Source together with their
+// deltas and delta-deltas.
+package features
@@ -0,0 +1,203 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package features
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/zikichombo/dsp/fft"
+	"github.com/zikichombo/dsp/internal/mel"
+	"github.com/zikichombo/sound/freq"
+)
+
+// Scale is a mel scale.
+type Scale int
+
+const (
+	// HTK is the mel scale 2595*log10(1+f/700) used by HTK, whose filter
+	// banks have triangles with a peak of 1.
+	HTK Scale = iota
+	// Slaney is the mel scale of Slaney's Auditory Toolbox, linear below
+	// 1kHz and logarithmic above, whose filter banks have triangles of
+	// equal area.
+	Slaney
+)
+
+// String implements Stringer.
+func (s Scale) String() string {
+	switch s {
+	case HTK:
+		return "htk"
+	case Slaney:
+		return "slaney"
+	}
+	return fmt.Sprintf("Scale(%d)", int(s))
+}
+
+// ToMel returns the frequency f in Hertz in mels on scale s.
+func (s Scale) ToMel(f float64) float64 {
+	if s == Slaney {
+		return mel.SlaneyFromHz(f)
+	}
+	return mel.FromHz(f)
+}
+
+// FromMel returns the frequency in Hertz of m mels on scale s.
+func (s Scale) FromMel(m float64) float64 {
+	if s == Slaney {
+		return mel.SlaneyToHz(m)
+	}
+	return mel.ToHz(m)
+}
+
+// FilterBank is a bank of triangular filters equally spaced on a mel scale
+// applied to power spectra.
+type FilterBank struct {
+	scale   Scale
+	n       int
+	centers []freq.T
+	los     []int
+	ws      [][]float64
+	pow     []float64
+}
+
+// NewFilterBank creates a filter bank of nb bands equally spaced in mels
+// on scale sc between min and max for spectra of transforms of size n at
+// sample rate sr.  If max is 0, the Nyquist frequency is used.  Each band
+// is a triangle with vertices at the centers of the neighbouring bands,
+// and bands narrower than a frequency bin may have no weight.
+//
+// NewFilterBank returns a non-nil error if nb or n is not positive or if
+// min and max do not give a range within [0, sr/2].
+func NewFilterBank(sc Scale, nb int, sr freq.T, n int, min, max freq.T) (*FilterBank, error) {
+	if nb < 1 {
+		return nil, fmt.Errorf("invalid number of bands %d", nb)
+	}
+	if n < 1 {
+		return nil, fmt.Errorf("invalid transform size %d", n)
+	}
+	ny := sr.Float64() / 2
+	lo, hi := min.Float64(), max.Float64()
+	if max == 0 {
+		hi = ny
+	}
+	if lo < 0 || lo >= hi || hi > ny {
+		return nil, fmt.Errorf("invalid frequency range %s..%s", min, max)
+	}
+	m := n/2 + 1
+	bin := sr.Float64() / float64(n)
+	ml, mh := sc.ToMel(lo), sc.ToMel(hi)
+	pts := make([]float64, nb+2)
+	for i := range pts {
+		pts[i] = sc.FromMel(ml + (mh-ml)*float64(i)/float64(nb+1))
+	}
+	res := &FilterBank{
+		scale:   sc,
+		n:       n,
+		centers: make([]freq.T, nb),
+		los:     make([]int, nb),
+		ws:      make([][]float64, nb),
+		pow:     make([]float64, m)}
+	for j := 0; j < nb; j++ {
+		fl, fc, fh := pts[j], pts[j+1], pts[j+2]
+		res.centers[j] = freq.T(fc * float64(freq.Hertz))
+		kl := int(math.Ceil(fl / bin))
+		kh := int(math.Floor(fh / bin))
+		if kh >= m {
+			kh = m - 1
+		}
+		g := 1.0
+		if sc == Slaney {
+			g = 2 / (fh - fl)
+		}
+		res.los[j] = kl
+		var w []float64
+		for k := kl; k <= kh; k++ {
+			f := float64(k) * bin
+			v := 0.0
+			if f <= fc {
+				v = (f - fl) / (fc - fl)
+			} else {
+				v = (fh - f) / (fh - fc)
+			}
+			w = append(w, v*g)
+		}
+		res.ws[j] = w
+	}
+	return res, nil
+}
+
+// Scale returns the mel scale of b.
+func (b *FilterBank) Scale() Scale {
+	return b.scale
+}
+
+// Bands returns the number of bands of b.
+func (b *FilterBank) Bands() int {
+	return len(b.ws)
+}
+
+// N returns the transform size of the spectra to which b applies.
+func (b *FilterBank) N() int {
+	return b.n
+}
+
+// Centers returns the center frequencies of the bands of b.
+func (b *FilterBank) Centers() []freq.T {
+	return b.centers
+}
+
+// Weights returns the weights of band i of b, and the index of the
+// frequency bin of the first weight.
+func (b *FilterBank) Weights(i int) (lo int, w []float64) {
+	return b.los[i], b.ws[i]
+}
+
+// Apply places the energies of each band of the powers pow of frequency
+// bins 0 through n/2 in dst, returning dst if it has sufficient capacity
+// or a new slice otherwise.
+//
+// Apply returns a non-nil error if pow does not have length n/2+1.
+func (b *FilterBank) Apply(dst, pow []float64) ([]float64, error) {
+	if len(pow) != len(b.pow) {
+		return nil, fmt.Errorf("wrong length %d != %d", len(pow), len(b.pow))
+	}
+	if cap(dst) < len(b.ws) {
+		dst = make([]float64, len(b.ws))
+	}
+	dst = dst[:len(b.ws)]
+	for j, w := range b.ws {
+		ttl := 0.0
+		for k, v := range w {
+			ttl += pow[b.los[j]+k] * v
+		}
+		dst[j] = ttl
+	}
+	return dst, nil
+}
+
+// ApplyHalfComplex is like Apply for the power spectrum of hc.
+func (b *FilterBank) ApplyHalfComplex(dst []float64, hc fft.HalfComplex) ([]float64, error) {
+	if len(hc) != b.n {
+		return nil, fmt.Errorf("wrong length %d != %d", len(hc), b.n)
+	}
+	for i := range b.pow {
+		c := hc.Cmplx(i)
+		b.pow[i] = real(c)*real(c) + imag(c)*imag(c)
+	}
+	return b.Apply(dst, b.pow)
+}
+
+// ApplyS is like Apply for the power spectrum of s.
+func (b *FilterBank) ApplyS(dst []float64, s *fft.S) ([]float64, error) {
+	if s.N() != b.n {
+		return nil, fmt.Errorf("wrong length %d != %d", s.N(), b.n)
+	}
+	for i := range b.pow {
+		m := s.Mag(i)
+		b.pow[i] = m * m
+	}
+	return b.Apply(dst, b.pow)
+}
@@ -0,0 +1,117 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package features
+
+import (
+	"math"
+	"testing"
+
+	"github.com/zikichombo/dsp/fft"
+	"github.com/zikichombo/sound/freq"
+)
+
+const sr = 16000 * freq.Hertz
+
+func TestScale(t *testing.T) {
+	for _, s := range []Scale{HTK, Slaney} {
+		for _, f := range []float64{0, 100, 999, 1000, 1001, 4000, 8000} {
+			if g := s.FromMel(s.ToMel(f)); math.Abs(g-f) > 1e-9 {
+				t.Errorf("%s: got %f not %f", s, g, f)
+			}
+		}
+	}
+	if m := HTK.ToMel(1000); math.Abs(m-1000) > 0.1 {
+		t.Errorf("htk: 1000Hz gave %f mels", m)
+	}
+	if m := Slaney.ToMel(1000); math.Abs(m-15) > 1e-9 {
+		t.Errorf("slaney: 1000Hz gave %f mels", m)
+	}
+}
+
+func TestFilterBank(t *testing.T) {
+	n := 512
+	for _, s := range []Scale{HTK, Slaney} {
+		fb, e := NewFilterBank(s, 40, sr, n, 0, 0)
+		if e != nil {
+			t.Fatal(e)
+		}
+		cs := fb.Centers()
+		for i := 1; i < len(cs); i++ {
+			if cs[i] <= cs[i-1] {
+				t.Fatalf("%s: centers not ascending at %d", s, i)
+			}
+		}
+		for j := 0; j < fb.Bands(); j++ {
+			lo, w := fb.Weights(j)
+			if lo < 0 || lo+len(w) > n/2+1 {
+				t.Errorf("%s: band %d out of range", s, j)
+			}
+			for _, v := range w {
+				if v < 0 {
+					t.Errorf("%s: negative weight in band %d", s, j)
+				}
+			}
+		}
+		// a pure tone at a center excites that band the most.
+		j := 20
+		k := int(math.Floor(cs[j].Float64()*float64(n)/sr.Float64() + 0.5))
+		pow := make([]float64, n/2+1)
+		pow[k] = 1
+		es, e := fb.Apply(nil, pow)
+		if e != nil {
+			t.Fatal(e)
+		}
+		mx := 0
+		for i, v := range es {
+			if v > es[mx] {
+				mx = i
+			}
+		}
+		if mx < j-1 || mx > j+1 {
+			t.Errorf("%s: got max band %d not %d", s, mx, j)
+		}
+		if _, e := fb.Apply(nil, pow[1:]); e == nil {
+			t.Errorf("%s: expected length error", s)
+		}
+	}
+	if _, e := NewFilterBank(HTK, 0, sr, n, 0, 0); e == nil {
+		t.Errorf("expected bands error")
+	}
+	if _, e := NewFilterBank(HTK, 10, sr, n, 4000*freq.Hertz, 1000*freq.Hertz); e == nil {
+		t.Errorf("expected range error")
+	}
+}
+
+func TestFilterBankSpectra(t *testing.T) {
+	n := 256
+	fb, e := NewFilterBank(Slaney, 20, sr, n, 100*freq.Hertz, 6000*freq.Hertz)
+	if e != nil {
+		t.Fatal(e)
+	}
+	d := make([]float64, n)
+	for i := range d {
+		d[i] = math.Sin(2*math.Pi*1200*float64(i)/sr.Float64()) + 0.3*math.Cos(float64(i))
+	}
+	ft := fft.NewReal(n)
+	hc := ft.Do(d)
+	pow := make([]float64, n/2+1)
+	for i := range pow {
+		c := hc.Cmplx(i)
+		pow[i] = real(c)*real(c) + imag(c)*imag(c)
+	}
+	a, _ := fb.Apply(nil, pow)
+	b, e := fb.ApplyHalfComplex(nil, hc)
+	if e != nil {
+		t.Fatal(e)
+	}
+	c, e := fb.ApplyS(nil, fft.NewSHalfComplex(hc))
+	if e != nil {
+		t.Fatal(e)
+	}
+	for i := range a {
+		if math.Abs(a[i]-b[i]) > 1e-9 || math.Abs(a[i]-c[i]) > 1e-9 {
+			t.Errorf("band %d: got %f %f %f", i, a[i], b[i], c[i])
+		}
+	}
+}
@@ -0,0 +1,267 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package features
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/zikichombo/dsp/dct"
+	"github.com/zikichombo/dsp/fft"
+	"github.com/zikichombo/dsp/stft"
+	"github.com/zikichombo/dsp/wfn"
+	"github.com/zikichombo/sound"
+	"github.com/zikichombo/sound/freq"
+)
+
+// DefaultFloor is the default floor applied to band energies before taking
+// logarithms.
+const DefaultFloor = 1e-10
+
+// Log replaces each element of d with its natural logarithm, replacing
+// values less than floor with floor first.
+func Log(d []float64, floor float64) {
+	for i, v := range d {
+		if !(v >= floor) {
+			v = floor
+		}
+		d[i] = math.Log(v)
+	}
+}
+
+// MFCC computes mel frequency cepstral coefficients of power spectra.
+type MFCC struct {
+	fb    *FilterBank
+	nc    int
+	dct   *dct.T
+	buf   []float64
+	floor float64
+}
+
+// NewMFCC creates an MFCC giving the first nc coefficients of the type II
+// dct, as scaled by dct.T, of the log energies of the bands of fb.
+//
+// NewMFCC returns a non-nil error if nc is not in [1, fb.Bands()].
+func NewMFCC(fb *FilterBank, nc int) (*MFCC, error) {
+	nb := fb.Bands()
+	if nc < 1 || nc > nb {
+		return nil, fmt.Errorf("invalid number of coefficients %d for %d bands", nc, nb)
+	}
+	return &MFCC{
+		fb:    fb,
+		nc:    nc,
+		dct:   dct.New(nb),
+		buf:   make([]float64, nb),
+		floor: DefaultFloor}, nil
+}
+
+// SetFloor sets the floor applied to band energies before taking
+// logarithms, which defaults to DefaultFloor.
+func (m *MFCC) SetFloor(v float64) {
+	m.floor = v
+}
+
+// FilterBank returns the filter bank of m.
+func (m *MFCC) FilterBank() *FilterBank {
+	return m.fb
+}
+
+// Coefs returns the number of coefficients m computes.
+func (m *MFCC) Coefs() int {
+	return m.nc
+}
+
+// Do places the coefficients of the powers pow of frequency bins 0
+// through n/2 in dst, returning dst if it has sufficient capacity or a new
+// slice otherwise.
+//
+// Do returns a non-nil error if pow does not have length n/2+1.
+func (m *MFCC) Do(dst, pow []float64) ([]float64, error) {
+	e, err := m.fb.Apply(m.buf, pow)
+	if err != nil {
+		return nil, err
+	}
+	return m.finish(dst, e), nil
+}
+
+// DoHalfComplex is like Do for the power spectrum of hc.
+func (m *MFCC) DoHalfComplex(dst []float64, hc fft.HalfComplex) ([]float64, error) {
+	e, err := m.fb.ApplyHalfComplex(m.buf, hc)
+	if err != nil {
+		return nil, err
+	}
+	return m.finish(dst, e), nil
+}
+
+// DoS is like Do for the power spectrum of s.
+func (m *MFCC) DoS(dst []float64, s *fft.S) ([]float64, error) {
+	e, err := m.fb.ApplyS(m.buf, s)
+	if err != nil {
+		return nil, err
+	}
+	return m.finish(dst, e), nil
+}
+
+func (m *MFCC) finish(dst, e []float64) []float64 {
+	Log(e, m.floor)
+	m.dct.Do(e)
+	if cap(dst) < m.nc {
+		dst = make([]float64, m.nc)
+	}
+	dst = dst[:m.nc]
+	copy(dst, e)
+	return dst
+}
+
+// Deltas returns the regression estimates of the time derivatives of the
+// frames of coefficients cs, over w frames on either side of each frame:
+//
+//	d[t] = sum_{k=1}^{w} k*(cs[t+k]-cs[t-k]) / (2*sum_{k=1}^{w} k*k)
+//
+// Frames beyond either end are taken to be copies of the end frames.
+// Deltas panics if w is not positive.
+func Deltas(cs [][]float64, w int) [][]float64 {
+	if w < 1 {
+		panic("non-positive delta width")
+	}
+	den := 0.0
+	for k := 1; k <= w; k++ {
+		den += float64(k * k)
+	}
+	den *= 2
+	n := len(cs)
+	clip := func(t int) int {
+		if t < 0 {
+			return 0
+		}
+		if t >= n {
+			return n - 1
+		}
+		return t
+	}
+	res := make([][]float64, n)
+	for t, c := range cs {
+		d := make([]float64, len(c))
+		for k := 1; k <= w; k++ {
+			a, b := cs[clip(t+k)], cs[clip(t-k)]
+			fk := float64(k)
+			for i := range d {
+				d[i] += fk * (a[i] - b[i])
+			}
+		}
+		for i := range d {
+			d[i] /= den
+		}
+		res[t] = d
+	}
+	return res
+}
+
+// Opts gives options for MFCCs.  Zero values give defaults.
+type Opts struct {
+	Size  int   // frame size, default 512
+	Hop   int   // hop between frames, default Size/2
+	Pad   int   // zero padding factor, default 1
+	Win   wfn.T // window, default periodic Hann
+	Scale Scale // mel scale, default HTK
+	Bands int   // number of mel bands, default 26
+	Coefs int   // number of coefficients, default 13
+	// Min and Max give the range of frequencies of the filter bank.  Max
+	// defaults to the Nyquist frequency.
+	Min, Max freq.T
+	// Width gives the number of frames on either side used for deltas,
+	// default 2.
+	Width int
+	// Floor is the floor applied to band energies, default DefaultFloor.
+	Floor float64
+}
+
+// Frame holds the mfccs of a frame of sound with their deltas and
+// delta-deltas.
+type Frame struct {
+	Time time.Duration // time of the start of the frame
+	C    []float64     // coefficients
+	D    []float64     // deltas
+	DD   []float64     // delta-deltas
+}
+
+// MFCCs computes the mfccs of the frames of src with options o, which may
+// be nil.  The power of all channels of src is summed.
+//
+// MFCCs returns a non-nil error if the options are invalid or if src
+// returns an error other than io.EOF.
+func MFCCs(src sound.Source, o *Opts) ([]Frame, error) {
+	var opts Opts
+	if o != nil {
+		opts = *o
+	}
+	if opts.Size == 0 {
+		opts.Size = 512
+	}
+	if opts.Hop == 0 {
+		opts.Hop = opts.Size / 2
+		if opts.Hop == 0 {
+			opts.Hop = 1
+		}
+	}
+	if opts.Pad == 0 {
+		opts.Pad = 1
+	}
+	if opts.Bands == 0 {
+		opts.Bands = 26
+	}
+	if opts.Coefs == 0 {
+		opts.Coefs = 13
+	}
+	if opts.Width == 0 {
+		opts.Width = 2
+	}
+	if opts.Floor == 0 {
+		opts.Floor = DefaultFloor
+	}
+	if opts.Width < 0 {
+		return nil, fmt.Errorf("invalid delta width %d", opts.Width)
+	}
+	a, e := stft.New(src, opts.Size, opts.Hop, opts.Win, opts.Pad)
+	if e != nil {
+		return nil, e
+	}
+	fb, e := NewFilterBank(opts.Scale, opts.Bands, src.SampleRate(), a.N(), opts.Min, opts.Max)
+	if e != nil {
+		return nil, e
+	}
+	m, e := NewMFCC(fb, opts.Coefs)
+	if e != nil {
+		return nil, e
+	}
+	m.SetFloor(opts.Floor)
+	pow := make([]float64, a.N()/2+1)
+	var res []Frame
+	var cs [][]float64
+	for {
+		f, e := a.Next()
+		if e == io.EOF {
+			break
+		}
+		if e != nil {
+			return nil, e
+		}
+		pow = f.Power(pow)
+		c, e := m.Do(nil, pow)
+		if e != nil {
+			return nil, e
+		}
+		res = append(res, Frame{Time: f.Time, C: c})
+		cs = append(cs, c)
+	}
+	ds := Deltas(cs, opts.Width)
+	dds := Deltas(ds, opts.Width)
+	for i := range res {
+		res[i].D = ds[i]
+		res[i].DD = dds[i]
+	}
+	return res, nil
+}
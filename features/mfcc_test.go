@@ -0,0 +1,102 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package features
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/zikichombo/dsp/dct"
+	"github.com/zikichombo/sound/sndbuf"
+)
+
+func TestMFCC(t *testing.T) {
+	n := 512
+	fb, e := NewFilterBank(HTK, 26, sr, n, 0, 0)
+	if e != nil {
+		t.Fatal(e)
+	}
+	m, e := NewMFCC(fb, 13)
+	if e != nil {
+		t.Fatal(e)
+	}
+	pow := make([]float64, n/2+1)
+	for i := range pow {
+		pow[i] = rand.Float64() + 0.1
+	}
+	c, e := m.Do(nil, pow)
+	if e != nil {
+		t.Fatal(e)
+	}
+	if len(c) != 13 {
+		t.Fatalf("got %d coefs", len(c))
+	}
+	es, _ := fb.Apply(nil, pow)
+	Log(es, DefaultFloor)
+	dct.Naive(es)
+	for i, v := range c {
+		if math.Abs(v-es[i]) > 1e-9 {
+			t.Errorf("coef %d: got %f not %f", i, v, es[i])
+		}
+	}
+	// scaling the power shifts only c0.
+	for i := range pow {
+		pow[i] *= 10
+	}
+	d, _ := m.Do(nil, pow)
+	for i := 1; i < len(d); i++ {
+		if math.Abs(d[i]-c[i]) > 1e-9 {
+			t.Errorf("coef %d changed from %f to %f", i, c[i], d[i])
+		}
+	}
+	if _, e := NewMFCC(fb, 27); e == nil {
+		t.Errorf("expected coefs error")
+	}
+}
+
+func TestDeltas(t *testing.T) {
+	cs := make([][]float64, 10)
+	for i := range cs {
+		cs[i] = []float64{float64(3 * i), 1}
+	}
+	ds := Deltas(cs, 2)
+	for i := 2; i < 8; i++ {
+		if math.Abs(ds[i][0]-3) > 1e-12 || ds[i][1] != 0 {
+			t.Errorf("frame %d: got %v", i, ds[i])
+		}
+	}
+	if ds[0][0] >= 3 || ds[0][0] <= 0 {
+		t.Errorf("edge frame: got %v", ds[0])
+	}
+}
+
+func TestMFCCs(t *testing.T) {
+	d := make([]float64, 16000)
+	for i := range d {
+		d[i] = math.Sin(2 * math.Pi * 440 * float64(i) / sr.Float64())
+	}
+	fs, e := MFCCs(sndbuf.FromSlice(d, sr), &Opts{Size: 400, Hop: 160, Scale: Slaney})
+	if e != nil {
+		t.Fatal(e)
+	}
+	if len(fs) < 16000/160-2 {
+		t.Fatalf("got %d frames", len(fs))
+	}
+	for i, f := range fs {
+		if len(f.C) != 13 || len(f.D) != 13 || len(f.DD) != 13 {
+			t.Fatalf("frame %d: got lengths %d %d %d", i, len(f.C), len(f.D), len(f.DD))
+		}
+	}
+	// a stationary tone has nearly constant coefficients in the middle.
+	mid := fs[len(fs)/2]
+	for i, v := range mid.D {
+		if math.Abs(v) > 1e-6*math.Max(1, math.Abs(mid.C[i])) {
+			t.Errorf("delta %d: got %f", i, v)
+		}
+	}
+	if _, e := MFCCs(sndbuf.FromSlice(d, sr), &Opts{Coefs: 40}); e == nil {
+		t.Errorf("expected coefs error")
+	}
+}
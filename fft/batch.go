@@ -0,0 +1,429 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package fft
+
+import (
+	"fmt"
+	"sync"
+)
+
+const (
+	batchDo = iota
+	batchInv
+)
+
+// Batch performs complex transforms of the same size on a number of
+// channels in one call.
+//
+// The channels may be spread over a configurable number of worker
+// goroutines.  Each worker has its own scratch space but all share the
+// same twiddle factors and other tables.  Once created, Batch does not
+// allocate, apart from starting goroutines when using more than 1 worker,
+// see SetWorkers.
+type Batch struct {
+	n, c  int
+	fts   []*T
+	chans [][]complex128
+	buf   [][]complex128 // for interleaved data
+	wg    sync.WaitGroup
+	op    int
+}
+
+// NewBatch creates a new Batch for c channels of transforms
+// of size n.  The result uses 1 worker.
+func NewBatch(n, c int) *Batch {
+	res := &Batch{
+		n:     n,
+		c:     c,
+		fts:   []*T{New(n)},
+		chans: make([][]complex128, c),
+		buf:   make([][]complex128, c)}
+	for i := range res.buf {
+		res.buf[i] = res.fts[0].Win(nil)
+	}
+	return res
+}
+
+// N returns the size of the transforms.
+func (b *Batch) N() int {
+	return b.n
+}
+
+// Channels returns the number of channels.
+func (b *Batch) Channels() int {
+	return b.c
+}
+
+// Workers returns the number of goroutines which b uses.
+func (b *Batch) Workers() int {
+	return len(b.fts)
+}
+
+// SetWorkers sets the number of goroutines used by b to w.  If w < 1, then 1
+// is used.  If w is greater than the number of channels, then the number of
+// channels is used.
+//
+// The workers are not kept between calls: with w > 1, each call to a
+// transform method starts w-1 goroutines and runs the last worker in the
+// calling goroutine.  Starting a goroutine costs about a microsecond, so
+// more workers only pay off when each one has a few transforms of more than
+// a few hundred points.
+func (b *Batch) SetWorkers(w int) {
+	w = workers(w, b.c)
+	for len(b.fts) < w {
+		b.fts = append(b.fts, b.fts[0].clone())
+	}
+	b.fts = b.fts[:w]
+}
+
+// Scale sets whether or not b scales the results, as in T.Scale.
+func (b *Batch) Scale(v bool) {
+	for _, ft := range b.fts {
+		ft.Scale(v)
+	}
+}
+
+// Win is like T.Win.
+func (b *Batch) Win(c []complex128) []complex128 {
+	return b.fts[0].Win(c)
+}
+
+// Do performs in place transforms on each channel in d.  Each channel
+// should be as in T.Do.
+func (b *Batch) Do(d [][]complex128) error {
+	return b.run(batchDo, d)
+}
+
+// Inv performs in place inverse transforms on each channel in d.
+func (b *Batch) Inv(d [][]complex128) error {
+	return b.run(batchInv, d)
+}
+
+// DoInter performs transforms on the channel interleaved data d, which
+// has b.N() frames of b.Channels() channels, leaving d untouched.  The
+// returned transforms are stored in b and are valid until the next call to
+// DoInter or InvInter.
+func (b *Batch) DoInter(d []complex128) ([][]complex128, error) {
+	if len(d) != b.n*b.c {
+		return nil, fmt.Errorf("wrong length %d != %d", len(d), b.n*b.c)
+	}
+	c := b.c
+	for j, ch := range b.buf {
+		for i := range ch {
+			ch[i] = d[i*c+j]
+		}
+	}
+	if e := b.run(batchDo, b.buf); e != nil {
+		return nil, e
+	}
+	return b.buf, nil
+}
+
+// InvInter performs inverse transforms on the channels of src, placing the
+// results channel interleaved in dst.  src is left untouched unless it was
+// returned from b.DoInter, in which case the inverse is computed in place.
+// It is an error if dst shares memory with src, or if a channel of src
+// shares memory with a channel returned from b.DoInter other than the one
+// in the same position.
+func (b *Batch) InvInter(dst []complex128, src [][]complex128) error {
+	if len(dst) != b.n*b.c {
+		return fmt.Errorf("wrong length %d != %d", len(dst), b.n*b.c)
+	}
+	if len(src) != b.c {
+		return fmt.Errorf("wrong channels %d != %d", len(src), b.c)
+	}
+	for _, ch := range src {
+		if len(ch) != b.n {
+			return fmt.Errorf("wrong length %d != %d", len(ch), b.n)
+		}
+	}
+	if b.n == 0 {
+		return nil
+	}
+	for j, ch := range src {
+		if sameArray(dst, ch) {
+			return fmt.Errorf("channel %d overlaps dst", j)
+		}
+		for k, bch := range b.buf {
+			if sameArray(ch, bch) && (k != j || &ch[0] != &bch[0]) {
+				return fmt.Errorf("channel %d overlaps buffer channel %d", j, k)
+			}
+		}
+	}
+	for j, ch := range src {
+		if &ch[0] != &b.buf[j][0] {
+			copy(b.buf[j], ch)
+		}
+	}
+	if e := b.run(batchInv, b.buf); e != nil {
+		return e
+	}
+	c := b.c
+	for j, ch := range b.buf {
+		for i, v := range ch {
+			dst[i*c+j] = v
+		}
+	}
+	return nil
+}
+
+func (b *Batch) run(op int, d [][]complex128) error {
+	if len(d) != b.c {
+		return fmt.Errorf("wrong channels %d != %d", len(d), b.c)
+	}
+	for _, ch := range d {
+		if e := b.fts[0].ckSrc(ch); e != nil {
+			return e
+		}
+	}
+	b.op = op
+	copy(b.chans, d)
+	w := len(b.fts)
+	b.wg.Add(w - 1)
+	for i := 0; i < w-1; i++ {
+		go b.goWork(i)
+	}
+	b.work(w - 1)
+	b.wg.Wait()
+	return nil
+}
+
+func (b *Batch) goWork(i int) {
+	b.work(i)
+	b.wg.Done()
+}
+
+func (b *Batch) work(i int) {
+	w := len(b.fts)
+	ft := b.fts[i]
+	for _, ch := range b.chans[i*b.c/w : (i+1)*b.c/w] {
+		if b.op == batchDo {
+			ft.Do(ch)
+		} else {
+			ft.Inv(ch)
+		}
+	}
+}
+
+// RealBatch performs real transforms of the same size on a number of
+// channels in one call, like Batch.
+type RealBatch struct {
+	n, c  int
+	fts   []*Real
+	chans [][]float64
+	hcs   []HalfComplex
+	buf   []float64 // for interleaved data
+	wg    sync.WaitGroup
+	op    int
+}
+
+// NewRealBatch creates a new RealBatch for c channels of real transforms
+// of size n.  The result uses 1 worker.
+func NewRealBatch(n, c int) *RealBatch {
+	res := &RealBatch{
+		n:     n,
+		c:     c,
+		fts:   []*Real{NewReal(n)},
+		chans: make([][]float64, c),
+		hcs:   make([]HalfComplex, c),
+		buf:   make([]float64, n*c)}
+	return res
+}
+
+// N returns the size of the transforms.
+func (b *RealBatch) N() int {
+	return b.n
+}
+
+// Channels returns the number of channels.
+func (b *RealBatch) Channels() int {
+	return b.c
+}
+
+// Workers returns the number of goroutines which b uses.
+func (b *RealBatch) Workers() int {
+	return len(b.fts)
+}
+
+// SetWorkers is like Batch.SetWorkers.
+func (b *RealBatch) SetWorkers(w int) {
+	w = workers(w, b.c)
+	for len(b.fts) < w {
+		b.fts = append(b.fts, b.fts[0].clone())
+	}
+	b.fts = b.fts[:w]
+}
+
+// Scale sets whether or not b scales the results, as in Real.Scale.
+func (b *RealBatch) Scale(v bool) {
+	for _, ft := range b.fts {
+		ft.Scale(v)
+	}
+}
+
+// Do performs in place transforms on each channel in d, as in Real.Do.
+// The returned HalfComplex for channel i is d[i].  The returned slice is
+// stored in b and is valid until the next call to a transform method.
+func (b *RealBatch) Do(d [][]float64) ([]HalfComplex, error) {
+	if e := b.ckChans(d); e != nil {
+		return nil, e
+	}
+	copy(b.chans, d)
+	b.run(batchDo)
+	return b.hcs, nil
+}
+
+// Inv performs in place inverse transforms on each channel in hcs, as in
+// Real.Inv.  The returned slice is stored in b and is valid until the next
+// call to a transform method.
+func (b *RealBatch) Inv(hcs []HalfComplex) ([][]float64, error) {
+	if e := b.ckHCs(hcs); e != nil {
+		return nil, e
+	}
+	copy(b.hcs, hcs)
+	b.run(batchInv)
+	return b.chans, nil
+}
+
+// DoInter performs transforms on the channel interleaved data d, which
+// has b.N() frames of b.Channels() channels, leaving d untouched.  The
+// returned spectra are stored in b and are valid until the next call to a
+// transform method.
+func (b *RealBatch) DoInter(d []float64) ([]HalfComplex, error) {
+	if len(d) != len(b.buf) {
+		return nil, fmt.Errorf("wrong length %d != %d", len(d), len(b.buf))
+	}
+	n, c := b.n, b.c
+	for j := range b.chans {
+		ch := b.buf[j*n : (j+1)*n]
+		for i := range ch {
+			ch[i] = d[i*c+j]
+		}
+		b.chans[j] = ch
+	}
+	b.run(batchDo)
+	return b.hcs, nil
+}
+
+// InvInter performs inverse transforms on the spectra in hcs, placing the
+// results channel interleaved in dst.  hcs is left untouched unless it was
+// returned from b.DoInter, in which case the inverse is computed in place.
+// As for Batch.InvInter, dst may not share memory with hcs, and hcs may
+// only share memory with the spectra returned from b.DoInter in the same
+// positions.
+func (b *RealBatch) InvInter(dst []float64, hcs []HalfComplex) error {
+	if len(dst) != len(b.buf) {
+		return fmt.Errorf("wrong length %d != %d", len(dst), len(b.buf))
+	}
+	if e := b.ckHCs(hcs); e != nil {
+		return e
+	}
+	if b.n == 0 {
+		return nil
+	}
+	n, c := b.n, b.c
+	for j, hc := range hcs {
+		if sameRealArray(dst, hc) {
+			return fmt.Errorf("channel %d overlaps dst", j)
+		}
+		if sameRealArray(hc, b.buf) && &hc[0] != &b.buf[j*n] {
+			return fmt.Errorf("channel %d overlaps buffer", j)
+		}
+	}
+	for j, hc := range hcs {
+		ch := b.buf[j*n : (j+1)*n]
+		if &hc[0] != &ch[0] {
+			copy(ch, hc)
+		}
+		b.hcs[j] = HalfComplex(ch)
+	}
+	b.run(batchInv)
+	for j, ch := range b.chans {
+		for i, v := range ch {
+			dst[i*c+j] = v
+		}
+	}
+	return nil
+}
+
+func (b *RealBatch) ckChans(d [][]float64) error {
+	if len(d) != b.c {
+		return fmt.Errorf("wrong channels %d != %d", len(d), b.c)
+	}
+	for _, ch := range d {
+		if len(ch) != b.n {
+			return fmt.Errorf("wrong length %d != %d", len(ch), b.n)
+		}
+	}
+	return nil
+}
+
+func (b *RealBatch) ckHCs(hcs []HalfComplex) error {
+	if len(hcs) != b.c {
+		return fmt.Errorf("wrong channels %d != %d", len(hcs), b.c)
+	}
+	for _, hc := range hcs {
+		if len(hc) != b.n {
+			return fmt.Errorf("wrong length %d != %d", len(hc), b.n)
+		}
+	}
+	return nil
+}
+
+func (b *RealBatch) run(op int) {
+	b.op = op
+	w := len(b.fts)
+	b.wg.Add(w - 1)
+	for i := 0; i < w-1; i++ {
+		go b.goWork(i)
+	}
+	b.work(w - 1)
+	b.wg.Wait()
+}
+
+func (b *RealBatch) goWork(i int) {
+	b.work(i)
+	b.wg.Done()
+}
+
+func (b *RealBatch) work(i int) {
+	w := len(b.fts)
+	ft := b.fts[i]
+	for j := i * b.c / w; j < (i+1)*b.c/w; j++ {
+		if b.op == batchDo {
+			b.hcs[j] = ft.Do(b.chans[j])
+		} else {
+			b.chans[j] = ft.Inv(b.hcs[j])
+		}
+	}
+}
+
+// workers returns the number of workers to use for w requested
+// workers and c channels.
+func workers(w, c int) int {
+	if w > c {
+		w = c
+	}
+	if w < 1 {
+		w = 1
+	}
+	return w
+}
+
+// sameArray returns whether a and b are slices of the same array.  Slices
+// whose capacity was limited with a full slice expression are not detected.
+func sameArray(a, b []complex128) bool {
+	if cap(a) == 0 || cap(b) == 0 {
+		return false
+	}
+	return &a[:cap(a)][cap(a)-1] == &b[:cap(b)][cap(b)-1]
+}
+
+// sameRealArray is like sameArray for real slices.
+func sameRealArray(a, b []float64) bool {
+	if cap(a) == 0 || cap(b) == 0 {
+		return false
+	}
+	return &a[:cap(a)][cap(a)-1] == &b[:cap(b)][cap(b)-1]
+}
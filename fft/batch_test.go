@@ -0,0 +1,239 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package fft
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestBatch(t *testing.T) {
+	for _, N := range []int{64, 45, 37, 67 * 71} {
+		for _, w := range []int{1, 3, 16} {
+			testBatch(N, 8, w, t)
+		}
+	}
+}
+
+func testBatch(N, C, w int, t *testing.T) {
+	b := NewBatch(N, C)
+	b.SetWorkers(w)
+	ft := New(N)
+	d := make([][]complex128, C)
+	exp := make([][]complex128, C)
+	inter := make([]complex128, N*C)
+	for j := range d {
+		d[j] = b.Win(nil)
+		exp[j] = ft.Win(nil)
+		for i := range d[j] {
+			v := complex(rand.Float64(), rand.Float64())
+			d[j][i], exp[j][i] = v, v
+			inter[i*C+j] = v
+		}
+		ft.Do(exp[j])
+	}
+	if e := b.Do(d); e != nil {
+		t.Fatal(e)
+	}
+	res, e := b.DoInter(inter)
+	if e != nil {
+		t.Fatal(e)
+	}
+	for j := range d {
+		for i := range d[j] {
+			if e := cmplxCmpErr(d[j][i], exp[j][i], 1e-10, nil); e != nil {
+				t.Errorf("N=%d w=%d channel %d at %d: %s", N, w, j, i, e)
+			}
+			if e := cmplxCmpErr(res[j][i], exp[j][i], 1e-10, nil); e != nil {
+				t.Errorf("N=%d w=%d inter channel %d at %d: %s", N, w, j, i, e)
+			}
+		}
+	}
+	out := make([]complex128, N*C)
+	if e := b.InvInter(out, res); e != nil {
+		t.Fatal(e)
+	}
+	for i, v := range out {
+		if e := cmplxCmpErr(v, inter[i], 1e-10, nil); e != nil {
+			t.Errorf("N=%d w=%d inv inter at %d: %s", N, w, i, e)
+		}
+	}
+}
+
+func TestRealBatch(t *testing.T) {
+	for _, N := range []int{64, 45, 1009, 37 * 41} {
+		for _, w := range []int{1, 3, 16} {
+			testRealBatch(N, 8, w, t)
+		}
+	}
+}
+
+func testRealBatch(N, C, w int, t *testing.T) {
+	b := NewRealBatch(N, C)
+	b.SetWorkers(w)
+	ft := NewReal(N)
+	d := make([][]float64, C)
+	exp := make([][]float64, C)
+	inter := make([]float64, N*C)
+	for j := range d {
+		d[j] = make([]float64, N)
+		exp[j] = make([]float64, N)
+		for i := range d[j] {
+			v := rand.Float64()
+			d[j][i], exp[j][i] = v, v
+			inter[i*C+j] = v
+		}
+		ft.Do(exp[j])
+	}
+	hcs, e := b.Do(d)
+	if e != nil {
+		t.Fatal(e)
+	}
+	for j := range hcs {
+		for i, v := range hcs[j] {
+			if math.Abs(v-exp[j][i]) > 1e-10 {
+				t.Errorf("N=%d w=%d channel %d at %d: got %f not %f", N, w, j, i, v, exp[j][i])
+			}
+		}
+	}
+	hcs, e = b.DoInter(inter)
+	if e != nil {
+		t.Fatal(e)
+	}
+	for j := range hcs {
+		for i, v := range hcs[j] {
+			if math.Abs(v-exp[j][i]) > 1e-10 {
+				t.Errorf("N=%d w=%d inter channel %d at %d: got %f not %f", N, w, j, i, v, exp[j][i])
+			}
+		}
+	}
+	out := make([]float64, N*C)
+	if e := b.InvInter(out, hcs); e != nil {
+		t.Fatal(e)
+	}
+	for i, v := range out {
+		if math.Abs(v-inter[i]) > 1e-10 {
+			t.Errorf("N=%d w=%d inv inter at %d: got %f not %f", N, w, i, v, inter[i])
+		}
+	}
+	for j := range hcs {
+		hcs[j] = HalfComplex(d[j])
+	}
+	chans, e := b.Inv(hcs)
+	if e != nil {
+		t.Fatal(e)
+	}
+	for j := range chans {
+		for i, v := range chans[j] {
+			if math.Abs(v-inter[i*C+j]) > 1e-10 {
+				t.Errorf("N=%d w=%d inv channel %d at %d: got %f not %f", N, w, j, i, v, inter[i*C+j])
+			}
+		}
+	}
+}
+
+func TestRealBatchErrs(t *testing.T) {
+	b := NewRealBatch(16, 2)
+	if _, e := b.Do(make([][]float64, 3)); e == nil {
+		t.Errorf("expected channel count error")
+	}
+	if _, e := b.Do([][]float64{make([]float64, 16), make([]float64, 15)}); e == nil {
+		t.Errorf("expected length error")
+	}
+	if _, e := b.DoInter(make([]float64, 31)); e == nil {
+		t.Errorf("expected interleaved length error")
+	}
+}
+
+func TestBatchEmpty(t *testing.T) {
+	b := NewBatch(0, 2)
+	d, e := b.DoInter(nil)
+	if e != nil {
+		t.Fatal(e)
+	}
+	if e := b.InvInter(nil, d); e != nil {
+		t.Error(e)
+	}
+	if e := b.InvInter(nil, [][]complex128{nil, make([]complex128, 1)}); e == nil {
+		t.Errorf("expected length error")
+	}
+	rb := NewRealBatch(0, 2)
+	hcs, e := rb.DoInter(nil)
+	if e != nil {
+		t.Fatal(e)
+	}
+	if e := rb.InvInter(nil, hcs); e != nil {
+		t.Error(e)
+	}
+}
+
+func TestBatchInvInterOverlap(t *testing.T) {
+	N, C := 16, 2
+	b := NewBatch(N, C)
+	d, e := b.DoInter(make([]complex128, N*C))
+	if e != nil {
+		t.Fatal(e)
+	}
+	if e := b.InvInter(make([]complex128, N*C), [][]complex128{d[1], d[0]}); e == nil {
+		t.Errorf("expected error for swapped channels")
+	}
+	dst := make([]complex128, N*C)
+	if e := b.InvInter(dst, [][]complex128{dst[:N], make([]complex128, N)}); e == nil {
+		t.Errorf("expected error for src in dst")
+	}
+	if e := b.InvInter(dst, d); e != nil {
+		t.Error(e)
+	}
+
+	rb := NewRealBatch(N, C)
+	hcs, e := rb.DoInter(make([]float64, N*C))
+	if e != nil {
+		t.Fatal(e)
+	}
+	if e := rb.InvInter(make([]float64, N*C), []HalfComplex{hcs[1], hcs[0]}); e == nil {
+		t.Errorf("expected error for swapped real channels")
+	}
+	rdst := make([]float64, N*C)
+	if e := rb.InvInter(rdst, []HalfComplex{HalfComplex(rdst[N:]), make(HalfComplex, N)}); e == nil {
+		t.Errorf("expected error for real src in dst")
+	}
+	if e := rb.InvInter(rdst, hcs); e != nil {
+		t.Error(e)
+	}
+}
+
+func TestRealBatchAllocs(t *testing.T) {
+	b := NewRealBatch(1024, 8)
+	d := make([]float64, 1024*8)
+	allocs := testing.AllocsPerRun(10, func() {
+		hcs, _ := b.DoInter(d)
+		b.InvInter(d, hcs)
+	})
+	if allocs != 0 {
+		t.Errorf("got %f allocations", allocs)
+	}
+}
+
+func benchmarkRealBatch(w int, b *testing.B) {
+	N, C := 4096, 16
+	rb := NewRealBatch(N, C)
+	rb.SetWorkers(w)
+	d := make([]float64, N*C)
+	for i := range d {
+		d[i] = rand.Float64()
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		rb.DoInter(d)
+	}
+}
+
+func BenchmarkRealBatch1(b *testing.B) {
+	benchmarkRealBatch(1, b)
+}
+
+func BenchmarkRealBatch4(b *testing.B) {
+	benchmarkRealBatch(4, b)
+}
@@ -0,0 +1,70 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package fft
+
+import "github.com/zikichombo/dsp/internal/tabcache"
+
+// The tables of twiddle factors, chirps and kernels computed for transforms
+// are immutable once computed, so transforms of the same size share them
+// via a cache.  The cache is bounded by the number of bytes of tables it
+// holds, evicting the least recently used tables first.  Evicted tables
+// remain valid for transforms holding them.  The cache also holds the tables
+// of packages built on fft, such as czt, and the bound applies to all of
+// them.
+
+// DefaultCacheLimit is the default bound on the number of bytes of tables
+// held in the cache.
+const DefaultCacheLimit = tabcache.DefaultLimit
+
+// kinds of cached tables
+const (
+	ckTrig      = iota // cos and sin of 2*pi*i/n, []float64
+	ckTrig32           // []float32
+	ckChirpz           // *chirpz
+	ckChirpz32         // *chirpz32
+	ckRealTw           // twiddles of Real, []complex128
+	ckRealTw32         // []complex64
+	ckRader            // transformed rader kernels, []complex128
+	ckRader32          // []complex64
+	ckRealRader        // tables of realRader, *realRaderTabs
+)
+
+type cacheKey struct {
+	kind, n, padN int
+	inv           bool
+}
+
+// CacheStats gives statistics for the cache of tables shared by
+// transforms.
+type CacheStats struct {
+	Hits    uint64 // number of tables found in the cache
+	Misses  uint64 // number of tables computed
+	Entries int    // number of tables in the cache
+	Bytes   int    // size of tables in the cache
+	Limit   int    // bound on Bytes
+}
+
+// ReadCacheStats returns the current statistics of the table cache.
+func ReadCacheStats() CacheStats {
+	return CacheStats(tabcache.ReadStats())
+}
+
+// SetCacheLimit sets the bound on the number of bytes of tables held in
+// the cache to b, evicting tables as needed, and returns the previous
+// bound.  If b <= 0, then tables are no longer cached.
+func SetCacheLimit(b int) int {
+	return tabcache.SetLimit(b)
+}
+
+// PurgeCache removes all tables from the cache and resets the hit and miss
+// counts.
+func PurgeCache() {
+	tabcache.Purge()
+}
+
+// cached returns the table for k, calling f to compute it and its size in
+// bytes if it is not in the cache.
+func cached(k cacheKey, f func() (interface{}, int)) interface{} {
+	return tabcache.Get(k, f)
+}
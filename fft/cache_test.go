@@ -0,0 +1,72 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package fft
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestCacheShare(t *testing.T) {
+	PurgeCache()
+	a, b := New(1024), New(1024)
+	a.Do(a.Win(nil))
+	b.Inv(b.Win(nil))
+	if &a.twids.cosTbl[0] != &b.itwids.cosTbl[0] {
+		t.Errorf("twiddles not shared")
+	}
+	st := ReadCacheStats()
+	if st.Misses != 1 || st.Hits != 1 || st.Entries != 1 || st.Bytes != 16*1024 {
+		t.Errorf("unexpected stats %+v", st)
+	}
+	c, d := New(37*41), New(37*41)
+	if &c.getChirpz(true).D[0] != &d.getChirpz(true).D[0] {
+		t.Errorf("chirps not shared")
+	}
+	r, s := NewReal(2048), NewReal(2048)
+	if &r.twidz[0] != &s.twidz[0] {
+		t.Errorf("real twiddles not shared")
+	}
+}
+
+func TestCacheLimit(t *testing.T) {
+	PurgeCache()
+	defer SetCacheLimit(SetCacheLimit(40 << 10))
+	for _, n := range []int{1024, 2048, 1024} {
+		New(n).Do(make([]complex128, n))
+	}
+	st := ReadCacheStats()
+	if st.Entries != 1 || st.Bytes != 16*1024 || st.Hits != 0 || st.Misses != 3 {
+		t.Errorf("unexpected stats %+v", st)
+	}
+	SetCacheLimit(0)
+	st = ReadCacheStats()
+	if st.Entries != 0 || st.Bytes != 0 {
+		t.Errorf("unexpected stats %+v", st)
+	}
+	testNaive(1024, t)
+}
+
+func TestCacheConcurrent(t *testing.T) {
+	PurgeCache()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for _, n := range []int{64, 100, 97, 67} {
+				ft := New(n)
+				d := ft.Win(nil)
+				d[i%n] = 1
+				ft.Do(d)
+				ft.Inv(d)
+				NewReal(2 * n).Do(make([]float64, 2*n))
+			}
+		}(i)
+	}
+	wg.Wait()
+	if st := ReadCacheStats(); st.Hits == 0 {
+		t.Errorf("no hits: %+v", st)
+	}
+}
@@ -36,10 +36,18 @@ func newChirpz(n, padN int, twids *twiddles) *chirpz {
 		res.D[i] = 0.0
 	}
 	copy(res.tD, res.D)
-	r2(res.tD, twids, true)
+	r4(res.tD, twids, true)
 	return res
 }
 
+// sharedChirpz returns the chirps for size n padded to padN in the
+// direction inv, shared via the cache.
+func sharedChirpz(n, padN int, inv bool) *chirpz {
+	return cached(cacheKey{kind: ckChirpz, n: n, padN: padN, inv: inv}, func() (interface{}, int) {
+		return newChirpz(n, padN, sharedTwiddles(padN, inv)), 32 * padN
+	}).(*chirpz)
+}
+
 func (c *chirpz) inv(i int) complex128 {
 	return cmplx.Conj(c.D[i])
 }
@@ -21,8 +21,14 @@
 //
 // Package fft provides an efficient real-only interface for even transform
 // sizes with spectra represented in a half complex format like fftw.  An odd
-// transform size real-only interface is also supported, but is not as efficient
-// as the even transform size real-only interface.
+// transform size real-only interface is also supported, and costs about half
+// of a complex transform of the same size when the size has a small prime
+// factor.
+//
+// Package fft provides float32 counterparts T32, Real32 and HalfComplex32 of
+// T, Real and HalfComplex for data which is natively single precision.  These
+// compute in single precision with the same interface and allocation
+// guarantees; see T32 for precision bounds.
 //
 // The interface guarantees the Parseval equation, which states the sum of
 // squares of the amplitudes in the time domain equals the sum of squares of
@@ -32,17 +38,34 @@
 //
 // Non Features
 //
+// Package fft provides Batch and RealBatch for transforming many channels of
+// the same size at once, optionally from channel interleaved data and
+// optionally spread over goroutines which share twiddle factors.
+//
+// Package fft provides Large for very large transforms, which splits the
+// work of a single transform across goroutines using Bailey's four step
+// algorithm.
+//
 // Package fft is designed exclusively for 1d data.  Support for matrices is a
 // non-goal of this package.
 //
 // Algorithms
 //
-// The implementation uses in place decimation in time binary radix 2 Cooley
-// Tuckey for sizes of powers of 2, and Bluestein algorithm otherwise.  Twiddle
-// factors are pre-computed, and attention is paid to minimize allocations and
-// copying, both internally and in the interface.  Package fft provides O(N Log
-// N) transforms for all inputs and allows for in place transforms.  The Real
-// only interface uses the complex interface for half-sized inputs together
-// with some O(N) pre/post processing.
+// The implementation uses in place decimation in time radix 4 Cooley Tuckey
+// (with a radix 2 pass for odd powers) for sizes of powers of 2, mixed radix
+// (Stockham autosort) Cooley Tuckey with radix 2, 3, 4, 5 and generic odd
+// butterflies for sizes whose prime factors are small, Rader's algorithm for
+// larger primes p such that p-1 has only small prime factors, and Bluestein
+// algorithm otherwise.  Twiddle factors are pre-computed and shared between
+// transforms of the same size through a bounded cache (see SetCacheLimit,
+// PurgeCache and ReadCacheStats), and attention is paid to minimize
+// allocations and copying, both internally and in the interface.  Package fft provides O(N Log N) transforms for all
+// inputs and allows for in place transforms.  The Real only interface uses
+// the complex interface for half-sized inputs together with some O(N)
+// pre/post processing for even sizes.  For odd sizes, it decimates in time
+// by the small prime factors with real butterflies which form only half of
+// each spectrum, and uses Rader's algorithm with convolutions of half the
+// size for larger primes.  Real transforms of odd size take about half the
+// time of complex ones, or about 60% for large primes.
 //
 package fft
@@ -6,19 +6,25 @@ package fft
 import (
 	"math"
 	"math/big"
+	"sync"
 )
 
-// nb this is unused, but can be useful if/when we add factor based
-// general radix cooley tuckey implementations
+// primes used by factor, which is used to find the radices of mixed radix
+// cooley tuckey implementations.
 var primes = []int{
-	2, 3, 5, 7, 11, 13, 17, 23, 29, 31, 37, 41, 43, 47, 51, 53, 57, 59,
+	2, 3, 5, 7, 11, 13, 17, 19, 23, 29, 31, 37, 41, 43, 47, 53, 59,
 	61, 67, 71, 73, 79}
 
+// primesMu protects primes, which factor extends.
+var primesMu sync.Mutex
+
 func init() {
 	factor(101483)
 }
 
 func factor(n int) (int, int, int) {
+	primesMu.Lock()
+	defer primesMu.Unlock()
 	t := 1
 	for n%2 == 0 {
 		n /= 2
@@ -59,6 +65,17 @@ func factor(n int) (int, int, int) {
 	return 1, n, t
 }
 
+func isPrime(n int) bool {
+	if n < 2 {
+		return false
+	}
+	if n == 2 {
+		return true
+	}
+	a, b, t := factor(n)
+	return a == 1 && b == n && t == 1
+}
+
 func log2(i int) uint {
 	r := uint(0)
 	for i > 1<<r {
@@ -150,8 +150,6 @@ func radix2(src []float64, dst []complex128, N, stride int, inv float64) {
 }
 
 // P is prime.
-
-// TBD(wsc) Rader?
 func slowft(src []float64, dst []complex128, P, stride int, inv float64) {
 	if P == 1 {
 		dst[0] = complex(src[0], 0)
@@ -192,7 +192,7 @@ func (h HalfComplex) FromPolar(mag, ph []float64) {
 		M++
 	}
 	for i := 1; i < M; i++ {
-		c = cmplx.Rect(mag[0], ph[0])
+		c = cmplx.Rect(mag[i], ph[i])
 		h[i] = real(c)
 		h[N-i] = imag(c)
 	}
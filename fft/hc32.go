@@ -0,0 +1,186 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package fft
+
+import "math/cmplx"
+
+// HalfComplex32 is like HalfComplex, but for float32 data.
+type HalfComplex32 []float32
+
+// Cmplx returns the complex64 representation of element i.
+func (h HalfComplex32) Cmplx(i int) complex64 {
+	N := len(h)
+	if i == 0 || 2*i == N {
+		return complex(h[i], 0)
+	}
+	return complex(h[i], h[N-i])
+}
+
+// SetCmplx sets the complex number i to c in h.
+func (h HalfComplex32) SetCmplx(i int, c complex64) {
+	N := len(h)
+	h[i] = real(c)
+	if i == 0 || 2*i == N {
+		return
+	}
+	h[N-i] = imag(c)
+}
+
+// Real returns the real part of the complex number at i.
+func (h HalfComplex32) Real(i int) float32 {
+	return h[i]
+}
+
+// SetReal sets the real part of the complex number at i.
+func (h HalfComplex32) SetReal(i int, v float32) {
+	h[i] = v
+}
+
+// Imag returns the imaginary part of the complex number at i.
+func (h HalfComplex32) Imag(i int) float32 {
+	N := len(h)
+	if i == 0 || 2*i == N {
+		return 0
+	}
+	return h[N-i]
+}
+
+// SetImag is like HalfComplex.SetImag.
+func (h HalfComplex32) SetImag(i int, v float32) {
+	N := len(h)
+	if i == 0 || 2*i == N {
+		return
+	}
+	h[N-i] = v
+}
+
+// Len returns the number of complex numbers in h, which does not
+// include elements with symmetric pairs.
+func (h HalfComplex32) Len() int {
+	return len(h)/2 + 1
+}
+
+// ToCmplx fills d with complex numbers stored in h.
+//
+// if len(h) != len(d), then ToCmplx panics.
+func (h HalfComplex32) ToCmplx(d []complex64) {
+	if len(h) != len(d) {
+		panic("size mismatch")
+	}
+	if len(h) == 0 {
+		return
+	}
+	d[0] = complex(h[0], 0.0)
+	N := len(d)
+	M := N / 2
+	if M+M != N {
+		M++
+	}
+	for i := 1; i < M; i++ {
+		d[i] = complex(h[i], h[N-i])
+		d[N-i] = complex(h[i], -h[N-i])
+	}
+	if M+M == N {
+		d[M] = complex(h[M], 0.0)
+	}
+}
+
+// ToPolar fills mag, phase with the magnitude and phase
+// of complex numbers stored in h
+func (h HalfComplex32) ToPolar(mag, ph []float32) {
+	if len(h) != len(mag) || len(mag) != len(ph) {
+		panic("size mismatch")
+	}
+	if len(h) == 0 {
+		return
+	}
+	N := len(h)
+	M := N / 2
+	if M+M != N {
+		M++
+	}
+	var m, p float64
+	m, p = cmplx.Polar(complex(float64(h[0]), 0))
+	mag[0], ph[0] = float32(m), float32(p)
+	for i := 1; i < M; i++ {
+		m, p = cmplx.Polar(complex(float64(h[i]), float64(h[N-i])))
+		mag[i], ph[i] = float32(m), float32(p)
+		mag[N-i], ph[N-i] = float32(m), -float32(p)
+	}
+	if M+M == N {
+		m, p = cmplx.Polar(complex(float64(h[M]), 0))
+		mag[M], ph[M] = float32(m), float32(p)
+	}
+}
+
+// FromCmplx places a complex spectrum of a real sequence in h.
+//
+// FromCmplx panics if len(h) != len(d).
+//
+// FromCmplx does not check that d is in the symmetric form
+// of a DFT of real data.
+func (h HalfComplex32) FromCmplx(d []complex64) {
+	if len(h) != len(d) {
+		panic("size mismatch")
+	}
+	if len(h) == 0 {
+		return
+	}
+	h[0] = real(d[0])
+	N := len(d)
+	M := N / 2
+	if M+M != N {
+		M++
+	}
+	for i := 1; i < M; i++ {
+		h[i] = real(d[i])
+		h[N-i] = imag(d[i])
+	}
+	if M+M == N {
+		h[M] = real(d[M])
+	}
+}
+
+// FromPolar places a complex spectrum of a real sequence given in polar
+// form in h.
+//
+// FromPolar panics if len(h) != len(mag) or len(mag) != len(ph).
+func (h HalfComplex32) FromPolar(mag, ph []float32) {
+	if len(h) != len(mag) || len(mag) != len(ph) {
+		panic("size mismatch")
+	}
+	if len(h) == 0 {
+		return
+	}
+	N := len(h)
+	M := N / 2
+	if M+M != N {
+		M++
+	}
+	var c complex128
+	c = cmplx.Rect(float64(mag[0]), float64(ph[0]))
+	h[0] = float32(real(c))
+	for i := 1; i < M; i++ {
+		c = cmplx.Rect(float64(mag[i]), float64(ph[i]))
+		h[i] = float32(real(c))
+		h[N-i] = float32(imag(c))
+	}
+	if M+M == N {
+		c = cmplx.Rect(float64(mag[M]), float64(ph[M]))
+		h[M] = float32(real(c))
+	}
+}
+
+// MulElems computes the elementwise multiplication of a and b, placing
+// the result in a and returning it. MulElems panics if a.Len() != b.Len().
+func (a HalfComplex32) MulElems(b HalfComplex32) HalfComplex32 {
+	if len(a) != len(b) {
+		panic("size mismatch")
+	}
+	N := a.Len()
+	for i := 0; i < N; i++ {
+		a.SetCmplx(i, a.Cmplx(i)*b.Cmplx(i))
+	}
+	return a
+}
@@ -4,6 +4,7 @@
 package fft
 
 import (
+	"math"
 	"math/cmplx"
 	"math/rand"
 	"testing"
@@ -47,3 +48,27 @@ func TestHCToFromCmplx(t *testing.T) {
 		}
 	}
 }
+
+func TestHCToFromPolar(t *testing.T) {
+	for _, N := range []int{64, 65} {
+		d := make([]float64, N)
+		e := make([]float64, N)
+		mag := make([]float64, N)
+		ph := make([]float64, N)
+		for i := range d {
+			d[i] = rand.Float64()
+			e[i] = d[i]
+		}
+		hc := HalfComplex(d)
+		hc.ToPolar(mag, ph)
+		for i := range hc {
+			hc[i] = 0.0
+		}
+		hc.FromPolar(mag, ph)
+		for i, v := range hc {
+			if math.Abs(v-e[i]) > 1e-12 {
+				t.Errorf("N=%d i=%d after to/from polar got %f not %f\n", N, i, v, e[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,285 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package fft
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+	"sync"
+)
+
+// LargeCutoff is the size below which Large uses the serial transform of T.
+const LargeCutoff = 1 << 16
+
+// transpose block size, 32*32 complex128 fit comfortably in L1.
+const tBlock = 32
+
+// Large performs transforms of large sizes, splitting the work across
+// goroutines with the four step (or six step, counting transposes)
+// algorithm of Bailey.
+//
+// For n = n1*n2, the data is viewed as an n1 by n2 matrix.  It is
+// transposed, n2 transforms of size n1 are done on its rows and multiplied
+// by twiddle factors, it is transposed back, n1 transforms of size n2 are
+// done on its rows and it is transposed once more to give the result in
+// natural order.  The row transforms and the blocked transposes are
+// distributed over the workers, each of which has its own T for each row
+// size sharing tables with the others.
+//
+// If n is less than LargeCutoff, or if n has no factorization n1*n2 with
+// n1 and n2 both at least 32 and computable without padding (powers of 2
+// and sizes whose prime factors are small), then Large uses T serially.
+type Large struct {
+	n, n1, n2 int
+	serial    *T
+	r1, r2    []*T // per worker, sizes n1, n2
+	tw        *twiddles
+	tmp       []complex128
+	scale     bool
+}
+
+// NewLarge creates a new Large for transforms of size n, using
+// runtime.GOMAXPROCS(0) workers.
+func NewLarge(n int) *Large {
+	return newLarge(n, LargeCutoff)
+}
+
+func newLarge(n, cutoff int) *Large {
+	res := &Large{n: n, scale: true}
+	if n >= cutoff {
+		res.n1, res.n2 = largeSplit(n)
+	}
+	if res.n1 == 0 {
+		res.serial = New(n)
+		return res
+	}
+	res.r1 = []*T{New(res.n1)}
+	res.r2 = []*T{New(res.n2)}
+	res.r1[0].Scale(false)
+	res.r2[0].Scale(false)
+	res.tmp = make([]complex128, n)
+	res.SetWorkers(runtime.GOMAXPROCS(0))
+	return res
+}
+
+// largeSplit returns n1, n2 with n1*n2 = n, n1 <= n2 and n1 as close as
+// possible to sqrt(n) such that both n1 and n2 are transformed without
+// padding.  If there are no such n1, n2 with n1 >= tBlock, then largeSplit
+// returns 0, 0.
+func largeSplit(n int) (int, int) {
+	n1 := 1
+	for n1*n1 <= n {
+		n1++
+	}
+	for n1--; n1 >= tBlock; n1-- {
+		if n%n1 != 0 {
+			continue
+		}
+		n2 := n / n1
+		if noPad(n1) && noPad(n2) {
+			return n1, n2
+		}
+	}
+	return 0, 0
+}
+
+func noPad(n int) bool {
+	return is2pow(n) || maxFactor(n) <= maxRadix
+}
+
+// N returns the size of the transforms.
+func (l *Large) N() int {
+	return l.n
+}
+
+// Cap returns the desired capacity of slices passed to Do and Inv.
+func (l *Large) Cap() int {
+	if l.serial != nil {
+		return l.serial.Cap()
+	}
+	return l.n
+}
+
+// Win is like T.Win.
+func (l *Large) Win(c []complex128) []complex128 {
+	if l.serial != nil {
+		return l.serial.Win(c)
+	}
+	if cap(c) < l.n {
+		tmp := make([]complex128, len(c), l.n)
+		copy(tmp, c)
+		c = tmp
+	}
+	if len(c) < l.n {
+		c = c[:l.n]
+		for i := len(c); i < l.n; i++ {
+			c[i] = 0i
+		}
+	}
+	return c[:l.n]
+}
+
+// Scale is like T.Scale.
+func (l *Large) Scale(v bool) {
+	l.scale = v
+	if l.serial != nil {
+		l.serial.Scale(v)
+	}
+}
+
+// Workers returns the number of goroutines used by l.
+func (l *Large) Workers() int {
+	if l.serial != nil {
+		return 1
+	}
+	return len(l.r1)
+}
+
+// SetWorkers sets the number of goroutines used by l to w, or 1 if w < 1.
+// SetWorkers has no effect if l uses T serially.
+func (l *Large) SetWorkers(w int) {
+	if l.serial != nil {
+		return
+	}
+	if w < 1 {
+		w = 1
+	}
+	for len(l.r1) < w {
+		l.r1 = append(l.r1, l.r1[0].clone())
+		l.r2 = append(l.r2, l.r2[0].clone())
+	}
+	l.r1, l.r2 = l.r1[:w], l.r2[:w]
+}
+
+// Do performs an in-place transform on d.
+func (l *Large) Do(d []complex128) error {
+	return l.do(d, false)
+}
+
+// Inv performs an in-place inverse transform on d.
+func (l *Large) Inv(d []complex128) error {
+	return l.do(d, true)
+}
+
+func (l *Large) do(d []complex128, inv bool) error {
+	if l.serial != nil {
+		if inv {
+			return l.serial.Inv(d)
+		}
+		return l.serial.Do(d)
+	}
+	if len(d) != l.n {
+		return fmt.Errorf("wrong length %d != %d", len(d), l.n)
+	}
+	if l.tw == nil {
+		l.tw = sharedTwiddles(l.n, false)
+	}
+	n1, n2 := l.n1, l.n2
+	tmp := l.tmp
+	// d is n1 x n2, tmp n2 x n1.
+	l.par(n1, func(w, lo, hi int) {
+		transpose(tmp, d, n1, n2, lo, hi)
+	})
+	l.par(n2, func(w, lo, hi int) {
+		ft := l.r1[w]
+		for j2 := lo; j2 < hi; j2++ {
+			row := tmp[j2*n1 : (j2+1)*n1 : (j2+1)*n1]
+			if inv {
+				ft.Inv(row)
+			} else {
+				ft.Do(row)
+			}
+			l.twiddle(row, j2, inv)
+		}
+	})
+	l.par(n2, func(w, lo, hi int) {
+		transpose(d, tmp, n2, n1, lo, hi)
+	})
+	l.par(n1, func(w, lo, hi int) {
+		ft := l.r2[w]
+		for k1 := lo; k1 < hi; k1++ {
+			row := d[k1*n2 : (k1+1)*n2 : (k1+1)*n2]
+			if inv {
+				ft.Inv(row)
+			} else {
+				ft.Do(row)
+			}
+		}
+	})
+	l.par(n1, func(w, lo, hi int) {
+		transpose(tmp, d, n1, n2, lo, hi)
+	})
+	l.par(n2, func(w, lo, hi int) {
+		copy(d[lo*n1:hi*n1], tmp[lo*n1:hi*n1])
+		if l.scale {
+			scaleBy(d[lo*n1:hi*n1], l.n)
+		}
+	})
+	return nil
+}
+
+// twiddle multiplies row[k1] by W_n^(j2*k1).
+func (l *Large) twiddle(row []complex128, j2 int, inv bool) {
+	cs, sn := l.tw.cosTbl, l.tw.sinTbl
+	sg := -1.0
+	if inv {
+		sg = 1.0
+	}
+	i := 0
+	for k1 := range row {
+		row[k1] *= complex(cs[i], sg*sn[i])
+		i += j2
+	}
+}
+
+// par calls f over [0, n) split into contiguous ranges, one
+// per worker, in parallel.
+func (l *Large) par(n int, f func(w, lo, hi int)) {
+	W := len(l.r1)
+	if W == 1 {
+		f(0, 0, n)
+		return
+	}
+	var wg sync.WaitGroup
+	wg.Add(W)
+	for w := 0; w < W; w++ {
+		go func(w int) {
+			f(w, w*n/W, (w+1)*n/W)
+			wg.Done()
+		}(w)
+	}
+	wg.Wait()
+}
+
+// transpose places rows [lo, hi) of the rows x cols matrix src in the
+// corresponding columns of the cols x rows matrix dst, by blocks.
+func transpose(dst, src []complex128, rows, cols, lo, hi int) {
+	for r0 := lo; r0 < hi; r0 += tBlock {
+		r1 := r0 + tBlock
+		if r1 > hi {
+			r1 = hi
+		}
+		for c0 := 0; c0 < cols; c0 += tBlock {
+			c1 := c0 + tBlock
+			if c1 > cols {
+				c1 = cols
+			}
+			for r := r0; r < r1; r++ {
+				s := src[r*cols : (r+1)*cols]
+				for c := c0; c < c1; c++ {
+					dst[c*rows+r] = s[c]
+				}
+			}
+		}
+	}
+}
+
+// scaleBy scales d by 1/sqrt(n).
+func scaleBy(d []complex128, n int) {
+	a := complex(1/math.Sqrt(float64(n)), 0)
+	for i := range d {
+		d[i] *= a
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package fft
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestLargeSplit(t *testing.T) {
+	for _, c := range [][3]int{
+		{1 << 20, 1 << 10, 1 << 10},
+		{1 << 21, 1 << 10, 1 << 11},
+		{3 * 5 * 7 * 1024, 320, 336},
+		{65537, 0, 0},
+		{2 * 65537, 0, 0}} {
+		n1, n2 := largeSplit(c[0])
+		if n1 != c[1] || n2 != c[2] {
+			t.Errorf("%d: got %d x %d not %d x %d", c[0], n1, n2, c[1], c[2])
+		}
+	}
+}
+
+func TestLarge(t *testing.T) {
+	for _, N := range []int{1 << 12, 1 << 13, 45 * 64, 37 * 64, 101} {
+		for _, w := range []int{1, 3} {
+			l := newLarge(N, 0)
+			l.SetWorkers(w)
+			testLarge(l, t)
+		}
+	}
+	testLarge(NewLarge(1<<17), t)
+}
+
+func testLarge(l *Large, t *testing.T) {
+	N := l.N()
+	ft := New(N)
+	d := l.Win(nil)
+	e := ft.Win(nil)
+	orig := make([]complex128, N)
+	for i := range d {
+		v := complex(rand.Float64(), rand.Float64())
+		d[i], e[i], orig[i] = v, v, v
+	}
+	if err := l.Do(d); err != nil {
+		t.Fatal(err)
+	}
+	ft.Do(e)
+	for i := range d {
+		if err := cmplxCmpErr(d[i], e[i], 1e-9, nil); err != nil {
+			t.Errorf("N=%d w=%d at %d: %s", N, l.Workers(), i, err)
+			return
+		}
+	}
+	if err := l.Inv(d); err != nil {
+		t.Fatal(err)
+	}
+	for i := range d {
+		if err := cmplxCmpErr(d[i], orig[i], 1e-9, nil); err != nil {
+			t.Errorf("N=%d w=%d inv at %d: %s", N, l.Workers(), i, err)
+			return
+		}
+	}
+}
+
+func TestTranspose(t *testing.T) {
+	rows, cols := 70, 45
+	src := make([]complex128, rows*cols)
+	dst := make([]complex128, rows*cols)
+	for i := range src {
+		src[i] = complex(float64(i), 0)
+	}
+	transpose(dst, src, rows, cols, 0, 33)
+	transpose(dst, src, rows, cols, 33, rows)
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; c++ {
+			if dst[c*rows+r] != src[r*cols+c] {
+				t.Errorf("at %d, %d got %f not %f", r, c, dst[c*rows+r], src[r*cols+c])
+			}
+		}
+	}
+}
+
+func benchmarkLarge(N, w int, b *testing.B) {
+	b.StopTimer()
+	l := NewLarge(N)
+	l.SetWorkers(w)
+	d := l.Win(nil)
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		l.Do(d)
+	}
+}
+
+func BenchmarkLarge1M(b *testing.B) {
+	benchmarkLarge(1<<20, 1, b)
+}
+
+func BenchmarkLarge1MPar(b *testing.B) {
+	benchmarkLarge(1<<20, 4, b)
+}
+
+func BenchmarkT1M(b *testing.B) {
+	b.StopTimer()
+	ft := New(1 << 20)
+	d := ft.Win(nil)
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		ft.Do(d)
+	}
+}
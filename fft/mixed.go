@@ -0,0 +1,265 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package fft
+
+import "math"
+
+// maxRadix is the largest prime factor for which New chooses mixed radix
+// Cooley Tuckey over Bluestein.  Primes above this use the generic odd
+// butterfly, which is quadratic in the radix.
+const maxRadix = 31
+
+// mixed holds the state for a mixed radix Cooley Tuckey transform of a size
+// n which is not a power of 2.
+//
+// The implementation is a Stockham autosort formulation of decimation in
+// frequency: each pass reads from one buffer and writes to another, so no
+// bit (or digit) reversal permutation is needed.  Passes alternate between
+// the data and tmp.
+type mixed struct {
+	radices []int
+	tmp     []complex128 // size n
+	gen     []complex128 // scratch for generic radices
+	trig    []float64    // scratch for generic radices
+}
+
+func newMixed(n int) *mixed {
+	res := &mixed{
+		radices: radices(n),
+		tmp:     make([]complex128, n)}
+	g := 0
+	for _, p := range res.radices {
+		if p > 5 && p > g {
+			g = p
+		}
+	}
+	res.gen = make([]complex128, g+1)
+	res.trig = make([]float64, 2*g)
+	return res
+}
+
+func (m *mixed) clone() *mixed {
+	return &mixed{
+		radices: m.radices,
+		tmp:     make([]complex128, len(m.tmp)),
+		gen:     make([]complex128, len(m.gen)),
+		trig:    make([]float64, len(m.trig))}
+}
+
+// radices returns the sequence of radices used for a mixed radix transform
+// of size n, using as many 4's as possible, then 2, 3, 5 and any other
+// (prime) factors in increasing order.
+func radices(n int) []int {
+	res := make([]int, 0, 16)
+	a, b, t := factor(n)
+	for t >= 4 {
+		res = append(res, 4)
+		t /= 4
+	}
+	if t == 2 {
+		res = append(res, 2)
+	}
+	for a != 1 {
+		res = append(res, a)
+		a, b, _ = factor(b)
+	}
+	if b != 1 {
+		res = append(res, b)
+	}
+	return res
+}
+
+// maxFactor returns the largest prime factor of n.
+func maxFactor(n int) int {
+	res := 1
+	for _, p := range radices(n) {
+		if p > res {
+			res = p
+		}
+	}
+	if res == 4 {
+		res = 2
+	}
+	return res
+}
+
+// do performs an unscaled transform in place in d, with the
+// direction determined by tw.
+func (m *mixed) do(d []complex128, tw *twiddles) {
+	N := len(d)
+	x, y := d, m.tmp[:N]
+	n, s := N, 1
+	for _, p := range m.radices {
+		l := n / p
+		switch p {
+		case 2:
+			pass2(x, y, l, s, tw)
+		case 3:
+			pass3(x, y, l, s, tw)
+		case 4:
+			pass4(x, y, l, s, tw)
+		case 5:
+			pass5(x, y, l, s, tw)
+		default:
+			h := (p + 1) / 2
+			passGen(x, y, l, s, p, tw, m.gen[:h], m.gen[h:2*h], m.trig[:p], m.trig[p:2*p])
+		}
+		n = l
+		s *= p
+		x, y = y, x
+	}
+	if &x[0] != &d[0] {
+		copy(d, x)
+	}
+}
+
+// Each pass below computes, for the current sub-transform size n = p*l and
+// stride s (n*s = len(x)), with w = W_n^q
+//
+//  y[k + s*(p*q + j)] = w^j * sum_{i=0}^{p-1} x[k + s*(q + l*i)] * W_p^{i*j}
+//
+// for q in [0..l), k in [0..s), j in [0..p).  W_n^q is W_N^{q*s}, so all
+// the twiddles are in the table of size N.
+
+func pass2(x, y []complex128, l, s int, tw *twiddles) {
+	var a, b, w complex128
+	for q := 0; q < l; q++ {
+		w = tw.cmplx(q * s)
+		i0, i1 := s*q, s*(q+l)
+		o0, o1 := s*2*q, s*(2*q+1)
+		for k := 0; k < s; k++ {
+			a, b = x[i0+k], x[i1+k]
+			y[o0+k] = a + b
+			y[o1+k] = (a - b) * w
+		}
+	}
+}
+
+func pass3(x, y []complex128, l, s int, tw *twiddles) {
+	// sin(2pi/3), signed for direction
+	k3 := tw.invSin * math.Sqrt(3) / 2
+	var a0, a1, a2, t1, t2, t3, w1, w2 complex128
+	for q := 0; q < l; q++ {
+		w1 = tw.cmplx(q * s)
+		w2 = tw.cmplx(2 * q * s)
+		i0, i1, i2 := s*q, s*(q+l), s*(q+2*l)
+		o0 := s * 3 * q
+		o1, o2 := o0+s, o0+2*s
+		for k := 0; k < s; k++ {
+			a0, a1, a2 = x[i0+k], x[i1+k], x[i2+k]
+			t1 = a1 + a2
+			t2 = a0 - 0.5*t1
+			t3 = a1 - a2
+			t3 = complex(-k3*imag(t3), k3*real(t3))
+			y[o0+k] = a0 + t1
+			y[o1+k] = (t2 + t3) * w1
+			y[o2+k] = (t2 - t3) * w2
+		}
+	}
+}
+
+func pass4(x, y []complex128, l, s int, tw *twiddles) {
+	sg := tw.invSin
+	var a0, a1, a2, a3, t0, t1, t2, t3, w1, w2, w3 complex128
+	for q := 0; q < l; q++ {
+		w1 = tw.cmplx(q * s)
+		w2 = tw.cmplx(2 * q * s)
+		w3 = tw.cmplx(3 * q * s)
+		i0, i1, i2, i3 := s*q, s*(q+l), s*(q+2*l), s*(q+3*l)
+		o0 := s * 4 * q
+		o1, o2, o3 := o0+s, o0+2*s, o0+3*s
+		for k := 0; k < s; k++ {
+			a0, a1, a2, a3 = x[i0+k], x[i1+k], x[i2+k], x[i3+k]
+			t0 = a0 + a2
+			t1 = a0 - a2
+			t2 = a1 + a3
+			t3 = a1 - a3
+			t3 = complex(-sg*imag(t3), sg*real(t3))
+			y[o0+k] = t0 + t2
+			y[o1+k] = (t1 + t3) * w1
+			y[o2+k] = (t0 - t2) * w2
+			y[o3+k] = (t1 - t3) * w3
+		}
+	}
+}
+
+func pass5(x, y []complex128, l, s int, tw *twiddles) {
+	c1, c2 := math.Cos(2*math.Pi/5), math.Cos(4*math.Pi/5)
+	s1, s2 := tw.invSin*math.Sin(2*math.Pi/5), tw.invSin*math.Sin(4*math.Pi/5)
+	var a0, a1, a2, a3, a4, b1, b2, d1, d2, t1, t2, u1, u2 complex128
+	var w1, w2, w3, w4 complex128
+	for q := 0; q < l; q++ {
+		w1 = tw.cmplx(q * s)
+		w2 = tw.cmplx(2 * q * s)
+		w3 = tw.cmplx(3 * q * s)
+		w4 = tw.cmplx(4 * q * s)
+		i0, i1, i2, i3, i4 := s*q, s*(q+l), s*(q+2*l), s*(q+3*l), s*(q+4*l)
+		o0 := s * 5 * q
+		o1, o2, o3, o4 := o0+s, o0+2*s, o0+3*s, o0+4*s
+		for k := 0; k < s; k++ {
+			a0, a1, a2, a3, a4 = x[i0+k], x[i1+k], x[i2+k], x[i3+k], x[i4+k]
+			b1, b2 = a1+a4, a2+a3
+			d1, d2 = a1-a4, a2-a3
+			t1 = a0 + complex(c1, 0)*b1 + complex(c2, 0)*b2
+			t2 = a0 + complex(c2, 0)*b1 + complex(c1, 0)*b2
+			u1 = complex(s1, 0)*d1 + complex(s2, 0)*d2
+			u2 = complex(s2, 0)*d1 - complex(s1, 0)*d2
+			// multiply by i
+			u1 = complex(-imag(u1), real(u1))
+			u2 = complex(-imag(u2), real(u2))
+			y[o0+k] = a0 + b1 + b2
+			y[o1+k] = (t1 + u1) * w1
+			y[o2+k] = (t2 + u2) * w2
+			y[o3+k] = (t2 - u2) * w3
+			y[o4+k] = (t1 - u1) * w4
+		}
+	}
+}
+
+// passGen is a pass for an arbitrary odd radix p, using a direct DFT of
+// size p for the butterfly which pairs indices i and p-i.  a and b are
+// scratch of length (p+1)/2, cs and sn of length p.
+func passGen(x, y []complex128, l, s, p int, tw *twiddles, a, b []complex128, cs, sn []float64) {
+	N := l * p * s
+	r := N / p // W_p = W_N^r
+	h := (p - 1) / 2
+	for i := 0; i < p; i++ {
+		cs[i] = tw.cos(i * r)
+		sn[i] = tw.sin(i * r)
+	}
+	var a0, u, v, re, im complex128
+	var c, sv float64
+	for q := 0; q < l; q++ {
+		for k := 0; k < s; k++ {
+			a0 = x[k+s*q]
+			u = a0
+			for i := 1; i <= h; i++ {
+				v = x[k+s*(q+l*i)]
+				w := x[k+s*(q+l*(p-i))]
+				a[i] = v + w
+				b[i] = v - w
+				u += a[i]
+			}
+			o := k + s*p*q
+			y[o] = u
+			for j := 1; j <= h; j++ {
+				re, im = a0, 0i
+				ij := 0
+				for i := 1; i <= h; i++ {
+					ij += j
+					if ij >= p {
+						ij -= p
+					}
+					c, sv = cs[ij], sn[ij]
+					re += complex(c*real(a[i]), c*imag(a[i]))
+					im += complex(sv*real(b[i]), sv*imag(b[i]))
+				}
+				// multiply by i
+				im = complex(-imag(im), real(im))
+				y[o+s*j] = (re + im) * tw.cmplx(j*q*s)
+				y[o+s*(p-j)] = (re - im) * tw.cmplx((p-j)*q*s)
+			}
+		}
+	}
+}
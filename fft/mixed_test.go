@@ -0,0 +1,78 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package fft
+
+import (
+	"testing"
+
+	"github.com/zikichombo/sound/freq"
+)
+
+func TestRadices(t *testing.T) {
+	for _, n := range []int{2, 3, 6, 12, 480, 960, 1000, 1536, 7 * 11 * 13, 19 * 19, 101483} {
+		p := 1
+		for _, r := range radices(n) {
+			p *= r
+		}
+		if p != n {
+			t.Errorf("radices of %d: %v", n, radices(n))
+		}
+	}
+}
+
+func TestMixed(t *testing.T) {
+	for _, N := range []int{3, 5, 6, 7, 9, 12, 15, 20, 25, 27, 45, 48, 60, 77, 96, 100, 120, 169, 480, 1000} {
+		if New(N).mixed == nil {
+			t.Errorf("%d not mixed radix", N)
+		}
+		testNaive(N, t)
+	}
+	testBasic(960, 1024*freq.Hertz, t)
+	testRand(1536, 1024*freq.Hertz, t)
+}
+
+func TestMixedEqBS(t *testing.T) {
+	for _, N := range []int{6, 60, 120, 210} {
+		w := generate(1000*freq.Hertz, N)
+		ft := New(N)
+		bsft := NewT(N, 1<<log2(2*N-1))
+		dmr, dbs := ft.Win(nil), bsft.Win(nil)
+		ft.To(dmr, w)
+		bsft.To(dbs, w)
+		for i := range w {
+			cmplxCmpErr(dmr[i], dbs[i], 1e-9, t)
+		}
+	}
+}
+
+func benchmarkT(N int, b *testing.B) {
+	b.StopTimer()
+	w := generate(1024*freq.Hertz, N)
+	tr := New(N)
+	d := tr.Win(w)
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		tr.Do(d)
+	}
+}
+
+func benchmarkBS(N int, b *testing.B) {
+	b.StopTimer()
+	w := generate(1024*freq.Hertz, N)
+	tr := NewT(N, 1<<log2(2*N-1))
+	d := tr.Win(w)
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		tr.Do(d)
+	}
+}
+
+func BenchmarkT_Do480(b *testing.B)   { benchmarkT(480, b) }
+func BenchmarkT_Do960(b *testing.B)   { benchmarkT(960, b) }
+func BenchmarkT_Do1000(b *testing.B)  { benchmarkT(1000, b) }
+func BenchmarkT_Do1536(b *testing.B)  { benchmarkT(1536, b) }
+func BenchmarkBS_Do480(b *testing.B)  { benchmarkBS(480, b) }
+func BenchmarkBS_Do960(b *testing.B)  { benchmarkBS(960, b) }
+func BenchmarkBS_Do1000(b *testing.B) { benchmarkBS(1000, b) }
+func BenchmarkBS_Do1536(b *testing.B) { benchmarkBS(1536, b) }
@@ -0,0 +1,67 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package fft
+
+// in place, radix 4 decimation in time Cooley-Tuckey with a radix 2 first
+// pass when log2(len(d)) is odd.
+//
+// After the bit reversal permutation, the 4 sub transforms of size M which
+// are combined into one of size 4M are found at offsets 0, M, 2M, 3M, in the
+// order of the residues 0, 2, 1, 3 mod 4 of the time indices.  Each radix 4
+// butterfly uses 3 complex multiplies where 2 radix 2 passes use 4, and
+// twiddles are looked up once per position in the sub transform rather than
+// once per butterfly.
+//
+// len(d) should be power of 2
+func r4(d []complex128, tw *twiddles, sc bool) {
+	N := len(d)
+	if !is2pow(N) {
+		panic("length not power of 2")
+	}
+	if N <= 2 {
+		r2(d, tw, sc)
+		return
+	}
+	revBinPermute(d)
+	M := 1
+	if log2(N)&1 != 0 {
+		var e, o complex128
+		for q := 0; q < N; q += 2 {
+			e, o = d[q], d[q+1]
+			d[q], d[q+1] = e+o, e-o
+		}
+		M = 2
+	}
+	sg := tw.invSin
+	var a, b, c, e, t0, t1, t2, t3, u1, u2, u3 complex128
+	var M4, s, i0, i1, i2, i3 int
+	for M < N {
+		M4 = 4 * M
+		s = N / M4
+		for r := 0; r < M; r++ {
+			u1 = tw.cmplx(r * s)
+			u2 = tw.cmplx(2 * r * s)
+			u3 = tw.cmplx(3 * r * s)
+			for q := r; q < N; q += M4 {
+				i0, i1, i2, i3 = q, q+M, q+2*M, q+3*M
+				t0 = d[i0]
+				t2 = d[i1] * u2
+				t1 = d[i2] * u1
+				t3 = d[i3] * u3
+				a, b = t0+t2, t0-t2
+				c, e = t1+t3, t1-t3
+				// multiply by W_4 = -i (fwd) or i (inv)
+				e = complex(-sg*imag(e), sg*real(e))
+				d[i0] = a + c
+				d[i1] = b + e
+				d[i2] = a - c
+				d[i3] = b - e
+			}
+		}
+		M = M4
+	}
+	if sc {
+		scale(d)
+	}
+}
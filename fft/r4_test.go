@@ -0,0 +1,53 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package fft
+
+import (
+	"fmt"
+	"math/cmplx"
+	"math/rand"
+	"testing"
+)
+
+func TestR4EqR2(t *testing.T) {
+	for N := 1; N <= 65536; N *= 2 {
+		for _, inv := range []bool{false, true} {
+			d := make([]complex128, N)
+			for i := range d {
+				d[i] = complex(rand.Float64()-0.5, rand.Float64()-0.5)
+			}
+			e := make([]complex128, N)
+			copy(e, d)
+			tw := newTwiddles(N, inv)
+			r2(d, tw, true)
+			r4(e, tw, true)
+			for i := range d {
+				if cmplx.Abs(d[i]-e[i]) > 1e-12 {
+					t.Errorf("N=%d inv=%t at %d: r4 %f != r2 %f", N, inv, i, e[i], d[i])
+					break
+				}
+			}
+		}
+	}
+}
+
+func benchmarkRadix(N int, f func([]complex128, *twiddles, bool), b *testing.B) {
+	b.StopTimer()
+	d := make([]complex128, N)
+	for i := range d {
+		d[i] = complex(rand.Float64(), rand.Float64())
+	}
+	tw := newTwiddles(N, false)
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		f(d, tw, true)
+	}
+}
+
+func BenchmarkRadix(b *testing.B) {
+	for N := 64; N <= 65536; N *= 4 {
+		b.Run(fmt.Sprintf("r2-%d", N), func(b *testing.B) { benchmarkRadix(N, r2, b) })
+		b.Run(fmt.Sprintf("r4-%d", N), func(b *testing.B) { benchmarkRadix(N, r4, b) })
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package fft
+
+import "math"
+
+// rader holds state for Rader's algorithm, which computes a DFT of prime
+// size p as a cyclic convolution of size p-1.
+//
+// With g a primitive root mod p, every non-zero index is g^m for some m in
+// [0..p-1), and
+//
+//	X_{g^-q} = x_0 + sum_{m=0}^{p-2} x_{g^m} W^{g^(m-q)}
+//
+// which is a cyclic convolution of a_m = x_{g^m} and b_m = W^{g^-m}.  The
+// convolution is done with transforms of size p-1, so Rader's algorithm is
+// only used when p-1 factors into small primes, see useRader.
+type rader struct {
+	p      int
+	perm   []int // g^m mod p
+	iperm  []int // g^-m mod p
+	ft     *T    // unscaled, size m
+	m      int
+	bt, ib []complex128 // transform of b, fwd and inverse, lazily computed.
+	buf    []complex128
+}
+
+// useRader returns whether Rader's algorithm does better than Bluestein's
+// for transforms of prime size p.  If p-1 has a prime factor larger than
+// maxRadix, then the convolution needs a zero padded power of 2 transform
+// as large as that of Bluestein's algorithm, and costs as much.  Splitting
+// off the large factor with a Cooley Tuckey step does not help either, since
+// the transforms of the large factor are Rader transforms themselves, which
+// cost more per point than the power of 2 transforms of Bluestein's.
+func useRader(p int) bool {
+	n := p - 1
+	return is2pow(n) || maxFactor(n) <= maxRadix
+}
+
+func newRader(p int) *rader {
+	res := &rader{p: p}
+	res.initPerm()
+	res.ft = New(res.m)
+	res.ft.Scale(false)
+	res.buf = res.ft.Win(nil)
+	return res
+}
+
+// initPerm sets up the convolution size and index permutations of r from
+// r.p.
+func (r *rader) initPerm() {
+	p := r.p
+	n := p - 1
+	r.m = n
+	r.perm = make([]int, n)
+	r.iperm = make([]int, n)
+	g := primRoot(p)
+	v := 1
+	for i := 0; i < n; i++ {
+		r.perm[i] = v
+		v = (v * g) % p
+	}
+	for i := 0; i < n; i++ {
+		r.iperm[i] = r.perm[(n-i)%n]
+	}
+}
+
+// primRoot returns the least primitive root mod the prime p.
+func primRoot(p int) int {
+	n := p - 1
+	var qs []int
+	for _, q := range radices(n) {
+		if q == 4 {
+			q = 2
+		}
+		if len(qs) == 0 || qs[len(qs)-1] != q {
+			qs = append(qs, q)
+		}
+	}
+	for g := 2; g < p; g++ {
+		ok := true
+		for _, q := range qs {
+			if powMod(g, n/q, p) == 1 {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return g
+		}
+	}
+	return 1
+}
+
+func powMod(b, e, m int) int {
+	r := 1
+	b %= m
+	for e > 0 {
+		if e&1 != 0 {
+			r = (r * b) % m
+		}
+		b = (b * b) % m
+		e >>= 1
+	}
+	return r
+}
+
+// getB returns the transform of the convolution kernel b for the direction
+// inv, scaled by 1/m so the unscaled inner inverse gives the convolution.
+func (r *rader) getB(inv bool) []complex128 {
+	if !inv && r.bt != nil {
+		return r.bt
+	}
+	if inv && r.ib != nil {
+		return r.ib
+	}
+	b := cached(cacheKey{kind: ckRader, n: r.p, inv: inv}, func() (interface{}, int) {
+		b := r.kernel(r.ft.Win(nil), inv)
+		r.ft.Do(b)
+		sc := complex(1/float64(r.m), 0)
+		for i := range b {
+			b[i] *= sc
+		}
+		return b, 16 * len(b)
+	}).([]complex128)
+	if inv {
+		r.ib = b
+	} else {
+		r.bt = b
+	}
+	return b
+}
+
+// clone returns a rader sharing the permutations and kernel transforms of r,
+// with its own scratch space.
+func (r *rader) clone() *rader {
+	r.getB(false)
+	r.getB(true)
+	res := *r
+	res.ft = r.ft.clone()
+	res.buf = res.ft.Win(nil)
+	return &res
+}
+
+// kernel places the (untransformed) convolution kernel for the direction inv
+// in b, which has length r.m, and returns it.
+func (r *rader) kernel(b []complex128, inv bool) []complex128 {
+	sg := -1.0
+	if inv {
+		sg = 1.0
+	}
+	w := sg * 2 * math.Pi / float64(r.p)
+	for i := range b {
+		s, c := math.Sincos(w * float64(r.iperm[i]))
+		b[i] = complex(c, s)
+	}
+	return b
+}
+
+// do performs an unscaled transform in place in d.
+func (r *rader) do(d []complex128, inv bool) {
+	b := r.getB(inv)
+	buf := r.buf
+	x0 := d[0]
+	sum := x0
+	for i, j := range r.perm {
+		v := d[j]
+		sum += v
+		buf[i] = v
+	}
+	r.ft.Do(buf)
+	for i := range buf {
+		buf[i] *= b[i]
+	}
+	r.ft.Inv(buf)
+	d[0] = sum
+	for q, j := range r.iperm {
+		d[j] = x0 + buf[q]
+	}
+}
@@ -0,0 +1,346 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package fft
+
+import "math"
+
+// complex64 counterparts of r4, mixed and rader.  See r4.go, mixed.go and
+// rader.go for the algorithms.
+
+func r4c64(d []complex64, tw *twiddles32, sc bool) {
+	N := len(d)
+	if !is2pow(N) {
+		panic("length not power of 2")
+	}
+	if N == 1 {
+		return
+	}
+	if N == 2 {
+		e, o := d[0], d[1]
+		d[0], d[1] = e+o, e-o
+		if sc {
+			scaleC64(d)
+		}
+		return
+	}
+	revBinPermuteC64(d)
+	M := 1
+	if log2(N)&1 != 0 {
+		var e, o complex64
+		for q := 0; q < N; q += 2 {
+			e, o = d[q], d[q+1]
+			d[q], d[q+1] = e+o, e-o
+		}
+		M = 2
+	}
+	sg := tw.invSin
+	var a, b, c, e, t0, t1, t2, t3, u1, u2, u3 complex64
+	var M4, s, i0, i1, i2, i3 int
+	for M < N {
+		M4 = 4 * M
+		s = N / M4
+		for r := 0; r < M; r++ {
+			u1 = tw.cmplx(r * s)
+			u2 = tw.cmplx(2 * r * s)
+			u3 = tw.cmplx(3 * r * s)
+			for q := r; q < N; q += M4 {
+				i0, i1, i2, i3 = q, q+M, q+2*M, q+3*M
+				t0 = d[i0]
+				t2 = d[i1] * u2
+				t1 = d[i2] * u1
+				t3 = d[i3] * u3
+				a, b = t0+t2, t0-t2
+				c, e = t1+t3, t1-t3
+				e = complex(-sg*imag(e), sg*real(e))
+				d[i0] = a + c
+				d[i1] = b + e
+				d[i2] = a - c
+				d[i3] = b - e
+			}
+		}
+		M = M4
+	}
+	if sc {
+		scaleC64(d)
+	}
+}
+
+func revBinPermuteC64(d []complex64) {
+	n := len(d)
+	j := 0
+	var bit int
+	for i := 1; i < n; i++ {
+		bit = n >> 1
+		for j&bit != 0 {
+			j ^= bit
+			bit >>= 1
+		}
+		j ^= bit
+		if i < j {
+			d[i], d[j] = d[j], d[i]
+		}
+	}
+}
+
+func scaleC64(d []complex64) {
+	if len(d) <= 1 {
+		return
+	}
+	a := complex(float32(1/math.Sqrt(float64(len(d)))), 0)
+	for i := range d {
+		d[i] *= a
+	}
+}
+
+type mixed32 struct {
+	radices []int
+	tmp     []complex64
+	gen     []complex64
+	trig    []float32
+}
+
+func newMixed32(n int) *mixed32 {
+	res := &mixed32{
+		radices: radices(n),
+		tmp:     make([]complex64, n)}
+	g := 0
+	for _, p := range res.radices {
+		if p > 5 && p > g {
+			g = p
+		}
+	}
+	res.gen = make([]complex64, g+1)
+	res.trig = make([]float32, 2*g)
+	return res
+}
+
+func (m *mixed32) do(d []complex64, tw *twiddles32) {
+	N := len(d)
+	x, y := d, m.tmp[:N]
+	n, s := N, 1
+	for _, p := range m.radices {
+		l := n / p
+		switch p {
+		case 2:
+			pass2c64(x, y, l, s, tw)
+		case 3:
+			pass3c64(x, y, l, s, tw)
+		case 4:
+			pass4c64(x, y, l, s, tw)
+		case 5:
+			pass5c64(x, y, l, s, tw)
+		default:
+			h := (p + 1) / 2
+			passGenC64(x, y, l, s, p, tw, m.gen[:h], m.gen[h:2*h], m.trig[:p], m.trig[p:2*p])
+		}
+		n = l
+		s *= p
+		x, y = y, x
+	}
+	if &x[0] != &d[0] {
+		copy(d, x)
+	}
+}
+
+func pass2c64(x, y []complex64, l, s int, tw *twiddles32) {
+	var a, b, w complex64
+	for q := 0; q < l; q++ {
+		w = tw.cmplx(q * s)
+		i0, i1 := s*q, s*(q+l)
+		o0, o1 := s*2*q, s*(2*q+1)
+		for k := 0; k < s; k++ {
+			a, b = x[i0+k], x[i1+k]
+			y[o0+k] = a + b
+			y[o1+k] = (a - b) * w
+		}
+	}
+}
+
+func pass3c64(x, y []complex64, l, s int, tw *twiddles32) {
+	k3 := tw.invSin * float32(math.Sqrt(3)/2)
+	var a0, a1, a2, t1, t2, t3, w1, w2 complex64
+	for q := 0; q < l; q++ {
+		w1 = tw.cmplx(q * s)
+		w2 = tw.cmplx(2 * q * s)
+		i0, i1, i2 := s*q, s*(q+l), s*(q+2*l)
+		o0 := s * 3 * q
+		o1, o2 := o0+s, o0+2*s
+		for k := 0; k < s; k++ {
+			a0, a1, a2 = x[i0+k], x[i1+k], x[i2+k]
+			t1 = a1 + a2
+			t2 = a0 - 0.5*t1
+			t3 = a1 - a2
+			t3 = complex(-k3*imag(t3), k3*real(t3))
+			y[o0+k] = a0 + t1
+			y[o1+k] = (t2 + t3) * w1
+			y[o2+k] = (t2 - t3) * w2
+		}
+	}
+}
+
+func pass4c64(x, y []complex64, l, s int, tw *twiddles32) {
+	sg := tw.invSin
+	var a0, a1, a2, a3, t0, t1, t2, t3, w1, w2, w3 complex64
+	for q := 0; q < l; q++ {
+		w1 = tw.cmplx(q * s)
+		w2 = tw.cmplx(2 * q * s)
+		w3 = tw.cmplx(3 * q * s)
+		i0, i1, i2, i3 := s*q, s*(q+l), s*(q+2*l), s*(q+3*l)
+		o0 := s * 4 * q
+		o1, o2, o3 := o0+s, o0+2*s, o0+3*s
+		for k := 0; k < s; k++ {
+			a0, a1, a2, a3 = x[i0+k], x[i1+k], x[i2+k], x[i3+k]
+			t0 = a0 + a2
+			t1 = a0 - a2
+			t2 = a1 + a3
+			t3 = a1 - a3
+			t3 = complex(-sg*imag(t3), sg*real(t3))
+			y[o0+k] = t0 + t2
+			y[o1+k] = (t1 + t3) * w1
+			y[o2+k] = (t0 - t2) * w2
+			y[o3+k] = (t1 - t3) * w3
+		}
+	}
+}
+
+func pass5c64(x, y []complex64, l, s int, tw *twiddles32) {
+	c1, c2 := float32(math.Cos(2*math.Pi/5)), float32(math.Cos(4*math.Pi/5))
+	s1, s2 := tw.invSin*float32(math.Sin(2*math.Pi/5)), tw.invSin*float32(math.Sin(4*math.Pi/5))
+	var a0, a1, a2, a3, a4, b1, b2, d1, d2, t1, t2, u1, u2 complex64
+	var w1, w2, w3, w4 complex64
+	for q := 0; q < l; q++ {
+		w1 = tw.cmplx(q * s)
+		w2 = tw.cmplx(2 * q * s)
+		w3 = tw.cmplx(3 * q * s)
+		w4 = tw.cmplx(4 * q * s)
+		i0, i1, i2, i3, i4 := s*q, s*(q+l), s*(q+2*l), s*(q+3*l), s*(q+4*l)
+		o0 := s * 5 * q
+		o1, o2, o3, o4 := o0+s, o0+2*s, o0+3*s, o0+4*s
+		for k := 0; k < s; k++ {
+			a0, a1, a2, a3, a4 = x[i0+k], x[i1+k], x[i2+k], x[i3+k], x[i4+k]
+			b1, b2 = a1+a4, a2+a3
+			d1, d2 = a1-a4, a2-a3
+			t1 = a0 + complex(c1, 0)*b1 + complex(c2, 0)*b2
+			t2 = a0 + complex(c2, 0)*b1 + complex(c1, 0)*b2
+			u1 = complex(s1, 0)*d1 + complex(s2, 0)*d2
+			u2 = complex(s2, 0)*d1 - complex(s1, 0)*d2
+			u1 = complex(-imag(u1), real(u1))
+			u2 = complex(-imag(u2), real(u2))
+			y[o0+k] = a0 + b1 + b2
+			y[o1+k] = (t1 + u1) * w1
+			y[o2+k] = (t2 + u2) * w2
+			y[o3+k] = (t2 - u2) * w3
+			y[o4+k] = (t1 - u1) * w4
+		}
+	}
+}
+
+func passGenC64(x, y []complex64, l, s, p int, tw *twiddles32, a, b []complex64, cs, sn []float32) {
+	N := l * p * s
+	r := N / p
+	h := (p - 1) / 2
+	for i := 0; i < p; i++ {
+		cs[i] = tw.cos(i * r)
+		sn[i] = tw.sin(i * r)
+	}
+	var a0, u, v, w, re, im complex64
+	var c, sv float32
+	for q := 0; q < l; q++ {
+		for k := 0; k < s; k++ {
+			a0 = x[k+s*q]
+			u = a0
+			for i := 1; i <= h; i++ {
+				v = x[k+s*(q+l*i)]
+				w = x[k+s*(q+l*(p-i))]
+				a[i] = v + w
+				b[i] = v - w
+				u += a[i]
+			}
+			o := k + s*p*q
+			y[o] = u
+			for j := 1; j <= h; j++ {
+				re, im = a0, 0i
+				ij := 0
+				for i := 1; i <= h; i++ {
+					ij += j
+					if ij >= p {
+						ij -= p
+					}
+					c, sv = cs[ij], sn[ij]
+					re += complex(c*real(a[i]), c*imag(a[i]))
+					im += complex(sv*real(b[i]), sv*imag(b[i]))
+				}
+				im = complex(-imag(im), real(im))
+				y[o+s*j] = (re + im) * tw.cmplx(j*q*s)
+				y[o+s*(p-j)] = (re - im) * tw.cmplx((p-j)*q*s)
+			}
+		}
+	}
+}
+
+// rader32 is like rader, but the transform of the convolution kernel is
+// computed in float64 and rounded.
+type rader32 struct {
+	r      *rader
+	ft     *T32
+	bt, ib []complex64
+	buf    []complex64
+}
+
+func newRader32(p int) *rader32 {
+	r := &rader{p: p}
+	r.initPerm()
+	res := &rader32{r: r, ft: New32(r.m)}
+	res.ft.Scale(false)
+	res.buf = res.ft.Win(nil)
+	return res
+}
+
+func (r *rader32) getB(inv bool) []complex64 {
+	if !inv && r.bt != nil {
+		return r.bt
+	}
+	if inv && r.ib != nil {
+		return r.ib
+	}
+	b := cached(cacheKey{kind: ckRader32, n: r.r.p, inv: inv}, func() (interface{}, int) {
+		ft := New(r.r.m)
+		ft.Scale(false)
+		b64 := r.r.kernel(ft.Win(nil), inv)
+		ft.Do(b64)
+		sc := 1 / float64(r.r.m)
+		b := make([]complex64, len(b64))
+		for i, c := range b64 {
+			b[i] = complex64(c * complex(sc, 0))
+		}
+		return b, 8 * len(b)
+	}).([]complex64)
+	if inv {
+		r.ib = b
+	} else {
+		r.bt = b
+	}
+	return b
+}
+
+func (r *rader32) do(d []complex64, inv bool) {
+	b := r.getB(inv)
+	buf := r.buf
+	x0 := d[0]
+	sum := x0
+	for i, j := range r.r.perm {
+		v := d[j]
+		sum += v
+		buf[i] = v
+	}
+	r.ft.Do(buf)
+	for i := range buf {
+		buf[i] *= b[i]
+	}
+	r.ft.Inv(buf)
+	d[0] = sum
+	for q, j := range r.r.iperm {
+		d[j] = x0 + buf[q]
+	}
+}
@@ -12,14 +12,19 @@ import (
 //
 // For even length transforms, the implementation
 // uses a complex FFT of size N/2 and some pre/post processing.
-// For odd length transforms, the implementtion uses a complex
-// FFT of size N.
+// For odd length transforms, the implementation decimates in time
+// by the small prime factors of N with real butterflies, or uses
+// Rader's algorithm with convolutions of half the size for large
+// primes.  Odd
+// lengths whose prime factors are all large use a complex FFT
+// of size N.
 type Real struct {
-	ft     *T           // half sized for even
+	ft     *T           // half sized for even, nil if odd != nil
 	n      int          //
 	cBuf   []complex128 //
 	twidz  []complex128 // only for even
-	scaler float64      // only for even
+	scaler float64      // only for even or odd != nil
+	odd    *oddReal     // only for odd
 }
 
 // NewReal creates a new FFT transformer for
@@ -31,22 +36,41 @@ func NewReal(n int) *Real {
 		res.n = n
 		res.cBuf = res.ft.Win(nil)
 		res.ft.Scale(false)
-		twidz := make([]complex128, m)
 		N := float64(n)
-		for i := range twidz {
-			s, c := math.Sincos(float64(i) * 2.0 * math.Pi / N)
-			twidz[i] = complex(c, -s)
-		}
-		res.twidz = twidz
+		res.twidz = cached(cacheKey{kind: ckRealTw, n: n}, func() (interface{}, int) {
+			twidz := make([]complex128, m)
+			for i := range twidz {
+				s, c := math.Sincos(float64(i) * 2.0 * math.Pi / N)
+				twidz[i] = complex(c, -s)
+			}
+			return twidz, 16 * m
+		}).([]complex128)
 		res.scaler = 1.0 / math.Sqrt(N)
 		return res
 	}
+	if useOddReal(n) {
+		return &Real{n: n, odd: newOddReal(n), scaler: 1.0 / math.Sqrt(float64(n))}
+	}
 	res := &Real{ft: New(n)}
 	res.n = n
 	res.cBuf = res.ft.Win(nil)
 	return res
 }
 
+// clone returns a Real sharing the tables of r with its own scratch space,
+// so that r and the result may be used concurrently.
+func (r *Real) clone() *Real {
+	res := *r
+	if r.ft != nil {
+		res.ft = r.ft.clone()
+		res.cBuf = res.ft.Win(nil)
+	}
+	if r.odd != nil {
+		res.odd = r.odd.clone()
+	}
+	return &res
+}
+
 // Do performs a DFT on real data in d.
 //
 // d must be the size specified in the call to NewReal()
@@ -76,6 +100,16 @@ func (r *Real) evenDo(d []float64) HalfComplex {
 }
 
 func (r *Real) oddDo(d []float64) HalfComplex {
+	if r.odd != nil {
+		r.odd.do(d)
+		hc := HalfComplex(d)
+		if r.scaler != 1.0 {
+			for i := range hc {
+				hc[i] *= r.scaler
+			}
+		}
+		return hc
+	}
 	for i, v := range d {
 		r.cBuf[i] = complex(v, 0.0)
 	}
@@ -117,6 +151,16 @@ func (r *Real) evenInv(hc HalfComplex) []float64 {
 }
 
 func (r *Real) oddInv(hc HalfComplex) []float64 {
+	if r.odd != nil {
+		d := []float64(hc)
+		r.odd.inv(d)
+		if r.scaler != 1.0 {
+			for i := range d {
+				d[i] *= r.scaler
+			}
+		}
+		return d
+	}
 	hc.ToCmplx(r.cBuf)
 	r.ft.Inv(r.cBuf)
 	for i, c := range r.cBuf {
@@ -128,8 +172,11 @@ func (r *Real) oddInv(hc HalfComplex) []float64 {
 // Scale sets whether or not r scales the transform results.
 // Scale returns whether or not r was configured to scale
 // the transform results prior to calling Scale.
+//
+// When not scaling, r.Inv(r.Do(d)) is d multiplied by r.N()
+// for odd r.N(), as with T, and by r.N()/2 for even r.N().
 func (r *Real) Scale(v bool) {
-	if r.n&1 == 0 {
+	if r.n&1 == 0 || r.odd != nil {
 		if !v {
 			r.scaler = 1.0
 		} else {
@@ -0,0 +1,205 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package fft
+
+import "math"
+
+// Real32 is like Real, but for float32 data.  Precision is as described
+// for T32.
+type Real32 struct {
+	ft     *T32
+	n      int
+	cBuf   []complex64
+	twidz  []complex64 // only for even
+	scaler float32     // only for even
+}
+
+// NewReal32 creates a new FFT transformer for
+// float32 data of length n.
+func NewReal32(n int) *Real32 {
+	if n&1 == 0 {
+		m := n / 2
+		res := &Real32{ft: New32(m), n: n}
+		res.cBuf = res.ft.Win(nil)
+		res.ft.Scale(false)
+		N := float64(n)
+		res.twidz = cached(cacheKey{kind: ckRealTw32, n: n}, func() (interface{}, int) {
+			twidz := make([]complex64, m)
+			for i := range twidz {
+				s, c := math.Sincos(float64(i) * 2.0 * math.Pi / N)
+				twidz[i] = complex(float32(c), float32(-s))
+			}
+			return twidz, 8 * m
+		}).([]complex64)
+		res.scaler = float32(1.0 / math.Sqrt(N))
+		return res
+	}
+	res := &Real32{ft: New32(n), n: n}
+	res.cBuf = res.ft.Win(nil)
+	return res
+}
+
+// Do is like Real.Do.
+func (r *Real32) Do(d []float32) HalfComplex32 {
+	if r.n&1 == 0 {
+		return r.evenDo(d)
+	}
+	return r.oddDo(d)
+}
+
+func (r *Real32) evenDo(d []float32) HalfComplex32 {
+	cb := r.cBuf
+	for i := range cb {
+		cb[i] = complex(d[2*i], d[2*i+1])
+	}
+	r.ft.Do(cb)
+	hc := r.toHC(d)
+	if r.scaler == 1.0 {
+		return hc
+	}
+	for i := range hc {
+		hc[i] *= r.scaler
+	}
+	return hc
+}
+
+func (r *Real32) oddDo(d []float32) HalfComplex32 {
+	for i, v := range d {
+		r.cBuf[i] = complex(v, 0.0)
+	}
+	r.ft.Do(r.cBuf)
+	res := HalfComplex32(d)
+	res.FromCmplx(r.cBuf)
+	return res
+}
+
+// Inv is like Real.Inv.
+func (r *Real32) Inv(hc HalfComplex32) []float32 {
+	if r.n&1 == 0 {
+		return r.evenInv(hc)
+	}
+	return r.oddInv(hc)
+}
+
+func (r *Real32) evenInv(hc HalfComplex32) []float32 {
+	if r.scaler != 1.0 {
+		for i := range hc {
+			hc[i] /= r.scaler
+		}
+	}
+	r.fromHC(hc)
+	r.ft.Inv(r.cBuf)
+	res := []float32(hc)
+	for i, v := range r.cBuf {
+		res[2*i] = real(v)
+		res[2*i+1] = imag(v)
+	}
+	if r.scaler != 1.0 {
+		sc := 1.0 / float32(len(r.cBuf))
+		for i := range res {
+			res[i] *= sc
+		}
+	}
+	return res
+}
+
+func (r *Real32) oddInv(hc HalfComplex32) []float32 {
+	hc.ToCmplx(r.cBuf)
+	r.ft.Inv(r.cBuf)
+	for i, c := range r.cBuf {
+		hc[i] = real(c)
+	}
+	return []float32(hc)
+}
+
+// Scale is like Real.Scale.
+func (r *Real32) Scale(v bool) {
+	if r.n&1 == 0 {
+		if !v {
+			r.scaler = 1.0
+		} else {
+			r.scaler = float32(1.0 / math.Sqrt(float64(r.n)))
+		}
+		return
+	}
+	r.ft.Scale(v)
+}
+
+// N returns the length of the arguments to the transform
+// implemented by r.
+func (r *Real32) N() int {
+	return r.n
+}
+
+// see Real.toHC
+func (r *Real32) toHC(d []float32) HalfComplex32 {
+	const (
+		halfR = complex(0.5, 0)
+		halfI = complex(0, 0.5)
+	)
+	N := len(d)
+	if N != r.n {
+		panic("invalid length")
+	}
+	res := HalfComplex32(d)
+	if N == 0 {
+		return res
+	}
+	cb := r.cBuf
+	h := len(cb)
+
+	a := cb[0]
+	f0 := halfR * a
+	g0 := -halfI * a
+	res.SetCmplx(0, 2*(f0+r.twidz[0]*g0))
+	if h+h == N {
+		res[h] = 2 * real(f0-g0)
+	}
+	var b, fi, gi complex64
+	for i := 1; i < h; i++ {
+		a, b = cb[i], cb[h-i]
+		b = complex(real(b), -imag(b))
+		fi = halfR * (a + b)
+		gi = halfI * (b - a)
+		res.SetCmplx(i, fi+r.twidz[i]*gi)
+	}
+	return res
+}
+
+// see Real.fromHC
+func (r *Real32) fromHC(hc HalfComplex32) {
+	const (
+		halfR = complex(0.5, 0)
+		halfI = complex(0, 0.5)
+	)
+	N := len(hc)
+	if N != r.n {
+		panic("invalid HalfComplex32 length")
+	}
+	if N == 0 {
+		return
+	}
+	conj := func(c complex64) complex64 {
+		return complex(real(c), -imag(c))
+	}
+	h := len(r.cBuf)
+	a := hc.Cmplx(0)
+	f := halfR * a
+	g := conj(r.twidz[0]) * (a - f)
+	c0 := halfR * (f/halfR - g/halfI)
+	var ny float32
+	if h+h == N {
+		ny = 0.5 * hc[h]
+	} else {
+		ny = 0.5 * hc[h-1]
+	}
+	r.cBuf[0] = complex(real(c0)+ny, imag(c0)-ny)
+	var fi, gi complex64
+	for i := 1; i < h; i++ {
+		a, b := hc.Cmplx(i), conj(hc.Cmplx(h-i))
+		fi = halfR * (a + b)
+		gi = conj(r.twidz[i]) * (a - fi)
+		r.cBuf[i] = halfR * (fi/halfR - gi/halfI)
+	}
+}
@@ -10,7 +10,7 @@ import (
 )
 
 func TestRealEquivCmplx(t *testing.T) {
-	for _, N := range []int{64, 65} {
+	for _, N := range []int{64, 65, 3, 9, 15, 31, 37, 41, 43, 45, 63, 77, 225, 961, 1001, 1009, 1019, 1033, 1051, 1125, 2187, 4099, 3 * 4099, 37 * 41, 5 * 37 * 41, 7 * 11 * 13 * 17} {
 		testRealEquivCmplx(N, true, t)
 		testRealEquivCmplx(N, false, t)
 	}
@@ -32,8 +32,12 @@ func testRealEquivCmplx(N int, scale bool, t *testing.T) {
 
 	hc := rft.Do(df)
 	ft.Do(dc)
+	eps := 1e-10
+	if !scale {
+		eps *= float64(N)
+	}
 	for i := 0; i < hc.Len(); i++ {
-		if err := cmplxCmpErr(hc.Cmplx(i), dc[i], 1e-10, nil); err != nil {
+		if err := cmplxCmpErr(hc.Cmplx(i), dc[i], eps, nil); err != nil {
 			t.Errorf("at %d got %f not %f\n", i, hc.Cmplx(i), dc[i])
 		}
 	}
@@ -41,7 +45,7 @@ func testRealEquivCmplx(N int, scale bool, t *testing.T) {
 
 func TestRealInv(t *testing.T) {
 	iters := 1
-	for _, N := range []int{32, 33} {
+	for _, N := range []int{32, 33, 3, 37, 41, 43, 45, 1001, 1009, 1019, 1033, 1051, 1125, 2187, 4099, 37 * 41, 5 * 37 * 41, 7 * 11 * 13 * 17} {
 		d := make([]float64, N)
 		tmp := make([]float64, N)
 		for i := 0; i < iters; i++ {
@@ -61,6 +65,79 @@ func TestRealInv(t *testing.T) {
 	}
 }
 
+func TestRealUnscaledInv(t *testing.T) {
+	// unscaled, Inv(Do(d)) is d multiplied by N for odd N, as with T, and
+	// by N/2 for even N.
+	for _, N := range []int{32, 33, 45, 1009, 1024} {
+		d := make([]float64, N)
+		e := make([]float64, N)
+		for i := range d {
+			d[i] = rand.Float64()
+			e[i] = d[i]
+		}
+		sc := float64(N)
+		if N&1 == 0 {
+			sc /= 2
+		}
+		rft := NewReal(N)
+		rft.Scale(false)
+		res := rft.Inv(rft.Do(d))
+		for i, v := range res {
+			if math.Abs(v-sc*e[i]) > 1e-9*float64(N) {
+				t.Errorf("N=%d at %d got %f not %f", N, i, v, sc*e[i])
+				break
+			}
+		}
+	}
+}
+
+func TestRealOdd(t *testing.T) {
+	for _, N := range []int{45, 1001, 1009} {
+		if NewReal(N).odd == nil {
+			t.Errorf("N=%d not using odd real transform", N)
+		}
+	}
+	if NewReal(37*41).odd != nil {
+		t.Errorf("37*41 should use complex transform")
+	}
+}
+
+func benchmarkRealVsT(N int, b *testing.B) {
+	b.Run("Real", func(b *testing.B) {
+		w := make([]float64, N)
+		tr := NewReal(N)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			tr.Do(w)
+		}
+	})
+	b.Run("T", func(b *testing.B) {
+		tr := New(N)
+		w := tr.Win(nil)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			tr.Do(w)
+		}
+	})
+}
+
+func BenchmarkRealOdd1125(b *testing.B) {
+	benchmarkRealVsT(1125, b)
+}
+
+func BenchmarkRealOdd1001(b *testing.B) {
+	benchmarkRealVsT(1001, b)
+}
+
+// For primes, Real takes about 60% of the time of T.
+func BenchmarkRealOdd1009(b *testing.B) {
+	benchmarkRealVsT(1009, b)
+}
+
+func BenchmarkRealOdd1051(b *testing.B) {
+	benchmarkRealVsT(1051, b)
+}
+
 func BenchmarkReal(b *testing.B) {
 	b.StopTimer()
 	N := 1024
@@ -0,0 +1,553 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package fft
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// oddReal holds the state for unscaled real transforms of odd size n.
+//
+// If n is a prime larger than maxRadix, Rader's algorithm is used with
+// convolutions of half the size, see realRader.
+//
+// Otherwise, n is decimated in time by its prime factors p <= maxRadix in
+// increasing order, n = p*m.  The p real sub sequences x[i*p + j] are
+// transformed with size m, and only the non-negative half of the spectrum is
+// formed from them with radix p butterflies.  Once the input is placed in
+// digit reversed order, each sub spectrum is a contiguous block stored as a
+// HalfComplex, and the radix p butterflies for frequency k read and write
+// exactly the positions j*m + k and j*m + m - k.  So, like r4, all levels
+// but the first operate in place.  What remains of n after the small
+// factors is transformed in each block by a Real.
+//
+// The inverse is the transpose: radix p butterflies on the non-negative
+// half of the spectrum give p Hermitian spectra of size m.  Both directions
+// cost about half of a complex transform of size n.
+type oddReal struct {
+	n       int
+	radices []int          // first level first
+	leaf    *Real          // size n/prod(radices), unscaled; nil if 1
+	perm    []int          // digit reversed order
+	cs, sn  []float64      // cos, sin of 2*pi*i/n for 2*i < n
+	tw      [][]complex128 // per level, see fwdGen
+	t, u    []complex128   // scratch, size max(radices)
+	a, b    []complex128   // scratch for dft
+	ra, rb  []float64      // scratch for fwdGen0, invGen0
+	gc, gs  []float64      // cos, sin of 2*pi*i/gp
+	gp      int            // radix of gc, gs
+	buf     []float64      // scratch, size n
+
+	rader *realRader // nil unless n is prime > maxRadix
+}
+
+// useOddReal returns whether oddReal does better than a complex
+// transform for real transforms of size n.
+func useOddReal(n int) bool {
+	if n&1 == 0 || n < 3 {
+		return false
+	}
+	p, _, _ := factor(n)
+	return p == 1 || p <= maxRadix
+}
+
+func newOddReal(n int) *oddReal {
+	res := &oddReal{n: n, buf: make([]float64, n)}
+	if isPrime(n) && n > maxRadix {
+		res.rader = newRealRader(n)
+		return res
+	}
+	q, g := n, 0
+	for q > 1 {
+		p, _, _ := factor(q)
+		if p == 1 {
+			p = q
+		}
+		if p > maxRadix {
+			break
+		}
+		res.radices = append(res.radices, p)
+		if p > g {
+			g = p
+		}
+		q /= p
+	}
+	if q > 1 {
+		res.leaf = NewReal(q)
+		res.leaf.Scale(false)
+	}
+	src := make([]int, n)
+	for i := range src {
+		src[i] = i
+	}
+	res.perm = digitRev(src, res.radices, make([]int, 0, n))
+	h := (n + 1) / 2
+	res.cs = make([]float64, h)
+	res.sn = make([]float64, h)
+	for i := 0; i < h; i++ {
+		res.sn[i], res.cs[i] = math.Sincos(2 * math.Pi * float64(i) / float64(n))
+	}
+	res.t = make([]complex128, g)
+	res.u = make([]complex128, g)
+	res.a = make([]complex128, g)
+	res.b = make([]complex128, g)
+	res.ra = make([]float64, g)
+	res.rb = make([]float64, g)
+	res.gc = make([]float64, g)
+	res.gs = make([]float64, g)
+	nl := n
+	for _, p := range res.radices {
+		m := nl / p
+		tw := make([]complex128, (p-1)*((m+1)/2))
+		for k := 0; 2*k < m; k++ {
+			for j := 1; j < p; j++ {
+				s, c := math.Sincos(-2 * math.Pi * float64(j*k) / float64(nl))
+				tw[k*(p-1)+j-1] = complex(c, s)
+			}
+		}
+		res.tw = append(res.tw, tw)
+		nl = m
+	}
+	return res
+}
+
+// clone returns an oddReal sharing the tables of o with its own scratch
+// space.
+func (o *oddReal) clone() *oddReal {
+	res := *o
+	res.buf = make([]float64, len(o.buf))
+	if o.rader != nil {
+		res.rader = o.rader.clone()
+		return &res
+	}
+	if o.leaf != nil {
+		res.leaf = o.leaf.clone()
+	}
+	g := len(o.t)
+	res.t = make([]complex128, g)
+	res.u = make([]complex128, g)
+	res.a = make([]complex128, g)
+	res.b = make([]complex128, g)
+	res.ra = make([]float64, g)
+	res.rb = make([]float64, g)
+	res.gc = make([]float64, g)
+	res.gs = make([]float64, g)
+	res.gp = 0
+	return &res
+}
+
+// digitRev appends to dst the elements of src in the order of the
+// decimation by radices.
+func digitRev(src, radices, dst []int) []int {
+	if len(radices) == 0 {
+		return append(dst, src...)
+	}
+	p := radices[0]
+	m := len(src) / p
+	sub := make([]int, m)
+	for j := 0; j < p; j++ {
+		for i := range sub {
+			sub[i] = src[i*p+j]
+		}
+		dst = digitRev(sub, radices[1:], dst)
+	}
+	return dst
+}
+
+// dft places in u the dft of odd size len(u) of t in the direction sg (-1
+// is forward, 1 inverse), pairing indices j and p-j.
+func (o *oddReal) dft(t, u []complex128, sg float64) {
+	p := len(u)
+	h := (p - 1) / 2
+	o.genTrig(p)
+	a, b := o.a[:h+1], o.b[:h+1]
+	u0 := t[0]
+	for j := 1; j <= h; j++ {
+		a[j], b[j] = t[j]+t[p-j], t[j]-t[p-j]
+		u0 += a[j]
+	}
+	u[0] = u0
+	var re, im complex128
+	var c, s float64
+	for l := 1; l <= h; l++ {
+		re, im = t[0], 0i
+		jl := 0
+		for j := 1; j <= h; j++ {
+			jl += l
+			if jl >= p {
+				jl -= p
+			}
+			c, s = o.gc[jl], o.gs[jl]
+			re += complex(c*real(a[j]), c*imag(a[j]))
+			im += complex(s*real(b[j]), s*imag(b[j]))
+		}
+		im = complex(-sg*imag(im), sg*real(im))
+		u[l] = re + im
+		u[p-l] = re - im
+	}
+}
+
+// genTrig places the cosines and sines of 2*pi*i/p in o.gc, o.gs.
+func (o *oddReal) genTrig(p int) {
+	if o.gp == p {
+		return
+	}
+	r := o.n / p // W_p = W_n^r
+	for i := 0; i < p; i++ {
+		if 2*i < p {
+			o.gc[i], o.gs[i] = o.cs[i*r], o.sn[i*r]
+		} else {
+			o.gc[i], o.gs[i] = o.cs[(p-i)*r], -o.sn[(p-i)*r]
+		}
+	}
+	o.gp = p
+}
+
+// do performs a forward transform of d, placing the result in HalfComplex
+// format in d.
+func (o *oddReal) do(d []float64) {
+	if o.rader != nil {
+		o.rader.do(d)
+		return
+	}
+	buf := o.buf
+	for i, j := range o.perm {
+		buf[i] = d[j]
+	}
+	nl := 1
+	if o.leaf != nil {
+		nl = o.leaf.N()
+		for b := 0; b < o.n; b += nl {
+			o.leaf.Do(buf[b : b+nl])
+		}
+	}
+	for l := len(o.radices) - 1; l >= 0; l-- {
+		dst := buf
+		if l == 0 {
+			dst = d
+		}
+		p := o.radices[l]
+		nl *= p
+		switch p {
+		case 3:
+			fwd3(buf, dst, nl, o.tw[l])
+		case 5:
+			fwd5(buf, dst, nl, o.tw[l])
+		default:
+			o.fwdGen(buf, dst, nl, p, o.tw[l])
+		}
+	}
+}
+
+// inv performs an inverse transform of the HalfComplex spectrum in d,
+// placing the result in d.
+func (o *oddReal) inv(d []float64) {
+	if o.rader != nil {
+		o.rader.inv(d)
+		return
+	}
+	buf := o.buf
+	nl := o.n
+	for l, p := range o.radices {
+		src := buf
+		if l == 0 {
+			src = d
+		}
+		switch p {
+		case 3:
+			inv3(src, buf, nl, o.tw[l])
+		case 5:
+			inv5(src, buf, nl, o.tw[l])
+		default:
+			o.invGen(src, buf, nl, p, o.tw[l])
+		}
+		nl /= p
+	}
+	if o.leaf != nil {
+		for b := 0; b < o.n; b += nl {
+			o.leaf.Inv(HalfComplex(buf[b : b+nl]))
+		}
+	}
+	for i, j := range o.perm {
+		d[j] = buf[i]
+	}
+}
+
+// fwdGen performs the radix p butterflies forming the HalfComplex spectra
+// of size nl in dst from the HalfComplex spectra of size nl/p in src.  tw
+// holds W_nl^(j*k) at k*(p-1) + j - 1.
+func (o *oddReal) fwdGen(src, dst []float64, nl, p int, tw []complex128) {
+	m := nl / p
+	t, u := o.t[:p], o.u[:p]
+	var K int
+	for b := 0; b < len(src); b += nl {
+		seqs, out := src[b:b+nl], dst[b:b+nl]
+		o.fwdGen0(seqs, out, p)
+		for k := 1; 2*k < m; k++ {
+			w := tw[k*(p-1) : (k+1)*(p-1)]
+			t[0] = complex(seqs[k], seqs[m-k])
+			for j := 1; j < p; j++ {
+				t[j] = complex(seqs[j*m+k], seqs[j*m+m-k]) * w[j-1]
+			}
+			o.dft(t, u, -1)
+			for l, c := range u {
+				K = k + l*m
+				switch {
+				case K == 0:
+					out[0] = real(c)
+				case 2*K < nl:
+					out[K], out[nl-K] = real(c), imag(c)
+				default:
+					out[nl-K], out[K] = real(c), -imag(c)
+				}
+			}
+		}
+	}
+}
+
+// invGen is the transpose of fwdGen.
+func (o *oddReal) invGen(src, dst []float64, nl, p int, tw []complex128) {
+	m := nl / p
+	t, u := o.t[:p], o.u[:p]
+	var K int
+	for b := 0; b < len(src); b += nl {
+		in, seqs := src[b:b+nl], dst[b:b+nl]
+		o.invGen0(in, seqs, p)
+		for k := 1; 2*k < m; k++ {
+			for l := range t {
+				K = k + l*m
+				switch {
+				case K == 0:
+					t[l] = complex(in[0], 0)
+				case 2*K < nl:
+					t[l] = complex(in[K], in[nl-K])
+				default:
+					t[l] = complex(in[nl-K], -in[K])
+				}
+			}
+			o.dft(t, u, 1)
+			w := tw[k*(p-1) : (k+1)*(p-1)]
+			seqs[k], seqs[m-k] = real(u[0]), imag(u[0])
+			for j := 1; j < p; j++ {
+				c := u[j] * cmplx.Conj(w[j-1])
+				seqs[j*m+k], seqs[j*m+m-k] = real(c), imag(c)
+			}
+		}
+	}
+}
+
+// fwdGen0 is fwdGen for frequency 0 of a block, where the inputs are real.
+func (o *oddReal) fwdGen0(x, y []float64, p int) {
+	nl := len(x)
+	m := nl / p
+	h := (p - 1) / 2
+	o.genTrig(p)
+	e, f := o.ra[:h+1], o.rb[:h+1]
+	x0 := x[0]
+	sum := x0
+	for j := 1; j <= h; j++ {
+		e[j], f[j] = x[j*m]+x[(p-j)*m], x[j*m]-x[(p-j)*m]
+		sum += e[j]
+	}
+	y[0] = sum
+	var re, im float64
+	for l := 1; l <= h; l++ {
+		re, im = x0, 0
+		jl := 0
+		for j := 1; j <= h; j++ {
+			jl += l
+			if jl >= p {
+				jl -= p
+			}
+			re += o.gc[jl] * e[j]
+			im += o.gs[jl] * f[j]
+		}
+		y[l*m], y[nl-l*m] = re, -im
+	}
+}
+
+// invGen0 is the transpose of fwdGen0.
+func (o *oddReal) invGen0(x, y []float64, p int) {
+	nl := len(x)
+	m := nl / p
+	h := (p - 1) / 2
+	o.genTrig(p)
+	e, f := o.ra[:h+1], o.rb[:h+1]
+	x0 := x[0]
+	sum := x0
+	for l := 1; l <= h; l++ {
+		e[l], f[l] = 2*x[l*m], 2*x[nl-l*m]
+		sum += e[l]
+	}
+	y[0] = sum
+	var re, im float64
+	for j := 1; j <= h; j++ {
+		re, im = x0, 0
+		jl := 0
+		for l := 1; l <= h; l++ {
+			jl += j
+			if jl >= p {
+				jl -= p
+			}
+			re += o.gc[jl] * e[l]
+			im += o.gs[jl] * f[l]
+		}
+		y[j*m], y[(p-j)*m] = re-im, re+im
+	}
+}
+
+// sin(2*pi/3)
+const k3 = 0.86602540378443864676
+
+func fwd3(src, dst []float64, nl int, tw []complex128) {
+	m := nl / 3
+	var a0, a1, a2, t0, t1, t2, c complex128
+	var x0, x1, x2 float64
+	for b := 0; b < len(src); b += nl {
+		x, y := src[b:b+nl], dst[b:b+nl]
+		x0, x1, x2 = x[0], x[m], x[2*m]
+		y[0] = x0 + x1 + x2
+		y[m], y[2*m] = x0-0.5*(x1+x2), -k3*(x1-x2)
+		for k := 1; 2*k < m; k++ {
+			a0 = complex(x[k], x[m-k])
+			a1 = complex(x[m+k], x[2*m-k]) * tw[2*k]
+			a2 = complex(x[2*m+k], x[3*m-k]) * tw[2*k+1]
+			t1, t2 = a1+a2, a1-a2
+			t0 = a0 - 0.5*t1
+			t2 = complex(k3*imag(t2), -k3*real(t2))
+			c = a0 + t1
+			y[k], y[nl-k] = real(c), imag(c)
+			c = t0 + t2
+			y[m+k], y[2*m-k] = real(c), imag(c)
+			c = t0 - t2
+			y[m-k], y[2*m+k] = real(c), -imag(c)
+		}
+	}
+}
+
+func inv3(src, dst []float64, nl int, tw []complex128) {
+	m := nl / 3
+	var a0, a1, a2, t0, t1, t2, c complex128
+	var x0, re, im float64
+	for b := 0; b < len(src); b += nl {
+		x, y := src[b:b+nl], dst[b:b+nl]
+		x0, re, im = x[0], x[m], x[2*m]
+		y[0] = x0 + 2*re
+		y[m], y[2*m] = x0-re-2*k3*im, x0-re+2*k3*im
+		for k := 1; 2*k < m; k++ {
+			a0 = complex(x[k], x[nl-k])
+			a1 = complex(x[m+k], x[2*m-k])
+			a2 = complex(x[m-k], -x[2*m+k])
+			t1, t2 = a1+a2, a1-a2
+			t0 = a0 - 0.5*t1
+			t2 = complex(-k3*imag(t2), k3*real(t2))
+			y[k], y[m-k] = real(a0+t1), imag(a0+t1)
+			c = (t0 + t2) * cmplx.Conj(tw[2*k])
+			y[m+k], y[2*m-k] = real(c), imag(c)
+			c = (t0 - t2) * cmplx.Conj(tw[2*k+1])
+			y[2*m+k], y[3*m-k] = real(c), imag(c)
+		}
+	}
+}
+
+const (
+	c51 = 0.30901699437494742410  // cos(2*pi/5)
+	c52 = -0.80901699437494742410 // cos(4*pi/5)
+	s51 = 0.95105651629515357212  // sin(2*pi/5)
+	s52 = 0.58778525229247312917  // sin(4*pi/5)
+)
+
+func fwd5(src, dst []float64, nl int, tw []complex128) {
+	m := nl / 5
+	var a0, a1, a2, a3, a4, b1, b2, d1, d2, t1, t2, v1, v2, c complex128
+	var x0, e1, e2, f1, f2 float64
+	for b := 0; b < len(src); b += nl {
+		x, y := src[b:b+nl], dst[b:b+nl]
+		x0 = x[0]
+		e1, e2 = x[m]+x[4*m], x[2*m]+x[3*m]
+		f1, f2 = x[m]-x[4*m], x[2*m]-x[3*m]
+		y[0] = x0 + e1 + e2
+		y[m], y[4*m] = x0+c51*e1+c52*e2, -(s51*f1 + s52*f2)
+		y[2*m], y[3*m] = x0+c52*e1+c51*e2, -(s52*f1 - s51*f2)
+		for k := 1; 2*k < m; k++ {
+			w := tw[4*k : 4*k+4]
+			a0 = complex(x[k], x[m-k])
+			a1 = complex(x[m+k], x[2*m-k]) * w[0]
+			a2 = complex(x[2*m+k], x[3*m-k]) * w[1]
+			a3 = complex(x[3*m+k], x[4*m-k]) * w[2]
+			a4 = complex(x[4*m+k], x[5*m-k]) * w[3]
+			b1, b2 = a1+a4, a2+a3
+			d1, d2 = a1-a4, a2-a3
+			t1 = a0 + c51*b1 + c52*b2
+			t2 = a0 + c52*b1 + c51*b2
+			v1 = s51*d1 + s52*d2
+			v2 = s52*d1 - s51*d2
+			v1 = complex(imag(v1), -real(v1))
+			v2 = complex(imag(v2), -real(v2))
+			c = a0 + b1 + b2
+			y[k], y[nl-k] = real(c), imag(c)
+			c = t1 + v1
+			y[m+k], y[4*m-k] = real(c), imag(c)
+			c = t2 + v2
+			y[2*m+k], y[3*m-k] = real(c), imag(c)
+			c = t2 - v2
+			y[2*m-k], y[3*m+k] = real(c), -imag(c)
+			c = t1 - v1
+			y[m-k], y[4*m+k] = real(c), -imag(c)
+		}
+	}
+}
+
+func inv5(src, dst []float64, nl int, tw []complex128) {
+	m := nl / 5
+	var a0, a1, a2, a3, a4, b1, b2, d1, d2, t1, t2, v1, v2, c complex128
+	var x0, r1, r2, i1, i2 float64
+	for b := 0; b < len(src); b += nl {
+		x, y := src[b:b+nl], dst[b:b+nl]
+		x0 = x[0]
+		r1, i1 = x[m], x[4*m]
+		r2, i2 = x[2*m], x[3*m]
+		y[0] = x0 + 2*(r1+r2)
+		t1r, t2r := x0+2*(c51*r1+c52*r2), x0+2*(c52*r1+c51*r2)
+		v1r, v2r := 2*(s51*i1+s52*i2), 2*(s52*i1-s51*i2)
+		y[m], y[4*m] = t1r-v1r, t1r+v1r
+		y[2*m], y[3*m] = t2r-v2r, t2r+v2r
+		for k := 1; 2*k < m; k++ {
+			w := tw[4*k : 4*k+4]
+			a0 = complex(x[k], x[nl-k])
+			a1 = complex(x[m+k], x[4*m-k])
+			a2 = complex(x[2*m+k], x[3*m-k])
+			a3 = complex(x[2*m-k], -x[3*m+k])
+			a4 = complex(x[m-k], -x[4*m+k])
+			b1, b2 = a1+a4, a2+a3
+			d1, d2 = a1-a4, a2-a3
+			t1 = a0 + c51*b1 + c52*b2
+			t2 = a0 + c52*b1 + c51*b2
+			v1 = s51*d1 + s52*d2
+			v2 = s52*d1 - s51*d2
+			v1 = complex(-imag(v1), real(v1))
+			v2 = complex(-imag(v2), real(v2))
+			c = a0 + b1 + b2
+			y[k], y[m-k] = real(c), imag(c)
+			c = (t1 + v1) * cmplx.Conj(w[0])
+			y[m+k], y[2*m-k] = real(c), imag(c)
+			c = (t2 + v2) * cmplx.Conj(w[1])
+			y[2*m+k], y[3*m-k] = real(c), imag(c)
+			c = (t2 - v2) * cmplx.Conj(w[2])
+			y[3*m+k], y[4*m-k] = real(c), imag(c)
+			c = (t1 - v1) * cmplx.Conj(w[3])
+			y[4*m+k], y[5*m-k] = real(c), imag(c)
+		}
+	}
+}
+
+// getHC returns the spectrum value at K from the odd length hc.
+func getHC(hc HalfComplex, K int) complex128 {
+	n := len(hc)
+	if 2*K < n {
+		return hc.Cmplx(K)
+	}
+	return cmplx.Conj(hc.Cmplx(n - K))
+}
@@ -0,0 +1,353 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package fft
+
+import "math"
+
+// realRader holds state for unscaled real transforms of prime size p by
+// Rader's algorithm, using the symmetry of real data to halve the size of
+// the convolutions.
+//
+// With n = p-1 and h = n/2, g^h = -1 mod p, so the Rader kernel
+// b_m = W^{g^-m} (see rader.go) satisfies b_{m+h} = conj(b_m).  Writing
+// b = c + i s, c has period h and s changes sign after h.  So for real data
+// a_m = x_{g^m}, with u_m = a_m + a_{m+h} and v_m = a_m - a_{m+h}, the
+// first half of the cyclic convolution of a and b is
+//
+//	y_q = (u * c)_q + i (v *- s)_q,  q < h
+//
+// where * is cyclic and *- negacyclic convolution of size h.  The second
+// half of the spectrum is conjugate to the first.  For the inverse, the
+// Hermitian spectrum gives a_{m+h} = conj(a_m), and with u and v the real
+// and imaginary parts of a_m, m < h, the real output is
+//
+//	y_q = 2((u * c)_q + (v *- s)_q)
+//	y_{q+h} = 2((u * c)_q - (v *- s)_q)
+//
+// The cyclic convolution is done with a real transform of size h.  If h is
+// odd, the negacyclic convolution is a cyclic one of the data and kernel
+// with alternating signs.  If h is even, it is a cyclic complex convolution
+// of size h/2 of (v_m + i v_{m+h/2}) e^{i pi m/h} and likewise for s, whose
+// result times e^{-i pi q/h} has the first half of the negacyclic
+// convolution in its real part and the second in its imaginary part.
+//
+// Altogether, this costs about a complex transform of size p-1, half of
+// the two of the complex Rader transform, but with the pre and post
+// processing of the real and complex convolutions, real transforms of prime
+// size take about 60% of the time of complex ones (see BenchmarkRealOdd1009).
+type realRader struct {
+	p, h  int
+	perm  []int // g^m mod p
+	iperm []int // g^-m mod p
+	cyc   *realConv
+	neg   *realConv  // for odd h
+	ra    *cmplxConv // for even h
+	tw    []complex128
+	u, v  []float64
+	z     []complex128
+}
+
+// realRaderTabs holds the tables of realRader shared via the cache.
+type realRaderTabs struct {
+	perm, iperm []int
+	c, s        HalfComplex  // transformed kernels, s only for odd h
+	ra          []complex128 // transformed kernel for even h
+	tw          []complex128 // e^{i pi m/h}, m < h/2, for even h
+}
+
+func newRealRader(p int) *realRader {
+	n := p - 1
+	h := n / 2
+	cyc := newRealConv(h)
+	var neg *realConv
+	var ra *cmplxConv
+	if h&1 == 1 {
+		neg = newRealConv(h)
+	} else {
+		ra = newCmplxConv(h / 2)
+	}
+	tabs := cached(cacheKey{kind: ckRealRader, n: p}, func() (interface{}, int) {
+		t := &realRaderTabs{
+			perm:  make([]int, n),
+			iperm: make([]int, n)}
+		g := primRoot(p)
+		v := 1
+		for i := 0; i < n; i++ {
+			t.perm[i] = v
+			v = (v * g) % p
+		}
+		for i := 0; i < n; i++ {
+			t.iperm[i] = t.perm[(n-i)%n]
+		}
+		c := make([]float64, h)
+		s := make([]float64, h)
+		for i := range c {
+			sn, cs := math.Sincos(2 * math.Pi * float64(t.iperm[i]) / float64(p))
+			c[i], s[i] = cs, -sn
+		}
+		t.c = cyc.kernel(c)
+		sz := 16*n + 8*cyc.m
+		if neg != nil {
+			for i := 1; i < h; i += 2 {
+				s[i] = -s[i]
+			}
+			t.s = neg.kernel(s)
+			sz += 8 * neg.m
+		} else {
+			H := h / 2
+			t.tw = make([]complex128, H)
+			k := make([]complex128, H)
+			for i := range t.tw {
+				sn, cs := math.Sincos(math.Pi * float64(i) / float64(h))
+				t.tw[i] = complex(cs, sn)
+				k[i] = complex(s[i], s[i+H]) * t.tw[i]
+			}
+			t.ra = ra.kernel(k)
+			sz += 16*H + 16*ra.m
+		}
+		return t, sz
+	}).(*realRaderTabs)
+	res := &realRader{
+		p:     p,
+		h:     h,
+		perm:  tabs.perm,
+		iperm: tabs.iperm,
+		cyc:   cyc,
+		neg:   neg,
+		ra:    ra,
+		tw:    tabs.tw,
+		u:     make([]float64, h),
+		v:     make([]float64, h)}
+	cyc.k = tabs.c
+	if neg != nil {
+		neg.k = tabs.s
+	} else {
+		ra.k = tabs.ra
+		res.z = make([]complex128, h/2)
+	}
+	return res
+}
+
+// clone returns a realRader sharing the tables of r with its own scratch
+// space.
+func (r *realRader) clone() *realRader {
+	res := *r
+	res.cyc = r.cyc.clone()
+	if r.neg != nil {
+		res.neg = r.neg.clone()
+	} else {
+		res.ra = r.ra.clone()
+		res.z = make([]complex128, len(r.z))
+	}
+	res.u = make([]float64, r.h)
+	res.v = make([]float64, r.h)
+	return &res
+}
+
+// conv places u * c in u and v *- s in v.
+func (r *realRader) conv() {
+	u, v := r.u, r.v
+	r.cyc.conv(u)
+	if r.neg != nil {
+		for i := 1; i < len(v); i += 2 {
+			v[i] = -v[i]
+		}
+		r.neg.conv(v)
+		for i := 1; i < len(v); i += 2 {
+			v[i] = -v[i]
+		}
+		return
+	}
+	H := len(r.z)
+	z := r.z
+	for i, t := range r.tw {
+		z[i] = complex(v[i], v[i+H]) * t
+	}
+	r.ra.conv(z)
+	for i, t := range r.tw {
+		w := z[i] * complex(real(t), -imag(t))
+		v[i], v[i+H] = real(w), imag(w)
+	}
+}
+
+// do performs a forward transform of d, placing the result in HalfComplex
+// format in d.
+func (r *realRader) do(d []float64) {
+	p, h := r.p, r.h
+	u, v := r.u, r.v
+	x0 := d[0]
+	sum := x0
+	for m := 0; m < h; m++ {
+		a, b := d[r.perm[m]], d[r.perm[m+h]]
+		u[m], v[m] = a+b, a-b
+		sum += a + b
+	}
+	r.conv()
+	hc := HalfComplex(d)
+	hc[0] = sum
+	for q := 0; q < h; q++ {
+		K := r.iperm[q]
+		if 2*K < p {
+			hc.SetCmplx(K, complex(x0+u[q], v[q]))
+		} else {
+			hc.SetCmplx(p-K, complex(x0+u[q], -v[q]))
+		}
+	}
+}
+
+// inv performs an inverse transform of the HalfComplex spectrum in d,
+// placing the result in d.
+func (r *realRader) inv(d []float64) {
+	p, h := r.p, r.h
+	u, v := r.u, r.v
+	hc := HalfComplex(d)
+	X0 := hc[0]
+	x0 := X0
+	for K := 1; 2*K < p; K++ {
+		x0 += 2 * hc[K]
+	}
+	for m := 0; m < h; m++ {
+		c := getHC(hc, r.perm[m])
+		u[m], v[m] = real(c), imag(c)
+	}
+	r.conv()
+	d[0] = x0
+	for q := 0; q < h; q++ {
+		d[r.iperm[q]] = X0 + 2*(u[q]+v[q])
+		d[r.iperm[q+h]] = X0 + 2*(u[q]-v[q])
+	}
+}
+
+// convSize returns the size of the transforms for cyclic convolutions of
+// size n: n if it has only small prime factors, or else a power of 2
+// allowing a linear convolution with the periodically extended kernel.
+func convSize(n int) int {
+	if is2pow(n) || maxFactor(n) <= maxRadix {
+		return n
+	}
+	return 1 << log2(2*n-1)
+}
+
+// realConv performs cyclic convolutions of real data of size n with a fixed
+// kernel.
+type realConv struct {
+	n, m int
+	ft   *Real // unscaled, size m
+	k    HalfComplex
+	buf  []float64
+}
+
+func newRealConv(n int) *realConv {
+	m := convSize(n)
+	res := &realConv{n: n, m: m, ft: NewReal(m), buf: make([]float64, m)}
+	res.ft.Scale(false)
+	return res
+}
+
+// kernel returns the transform of the kernel k of size c.n, scaled so that
+// the unscaled inverse gives the convolution.
+func (c *realConv) kernel(k []float64) HalfComplex {
+	b := make([]float64, c.m)
+	copy(b, k)
+	if c.m != c.n {
+		for i := 1; i < c.n; i++ {
+			b[c.m-i] = k[c.n-i]
+		}
+	}
+	// the unscaled inverse gives m times the data for odd m and m/2
+	// times for even m.
+	sc := 1 / float64(c.m)
+	if c.m&1 == 0 {
+		sc *= 2
+	}
+	for i := range b {
+		b[i] *= sc
+	}
+	return c.ft.Do(b)
+}
+
+func (c *realConv) clone() *realConv {
+	res := *c
+	res.ft = c.ft.clone()
+	res.buf = make([]float64, c.m)
+	return &res
+}
+
+// conv places the convolution of d with the kernel in d.
+func (c *realConv) conv(d []float64) {
+	buf := c.buf
+	copy(buf, d)
+	for i := c.n; i < c.m; i++ {
+		buf[i] = 0
+	}
+	hc := c.ft.Do(buf)
+	k := c.k
+	m := c.m
+	hc[0] *= k[0]
+	for i := 1; 2*i < m; i++ {
+		ar, ai := hc[i], hc[m-i]
+		br, bi := k[i], k[m-i]
+		hc[i] = ar*br - ai*bi
+		hc[m-i] = ar*bi + ai*br
+	}
+	if m&1 == 0 {
+		hc[m/2] *= k[m/2]
+	}
+	c.ft.Inv(buf)
+	copy(d, buf)
+}
+
+// cmplxConv is like realConv for complex data.
+type cmplxConv struct {
+	n, m int
+	ft   *T // unscaled, size m
+	k    []complex128
+	buf  []complex128
+}
+
+func newCmplxConv(n int) *cmplxConv {
+	m := convSize(n)
+	res := &cmplxConv{n: n, m: m, ft: New(m), buf: make([]complex128, m)}
+	res.ft.Scale(false)
+	return res
+}
+
+// kernel is like realConv.kernel.
+func (c *cmplxConv) kernel(k []complex128) []complex128 {
+	b := make([]complex128, c.m)
+	copy(b, k)
+	if c.m != c.n {
+		for i := 1; i < c.n; i++ {
+			b[c.m-i] = k[c.n-i]
+		}
+	}
+	sc := complex(1/float64(c.m), 0)
+	for i := range b {
+		b[i] *= sc
+	}
+	c.ft.Do(b)
+	return b
+}
+
+func (c *cmplxConv) clone() *cmplxConv {
+	res := *c
+	res.ft = c.ft.clone()
+	res.buf = make([]complex128, c.m)
+	return &res
+}
+
+// conv places the convolution of d with the kernel in d.
+func (c *cmplxConv) conv(d []complex128) {
+	buf := c.buf
+	copy(buf, d)
+	for i := c.n; i < c.m; i++ {
+		buf[i] = 0
+	}
+	c.ft.Do(buf)
+	for i, v := range c.k {
+		buf[i] *= v
+	}
+	c.ft.Inv(buf)
+	copy(d, buf)
+}
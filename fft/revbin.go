@@ -25,12 +25,23 @@ func revBinLim(i int) uint {
 	return N
 }
 
+// revBinPermute permutes d, whose length is a power of 2, placing
+// each element at the bit reversal of its index.
+//
+// The reversed index is maintained incrementally, by adding 1 from the
+// most significant bit downwards, rather than computed with revBin.
 func revBinPermute(d []complex128) {
-	m := len(d)
-	N := revBinLim(m)
-	for i := range d {
-		j := revBin(i, N)
-		if j < i {
+	n := len(d)
+	j := 0
+	var bit int
+	for i := 1; i < n; i++ {
+		bit = n >> 1
+		for j&bit != 0 {
+			j ^= bit
+			bit >>= 1
+		}
+		j ^= bit
+		if i < j {
 			d[i], d[j] = d[j], d[i]
 		}
 	}
@@ -23,7 +23,7 @@ func TestRevBin(t *testing.T) {
 	}
 }
 
-func testRevBinPermute(t *testing.T) {
+func TestRevBinPermute(t *testing.T) {
 	N := 128
 	L := revBinLim(N)
 	d := make([]complex128, N)
@@ -31,7 +31,7 @@ func testRevBinPermute(t *testing.T) {
 		d[i] = complex(rand.Float64(), rand.Float64())
 	}
 	c := make([]complex128, N)
-	copy(d, c)
+	copy(c, d)
 	revBinPermute(d)
 	for i := range d {
 		if c[i] != d[revBin(i, L)] {
@@ -281,7 +281,7 @@ func (s *S) Rect(dst []complex128) []complex128 {
 // is allocated and returned.  Otherwise, the results are placed in dst
 // and returned.
 func (s *S) ToHalfComplex(dst HalfComplex) HalfComplex {
-	if cap(dst) != len(s.mags) {
+	if cap(dst) < len(s.mags) {
 		dst = HalfComplex(make([]float64, len(s.mags)))
 	}
 	dst = dst[:len(s.mags)]
@@ -120,3 +120,15 @@ func testSSymN(N int, t *testing.T) {
 		}
 	}
 }
+
+func TestSToHalfComplexCap(t *testing.T) {
+	sp := NewSN(16)
+	for i := 0; i < 16; i++ {
+		sp.SetMag(i, rand.Float64())
+	}
+	buf := make([]float64, 0, 32)
+	hc := sp.ToHalfComplex(buf)
+	if len(hc) != 16 || &hc[:1][0] != &buf[:1][0] {
+		t.Errorf("dst with spare capacity not used")
+	}
+}
@@ -10,11 +10,13 @@ import (
 
 // T maintains state for efficient repeated computation on windows of data.
 type T struct {
-	n, padN int       // padN = n if n is power of 2, least power of 2 >= 2*n + 1 otherwise.
+	n, padN int       // padN = n if n is power of 2 or mixed radix, least power of 2 >= 2*n + 1 otherwise.
 	twids   *twiddles // size padN
 	chirpz  *chirpz   // nil if n == padN
 	itwids  *twiddles
 	ichirpz *chirpz
+	mixed   *mixed // nil unless n == padN and n is not a power of 2
+	rader   *rader // nil unless n is a prime > maxRadix and useRader(n)
 	scale   bool
 }
 
@@ -25,16 +27,26 @@ type T struct {
 //
 // - inv is whether or not to use the "inverse" transform
 func New(n int) *T {
-	if is2pow(n) {
+	if is2pow(n) || maxFactor(n) <= maxRadix {
 		return NewT(n, n)
 	}
+	if isPrime(n) && useRader(n) {
+		return &T{n: n, padN: n, rader: newRader(n), scale: true}
+	}
 	return NewT(n, 1<<log2(2*n-1))
 }
 
 // we factor this out so we can test case where
 // N is power of 2, and padN = 2N.
+//
+// If padN == n and n is not a power of 2, then mixed
+// radix Cooley Tuckey is used, otherwise Bluestein.
 func NewT(n, padN int) *T {
-	return &T{n: n, padN: padN, scale: true}
+	res := &T{n: n, padN: padN, scale: true}
+	if n == padN && !is2pow(n) {
+		res.mixed = newMixed(n)
+	}
+	return res
 }
 
 // Win returns a slice of data with dimensions set so no error occurs if used
@@ -97,8 +109,22 @@ func (t *T) Do(d []complex128) error {
 	if e := t.ckSrc(d); e != nil {
 		return e
 	}
+	if t.mixed != nil {
+		t.mixed.do(d, t.getTwids(false))
+		if t.scale {
+			scale(d)
+		}
+		return nil
+	}
+	if t.rader != nil {
+		t.rader.do(d, false)
+		if t.scale {
+			scale(d)
+		}
+		return nil
+	}
 	if t.n == t.padN {
-		r2(d, t.getTwids(false), t.scale)
+		r4(d, t.getTwids(false), t.scale)
 		return nil
 	}
 	return t.bluestein(d, false)
@@ -109,8 +135,22 @@ func (t *T) Inv(d []complex128) error {
 	if e := t.ckSrc(d); e != nil {
 		return e
 	}
+	if t.mixed != nil {
+		t.mixed.do(d, t.getTwids(true))
+		if t.scale {
+			scale(d)
+		}
+		return nil
+	}
+	if t.rader != nil {
+		t.rader.do(d, true)
+		if t.scale {
+			scale(d)
+		}
+		return nil
+	}
 	if t.n == t.padN {
-		r2(d, t.getTwids(true), t.scale)
+		r4(d, t.getTwids(true), t.scale)
 		return nil
 	}
 	return t.bluestein(d, true)
@@ -148,7 +188,7 @@ func (t *T) bluestein(d []complex128, inv bool) error {
 
 	// perform fwd dft on input zero padded (no scaling since chirps are scaled)
 	d = pad(d, t.padN)
-	r2(d, t.getTwids(inv), inv)
+	r4(d, t.getTwids(inv), inv)
 
 	// pointwise multiply for convolution (also scales since chirps scaled)
 	chirpz := t.getChirpz(inv)
@@ -157,7 +197,7 @@ func (t *T) bluestein(d []complex128, inv bool) error {
 	}
 
 	// inverse (scaled, since d was scaled since chirps were scaled)
-	r2(d, t.getTwids(!inv), !inv)
+	r4(d, t.getTwids(!inv), !inv)
 	d = d[:t.n]
 	if t.scale {
 		scale(d) // scale to current len
@@ -225,12 +265,12 @@ func (t *T) AutoCorr(d []complex128) error {
 func (t *T) getTwids(inv bool) *twiddles {
 	if !inv {
 		if t.twids == nil {
-			t.twids = newTwiddles(t.padN, false)
+			t.twids = sharedTwiddles(t.padN, false)
 		}
 		return t.twids
 	}
 	if t.itwids == nil {
-		t.itwids = newTwiddles(t.padN, true)
+		t.itwids = sharedTwiddles(t.padN, true)
 	}
 	return t.itwids
 }
@@ -238,12 +278,32 @@ func (t *T) getTwids(inv bool) *twiddles {
 func (t *T) getChirpz(inv bool) *chirpz {
 	if !inv {
 		if t.chirpz == nil {
-			t.chirpz = newChirpz(t.n, t.padN, t.getTwids(false))
+			t.chirpz = sharedChirpz(t.n, t.padN, false)
 		}
 		return t.chirpz
 	}
 	if t.ichirpz == nil {
-		t.ichirpz = newChirpz(t.n, t.padN, t.getTwids(true))
+		t.ichirpz = sharedChirpz(t.n, t.padN, true)
 	}
 	return t.ichirpz
 }
+
+// clone returns a T which shares the tables of t but has its own scratch
+// space, so that t and the result may be used concurrently.  The tables
+// are computed before sharing.
+func (t *T) clone() *T {
+	res := *t
+	switch {
+	case t.mixed != nil:
+		res.mixed = t.mixed.clone()
+	case t.rader != nil:
+		res.rader = t.rader.clone()
+	}
+	if t.rader == nil {
+		res.twids, res.itwids = t.getTwids(false), t.getTwids(true)
+	}
+	if t.n != t.padN {
+		res.chirpz, res.ichirpz = t.getChirpz(false), t.getChirpz(true)
+	}
+	return &res
+}
@@ -0,0 +1,241 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package fft
+
+import "fmt"
+
+// T32 is like T but for complex64 data.
+//
+// T32 computes in float32 throughout, using the same algorithms as T.  Its
+// twiddle factors, and the chirps and kernels of Bluestein and Rader
+// transforms, are computed in float64 and rounded.  For inputs with unit root
+// mean square, the error relative to the exact (scaled) transform is
+// typically on the order of 1e-7 * log2(n) in root mean square, and in the
+// worst case on the order of 1e-6 * log2(n) per bin.  Bluestein and Rader
+// transforms perform 2 inner transforms and so have about twice the error.
+type T32 struct {
+	n, padN int
+	twids   *twiddles32
+	itwids  *twiddles32
+	chirpz  *chirpz32
+	ichirpz *chirpz32
+	mixed   *mixed32
+	rader   *rader32
+	scale   bool
+}
+
+// New32 creates a new T32 for transforms of size n.
+func New32(n int) *T32 {
+	if is2pow(n) || maxFactor(n) <= maxRadix {
+		return NewT32(n, n)
+	}
+	if isPrime(n) && useRader(n) {
+		return &T32{n: n, padN: n, rader: newRader32(n), scale: true}
+	}
+	return NewT32(n, 1<<log2(2*n-1))
+}
+
+// NewT32 is like NewT but for complex64 data.
+func NewT32(n, padN int) *T32 {
+	res := &T32{n: n, padN: padN, scale: true}
+	if n == padN && !is2pow(n) {
+		res.mixed = newMixed32(n)
+	}
+	return res
+}
+
+// Win is like T.Win.
+func (t *T32) Win(c []complex64) []complex64 {
+	if cap(c) < t.padN {
+		tmp := make([]complex64, len(c), t.padN)
+		copy(tmp, c)
+		c = tmp
+	}
+	if len(c) < t.n {
+		c = c[:t.n]
+		for i := len(c); i < t.n; i++ {
+			c[i] = 0i
+		}
+	}
+	return c[:t.n]
+}
+
+// N returns the size of the transforms to be performed.
+func (t *T32) N() int {
+	return t.n
+}
+
+// Cap returns the desired capacity of slices passed into
+// Do() and as dst to To().
+func (t *T32) Cap() int {
+	return t.padN
+}
+
+// Scale is like T.Scale.
+func (t *T32) Scale(v bool) {
+	t.scale = v
+}
+
+// To is like T.To.
+func (t *T32) To(dst, src []complex64) ([]complex64, error) {
+	if len(src) != t.n {
+		return nil, fmt.Errorf("wrong input size, got %d expected %d", len(src), t.n)
+	}
+	if dst == nil {
+		dst = t.Win(nil)
+	}
+	if e := t.ckSrc(dst); e != nil {
+		return nil, e
+	}
+	copy(dst, src)
+	e := t.Do(dst)
+	return dst, e
+}
+
+// InvTo is like T.InvTo.
+func (t *T32) InvTo(dst, src []complex64) ([]complex64, error) {
+	if len(src) != t.n {
+		return nil, fmt.Errorf("wrong input size, got %d expected %d", len(src), t.n)
+	}
+	if dst == nil {
+		dst = t.Win(nil)
+	}
+	if e := t.ckSrc(dst); e != nil {
+		return nil, e
+	}
+	copy(dst, src)
+	e := t.Inv(dst)
+	return dst, e
+}
+
+// Do performs an in-place transform on d.
+func (t *T32) Do(d []complex64) error {
+	return t.do(d, false)
+}
+
+// Inv performs an in-place inverse transform on d.
+func (t *T32) Inv(d []complex64) error {
+	return t.do(d, true)
+}
+
+func (t *T32) do(d []complex64, inv bool) error {
+	if e := t.ckSrc(d); e != nil {
+		return e
+	}
+	switch {
+	case t.mixed != nil:
+		t.mixed.do(d, t.getTwids(inv))
+	case t.rader != nil:
+		t.rader.do(d, inv)
+	case t.n == t.padN:
+		r4c64(d, t.getTwids(inv), t.scale)
+		return nil
+	default:
+		t.bluestein(d, inv)
+		return nil
+	}
+	if t.scale {
+		scaleC64(d)
+	}
+	return nil
+}
+
+func (t *T32) bluestein(d []complex64, inv bool) {
+	iChirpz := t.getChirpz(!inv)
+	for i := range d {
+		d[i] *= iChirpz.D[i]
+	}
+	d = padC64(d, t.padN)
+	r4c64(d, t.getTwids(inv), inv)
+	chirpz := t.getChirpz(inv)
+	for i := range d {
+		d[i] *= chirpz.tD[i]
+	}
+	r4c64(d, t.getTwids(!inv), !inv)
+	d = d[:t.n]
+	if t.scale {
+		scaleC64(d)
+	}
+	for i := range d {
+		d[i] *= iChirpz.D[i]
+	}
+}
+
+func (t *T32) ckSrc(d []complex64) error {
+	if len(d) != t.n {
+		return fmt.Errorf("wrong length %d != %d", len(d), t.n)
+	}
+	if cap(d) < t.padN {
+		return fmt.Errorf("wrong cap got %d expected %d", cap(d), t.padN)
+	}
+	return nil
+}
+
+func (t *T32) getTwids(inv bool) *twiddles32 {
+	if !inv {
+		if t.twids == nil {
+			t.twids = sharedTwiddles32(t.padN, false)
+		}
+		return t.twids
+	}
+	if t.itwids == nil {
+		t.itwids = sharedTwiddles32(t.padN, true)
+	}
+	return t.itwids
+}
+
+func (t *T32) getChirpz(inv bool) *chirpz32 {
+	if !inv {
+		if t.chirpz == nil {
+			t.chirpz = sharedChirpz32(t.n, t.padN, false)
+		}
+		return t.chirpz
+	}
+	if t.ichirpz == nil {
+		t.ichirpz = sharedChirpz32(t.n, t.padN, true)
+	}
+	return t.ichirpz
+}
+
+// chirpz32 holds the chirps of chirpz, rounded to complex64.
+type chirpz32 struct {
+	D  []complex64
+	tD []complex64
+}
+
+// sharedChirpz32 is like sharedChirpz for chirpz32.
+func sharedChirpz32(n, padN int, inv bool) *chirpz32 {
+	return cached(cacheKey{kind: ckChirpz32, n: n, padN: padN, inv: inv}, func() (interface{}, int) {
+		return newChirpz32(n, padN, inv), 16 * padN
+	}).(*chirpz32)
+}
+
+func newChirpz32(n, padN int, inv bool) *chirpz32 {
+	c := sharedChirpz(n, padN, inv)
+	res := &chirpz32{
+		D:  make([]complex64, padN),
+		tD: make([]complex64, padN)}
+	for i := range c.D {
+		res.D[i] = complex64(c.D[i])
+		res.tD[i] = complex64(c.tD[i])
+	}
+	return res
+}
+
+func padC64(d []complex64, toLen int) []complex64 {
+	n := len(d)
+	if n >= toLen {
+		return d[:toLen]
+	}
+	if cap(d) < toLen {
+		t := make([]complex64, toLen)
+		copy(t, d)
+		return t
+	}
+	d = d[:toLen]
+	for i := n; i < toLen; i++ {
+		d[i] = 0i
+	}
+	return d
+}
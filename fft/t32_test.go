@@ -0,0 +1,126 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package fft
+
+import (
+	"math"
+	"math/cmplx"
+	"math/rand"
+	"testing"
+)
+
+// maximum per bin error of T32 relative to T, for inputs
+// of unit rms.
+func t32Eps(n int) float64 {
+	return 2e-6 * float64(log2(n)+1)
+}
+
+func TestT32(t *testing.T) {
+	for _, N := range []int{1, 2, 4, 8, 64, 1024, 6, 480, 1000, 77, 37, 1009, 4099, 4201, 2 * 4099} {
+		d := make([]complex128, N)
+		for i := range d {
+			d[i] = complex(rand.Float64()*2-1, rand.Float64()*2-1)
+		}
+		ft, ft32 := New(N), New32(N)
+		w, w32 := ft.Win(nil), ft32.Win(nil)
+		copy(w, d)
+		for i, c := range d {
+			w32[i] = complex64(c)
+		}
+		eps := t32Eps(N)
+		ft.Do(w)
+		ft32.Do(w32)
+		for i := range w {
+			if cmplx.Abs(w[i]-complex128(w32[i])) > eps {
+				t.Errorf("N=%d fwd at %d: %f != %f", N, i, w32[i], w[i])
+				break
+			}
+		}
+		ft32.Inv(w32)
+		for i := range d {
+			if cmplx.Abs(d[i]-complex128(w32[i])) > 2*eps {
+				t.Errorf("N=%d inv at %d: %f != %f", N, i, w32[i], d[i])
+				break
+			}
+		}
+	}
+}
+
+func TestReal32(t *testing.T) {
+	for _, N := range []int{64, 65, 960, 1009} {
+		d := make([]float64, N)
+		d32 := make([]float32, N)
+		org := make([]float32, N)
+		for i := range d {
+			d[i] = rand.Float64()*2 - 1
+			d32[i] = float32(d[i])
+			org[i] = d32[i]
+		}
+		rft, rft32 := NewReal(N), NewReal32(N)
+		hc := rft.Do(d)
+		hc32 := rft32.Do(d32)
+		eps := t32Eps(N)
+		for i := range hc {
+			if math.Abs(hc[i]-float64(hc32[i])) > eps {
+				t.Errorf("N=%d at %d: %f != %f", N, i, hc32[i], hc[i])
+				break
+			}
+		}
+		res := rft32.Inv(hc32)
+		for i := range res {
+			if math.Abs(float64(res[i]-org[i])) > 2*eps {
+				t.Errorf("N=%d inv at %d: %f", N, i, res[i])
+				break
+			}
+		}
+	}
+}
+
+func TestHalfComplex32(t *testing.T) {
+	for _, N := range []int{64, 65} {
+		d := make([]float32, N)
+		e := make([]float32, N)
+		c := make([]complex64, N)
+		mag, ph := make([]float32, N), make([]float32, N)
+		for i := range d {
+			d[i] = rand.Float32()
+			e[i] = d[i]
+		}
+		hc := HalfComplex32(d)
+		hc.ToCmplx(c)
+		hc.FromCmplx(c)
+		for i, v := range hc {
+			if v != e[i] {
+				t.Errorf("N=%d i=%d after to/from cmplx got %f not %f\n", N, i, v, e[i])
+			}
+		}
+		hc.ToPolar(mag, ph)
+		hc.FromPolar(mag, ph)
+		for i, v := range hc {
+			if math.Abs(float64(v-e[i])) > 1e-6 {
+				t.Errorf("N=%d i=%d after to/from polar got %f not %f\n", N, i, v, e[i])
+			}
+		}
+	}
+}
+
+func BenchmarkT32_DoR2(b *testing.B) {
+	N := 1024
+	tr := New32(N)
+	w := tr.Win(nil)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tr.Do(w)
+	}
+}
+
+func BenchmarkReal32(b *testing.B) {
+	N := 1024
+	w := make([]float32, N)
+	tr := NewReal32(N)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tr.Do(w)
+	}
+}
@@ -54,6 +54,37 @@ func TestBS(t *testing.T) {
 	testRand(1019, 1024*freq.Hertz, t)
 }
 
+func TestRader(t *testing.T) {
+	for _, N := range []int{37, 41, 97, 1009, 4201} {
+		if New(N).rader == nil {
+			t.Errorf("%d not using rader", N)
+		}
+		testNaive(N, t)
+	}
+	// 4098 = 2*3*683 needs padding, so Bluestein is used.
+	if ft := New(4099); ft.rader != nil || ft.padN == 4099 {
+		t.Errorf("4099 using rader")
+	}
+	testNaive(4099, t)
+	testRand(8191, 1024*freq.Hertz, t)
+}
+
+func TestPrimRoot(t *testing.T) {
+	for _, p := range []int{3, 5, 7, 37, 1009, 4099} {
+		g := primRoot(p)
+		seen := make([]bool, p)
+		v := 1
+		for i := 0; i < p-1; i++ {
+			if seen[v] {
+				t.Errorf("%d not a primitive root of %d", g, p)
+				break
+			}
+			seen[v] = true
+			v = (v * g) % p
+		}
+	}
+}
+
 func TestBSEqR2(t *testing.T) {
 	F := 1000 * freq.Hertz
 	for N := 8; N <= 512; N *= 2 {
@@ -103,7 +134,7 @@ func ExampleT() {
 	tr.Inv(w)
 }
 
-func ExampleT_Spike() {
+func ExampleT_spike() {
 	var d = []complex128{
 		0i, 0i, 0i, 0i, 0i, 0i, 0i, (1 + 0i),
 		0i, 0i, 0i, 0i, 0i, 0i, 0i, 0i}
@@ -121,6 +152,22 @@ func ExampleT_Spike() {
 
 }
 
+func BenchmarkT_DoRader1009(b *testing.B) {
+	benchmarkT(1009, b)
+}
+
+func BenchmarkT_DoBS1009(b *testing.B) {
+	benchmarkBS(1009, b)
+}
+
+func BenchmarkT_DoRader4201(b *testing.B) {
+	benchmarkT(4201, b)
+}
+
+func BenchmarkT_DoBS4201(b *testing.B) {
+	benchmarkBS(4201, b)
+}
+
 func BenchmarkDoR2(b *testing.B) {
 	b.StopTimer()
 	w := generate(1024*freq.Hertz, 1024)
@@ -228,3 +275,48 @@ func cmplxCmpErr(a, b complex128, eps float64, t *testing.T) error {
 	}
 	return e
 }
+
+// naive computes the DFT of d directly from the definition, scaled
+// as T does by default.
+func naive(d []complex128, inv bool) []complex128 {
+	N := len(d)
+	res := make([]complex128, N)
+	sg := -1.0
+	if inv {
+		sg = 1.0
+	}
+	sc := complex(1/math.Sqrt(float64(N)), 0)
+	for k := range res {
+		c := 0i
+		for j, v := range d {
+			a := sg * 2 * math.Pi * float64((j*k)%N) / float64(N)
+			c += v * complex(math.Cos(a), math.Sin(a))
+		}
+		res[k] = c * sc
+	}
+	return res
+}
+
+func testNaive(N int, t *testing.T) {
+	d := make([]complex128, N)
+	for i := range d {
+		d[i] = complex(rand.Float64()-0.5, rand.Float64()-0.5)
+	}
+	ft := New(N)
+	w := ft.Win(d)
+	exp := naive(d, false)
+	ft.Do(w)
+	for i := range w {
+		if e := cmplxCmpErr(w[i], exp[i], 1e-9, nil); e != nil {
+			t.Errorf("N=%d fwd at %d: %s", N, i, e)
+		}
+	}
+	copy(w, d)
+	exp = naive(d, true)
+	ft.Inv(w)
+	for i := range w {
+		if e := cmplxCmpErr(w[i], exp[i], 1e-9, nil); e != nil {
+			t.Errorf("N=%d inv at %d: %s", N, i, e)
+		}
+	}
+}
@@ -46,6 +46,25 @@ func newTwiddles(n int, inv bool) *twiddles {
 	return res
 }
 
+// sharedTwiddles is like newTwiddles, but the tables are shared via the
+// cache with other twiddles of size n in either direction.
+func sharedTwiddles(n int, inv bool) *twiddles {
+	tbl := cached(cacheKey{kind: ckTrig, n: n}, func() (interface{}, int) {
+		t := newTwiddles(n, false)
+		return t.cosTbl[:2*n], 16 * n
+	}).([]float64)
+	res := &twiddles{
+		cosTbl: tbl[:n],
+		sinTbl: tbl[n:],
+		twoPi:  n,
+		inv:    inv,
+		invSin: -1.0}
+	if inv {
+		res.invSin = 1.0
+	}
+	return res
+}
+
 func (t *twiddles) sincos(i int) (float64, float64) {
 	return t.sin(i), t.cos(i)
 }
@@ -0,0 +1,68 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package fft
+
+import "math"
+
+// twiddles32 is like twiddles but for complex64 transforms.  The table is
+// computed in float64 and rounded, so each entry is within half an ulp of
+// float32.
+type twiddles32 struct {
+	cosTbl []float32
+	sinTbl []float32
+	twoPi  int
+	inv    bool
+	invSin float32
+}
+
+func newTwiddles32(n int, inv bool) *twiddles32 {
+	tbl := make([]float32, 2*n)
+	res := &twiddles32{
+		cosTbl: tbl[:n],
+		sinTbl: tbl[n:],
+		twoPi:  n,
+		inv:    inv,
+		invSin: -1.0}
+	if inv {
+		res.invSin = 1.0
+	}
+	w := 2.0 * math.Pi / float64(n)
+	for i := 0; i < n; i++ {
+		s, c := math.Sincos(float64(i) * w)
+		res.cosTbl[i] = float32(c)
+		res.sinTbl[i] = float32(s)
+	}
+	return res
+}
+
+// sharedTwiddles32 is like sharedTwiddles for twiddles32.
+func sharedTwiddles32(n int, inv bool) *twiddles32 {
+	tbl := cached(cacheKey{kind: ckTrig32, n: n}, func() (interface{}, int) {
+		t := newTwiddles32(n, false)
+		return t.cosTbl[:2*n], 8 * n
+	}).([]float32)
+	res := &twiddles32{
+		cosTbl: tbl[:n],
+		sinTbl: tbl[n:],
+		twoPi:  n,
+		inv:    inv,
+		invSin: -1.0}
+	if inv {
+		res.invSin = 1.0
+	}
+	return res
+}
+
+func (t *twiddles32) cmplx(i int) complex64 {
+	j := i % t.twoPi
+	return complex(t.cosTbl[j], t.invSin*t.sinTbl[j])
+}
+
+func (t *twiddles32) sin(i int) float32 {
+	return t.invSin * t.sinTbl[i%t.twoPi]
+}
+
+func (t *twiddles32) cos(i int) float32 {
+	return t.cosTbl[i%t.twoPi]
+}
@@ -0,0 +1,37 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+// Package fir provides finite impulse response (feedforward) filter design.
+//
+// Package fir is part of http://zikichombo.org
+//
+// The window method designs linear phase filters by windowing the impulse
+// response of an ideal filter whose gain is constant between band edges.
+// LowPass, HighPass, BandPass, BandStop and Multiband take a number of taps
+// and a window function from package wfn, such as wfn.Hamming or
+// wfn.Kaiser.  KaiserOrd estimates the number of taps and the Kaiser window
+// parameter needed for a given pass band ripple, stop band attenuation and
+// transition width.
+//
+// Remez designs optimal equiripple filters by the Parks-McClellan
+// algorithm, minimising the maximum weighted deviation from desired gains
+// in bands separated by unconstrained transition bands.  Besides filters
+// with constant gains in bands, it designs differentiators and Hilbert
+// transformers, with symmetric or antisymmetric taps and so any of the four
+// types of linear phase filter.  The result includes the deviation,
+// number of iterations and extremal frequencies, and a design that fails
+// to converge gives an error.
+//
+// LeastSquares designs linear phase filters minimising the weighted
+// squared error over bands instead, with a desired gain varying linearly
+// across each band, which can follow a measured curve piecewise.
+// FreqSample designs a filter from an arbitrary target response, such as
+// the inverse of a measured response, by inverse transform and windowing,
+// which gives correction filters.
+//
+// The resulting taps are an impulse response, which may be used directly
+// as the kernel of convol.K or convol.Ola.  T filters a sound.Source with
+// taps as a stream, in direct form for short filters and by block
+// convolution for long ones, giving the tail of the convolution at the
+// end of its source.
+package fir
@@ -0,0 +1,55 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package fir
+
+import (
+	"fmt"
+
+	"github.com/zikichombo/dsp/fft"
+	"github.com/zikichombo/dsp/wfn"
+)
+
+// FreqSample returns n taps of a filter whose response approximates the
+// target response s delayed by d samples, designed by frequency sampling.
+// The magnitudes of s are gains, as for a response from package freqz,
+// and the bins of s are equally spaced around the unit circle, so s may
+// have both magnitude and phase, as measured for example.
+//
+// The taps are the inverse transform of s, taken as periodic, from -d to
+// n-d-1 and multiplied by the window function win.  A nil win is the
+// rectangular window.  For a zero phase s, d = (n-1)/2 gives a linear
+// phase filter.  For a causal s, such as a minimum phase response, d = 0
+// keeps the start of its impulse response, and win should not attenuate
+// the first taps.  s.N() should be large enough that the impulse response
+// of s decays within s.N() samples.
+//
+// Only the real part of the inverse transform is used, which is that of
+// the conjugate symmetric part of s.
+//
+// FreqSample returns a non-nil error if n < 1 or n > s.N() or if d is not
+// in [0, n).
+func FreqSample(s *fft.S, n, d int, win func(float64) float64) ([]float64, error) {
+	m := s.N()
+	if n < 1 || n > m {
+		return nil, fmt.Errorf("invalid number of taps %d for %d bins", n, m)
+	}
+	if d < 0 || d >= n {
+		return nil, fmt.Errorf("invalid delay %d for %d taps", d, n)
+	}
+	ft := fft.New(m)
+	ft.Scale(false)
+	h := s.Rect(ft.Win(nil))
+	if e := ft.Inv(h); e != nil {
+		return nil, e
+	}
+	res := make([]float64, n)
+	for i := range res {
+		j := (i - d + m) % m
+		res[i] = real(h[j]) / float64(m)
+	}
+	if win != nil && n > 1 {
+		wfn.New(win, n).Apply(res)
+	}
+	return res, nil
+}
@@ -0,0 +1,68 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package fir
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+
+	"github.com/zikichombo/dsp/fft"
+	"github.com/zikichombo/dsp/wfn"
+)
+
+func TestFreqSample(t *testing.T) {
+	m := 64
+	g := []float64{0.5, -0.25, 0.125, 1, 0.3, -0.1, 0.05}
+	spec := make([]complex128, m)
+	for k := range spec {
+		w := 2 * math.Pi * float64(k) / float64(m)
+		for i, v := range g {
+			spec[k] += complex(v, 0) * cmplx.Rect(1, -w*float64(i))
+		}
+	}
+	// a causal target is recovered exactly.
+	h, e := FreqSample(fft.NewS(spec), 10, 0, nil)
+	if e != nil {
+		t.Fatal(e)
+	}
+	for i, v := range h {
+		exp := 0.0
+		if i < len(g) {
+			exp = g[i]
+		}
+		if math.Abs(v-exp) > 1e-12 {
+			t.Errorf("got tap %f not %f at %d", v, exp, i)
+		}
+	}
+
+	// a linear phase correction for a measured first order low pass
+	// response.
+	m = 1024
+	a := 0.6
+	inv := make([]complex128, m)
+	for k := range inv {
+		z := cmplx.Rect(1, -2*math.Pi*float64(k)/float64(m))
+		inv[k] = complex(cmplx.Abs((1-complex(a, 0)*z)/complex(1-a, 0)), 0)
+	}
+	n := 127
+	h, e = FreqSample(fft.NewS(inv), n, (n-1)/2, wfn.Hann)
+	if e != nil {
+		t.Fatal(e)
+	}
+	symmetric(t, h)
+	for f := 0.0; f < 20000; f += 100 {
+		z := cmplx.Rect(1, -2*math.Pi*f/sr.Float64())
+		dev := cmplx.Abs(complex(1-a, 0) / (1 - complex(a, 0)*z))
+		if c := gain(h, f) * dev; math.Abs(c-1) > 0.01 {
+			t.Errorf("got corrected gain %f at %f", c, f)
+		}
+	}
+	if _, e := FreqSample(fft.NewS(inv), m+1, 0, nil); e == nil {
+		t.Errorf("expected taps error")
+	}
+	if _, e := FreqSample(fft.NewS(inv), 10, 10, nil); e == nil {
+		t.Errorf("expected delay error")
+	}
+}
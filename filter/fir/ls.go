@@ -0,0 +1,151 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package fir
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/zikichombo/sound/freq"
+)
+
+// LSBand is a frequency band of a LeastSquares design, in which the
+// desired gain is linear in frequency.
+type LSBand struct {
+	// Lo and Hi are the edges of the band.
+	Lo, Hi freq.T
+	// LoGain and HiGain are the desired gains at Lo and Hi.
+	LoGain, HiGain float64
+	// Weight is the relative weight of errors in the band, 1 if 0.
+	Weight float64
+}
+
+// LeastSquares returns n symmetric taps of the linear phase filter at
+// sample rate sr which minimises the integral over the bands of the
+// weighted squared error between its gain and the desired gains.  The
+// gain in the transition bands between the bands is not constrained.
+// Filters with an even number of taps have gain 0 at the Nyquist
+// frequency.
+//
+// LeastSquares returns a non-nil error if n < 1, if the bands are not
+// ascending, non-overlapping and between 0 and the Nyquist frequency, if a
+// weight is negative, if n is even and the last band ends at the Nyquist
+// frequency with a non-zero gain, or if the bands do not determine the
+// taps.
+func LeastSquares(n int, bands []LSBand, sr freq.T) ([]float64, error) {
+	if n < 1 {
+		return nil, fmt.Errorf("invalid number of taps %d", n)
+	}
+	if len(bands) == 0 {
+		return nil, fmt.Errorf("no bands")
+	}
+	for i, b := range bands {
+		if b.Lo < 0 || b.Hi <= b.Lo || 2*b.Hi > sr {
+			return nil, fmt.Errorf("invalid band %s..%s", b.Lo, b.Hi)
+		}
+		if i > 0 && b.Lo < bands[i-1].Hi {
+			return nil, fmt.Errorf("bands %s..%s %s..%s overlap", bands[i-1].Lo, bands[i-1].Hi, b.Lo, b.Hi)
+		}
+		if b.Weight < 0 {
+			return nil, fmt.Errorf("negative weight %f", b.Weight)
+		}
+	}
+	if b := bands[len(bands)-1]; n%2 == 0 && 2*b.Hi == sr && b.HiGain != 0 {
+		return nil, fmt.Errorf("even number of taps %d with gain at Nyquist", n)
+	}
+	// the gain is sum c[i] cos(m[i] w), with m[i] the distance of tap i
+	// from the center.
+	nh := (n + 1) / 2
+	m := make([]float64, nh)
+	for i := range m {
+		m[i] = float64(n-1)/2 - float64(i)
+	}
+	q := make([][]float64, nh)
+	c := make([]float64, nh)
+	for i := range q {
+		q[i] = make([]float64, nh)
+	}
+	for _, b := range bands {
+		w := b.Weight
+		if w == 0 {
+			w = 1
+		}
+		lo := 2 * math.Pi * b.Lo.Float64() / sr.Float64()
+		hi := 2 * math.Pi * b.Hi.Float64() / sr.Float64()
+		// the desired gain is g + s w.
+		s := (b.HiGain - b.LoGain) / (hi - lo)
+		g := b.LoGain - s*lo
+		for i := range q {
+			for j := range q[i] {
+				q[i][j] += w * (cosInt(m[i]-m[j], lo, hi) + cosInt(m[i]+m[j], lo, hi)) / 2
+			}
+			c[i] += w * (g*cosInt(m[i], lo, hi) + s*wCosInt(m[i], lo, hi))
+		}
+	}
+	if e := solve(q, c); e != nil {
+		return nil, e
+	}
+	res := make([]float64, n)
+	for i, v := range c {
+		if m[i] != 0 {
+			v /= 2
+		}
+		res[i] = v
+		res[n-1-i] = v
+	}
+	return res, nil
+}
+
+// cosInt returns the integral of cos(m w) for w from lo to hi.
+func cosInt(m, lo, hi float64) float64 {
+	if m == 0 {
+		return hi - lo
+	}
+	return (math.Sin(m*hi) - math.Sin(m*lo)) / m
+}
+
+// wCosInt returns the integral of w cos(m w) for w from lo to hi.
+func wCosInt(m, lo, hi float64) float64 {
+	if m == 0 {
+		return (hi*hi - lo*lo) / 2
+	}
+	f := func(w float64) float64 {
+		return w*math.Sin(m*w)/m + math.Cos(m*w)/(m*m)
+	}
+	return f(hi) - f(lo)
+}
+
+// solve solves the linear system a x = b by Gaussian elimination with
+// partial pivoting, placing x in b.
+func solve(a [][]float64, b []float64) error {
+	n := len(b)
+	for k := 0; k < n; k++ {
+		p := k
+		for i := k + 1; i < n; i++ {
+			if math.Abs(a[i][k]) > math.Abs(a[p][k]) {
+				p = i
+			}
+		}
+		if math.Abs(a[p][k]) <= 1e-13*math.Abs(a[0][0]) {
+			return fmt.Errorf("singular system at %d", k)
+		}
+		a[k], a[p] = a[p], a[k]
+		b[k], b[p] = b[p], b[k]
+		for i := k + 1; i < n; i++ {
+			f := a[i][k] / a[k][k]
+			for j := k; j < n; j++ {
+				a[i][j] -= f * a[k][j]
+			}
+			b[i] -= f * b[k]
+		}
+	}
+	for k := n - 1; k >= 0; k-- {
+		s := b[k]
+		for j := k + 1; j < n; j++ {
+			s -= a[k][j] * b[j]
+		}
+		b[k] = s / a[k][k]
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package fir
+
+import (
+	"math"
+	"testing"
+
+	"github.com/zikichombo/sound/freq"
+)
+
+func TestLeastSquares(t *testing.T) {
+	fc := 6000 * freq.Hertz
+	// without transition bands, the least squares filter is the
+	// truncated ideal filter.
+	for _, n := range []int{31, 32} {
+		h, e := LeastSquares(n, []LSBand{{0, fc, 1, 1, 1}, {fc, sr / 2, 0, 0, 1}}, sr)
+		if e != nil {
+			t.Fatal(e)
+		}
+		for i, v := range h {
+			exp := ideal(fc.Float64()/sr.Float64(), float64(i)-float64(n-1)/2)
+			if math.Abs(v-exp) > 1e-9 {
+				t.Errorf("%d: got tap %f not %f at %d", n, v, exp, i)
+			}
+		}
+	}
+	// weighting the stop band more gives more attenuation.
+	var last float64
+	for _, w := range []float64{1, 10, 100} {
+		h, e := LeastSquares(61, []LSBand{{0, 4000 * freq.Hertz, 1, 1, 1}, {6000 * freq.Hertz, sr / 2, 0, 0, w}}, sr)
+		if e != nil {
+			t.Fatal(e)
+		}
+		symmetric(t, h)
+		if g := gain(h, 0); math.Abs(g-1) > 0.01 {
+			t.Errorf("weight %f: got gain %f at 0", w, g)
+		}
+		s := 0.0
+		for f := 6000.0; f <= 24000; f += 10 {
+			s += gain(h, f) * gain(h, f)
+		}
+		if w > 1 && s >= last {
+			t.Errorf("weight %f: got stop band energy %g not less than %g", w, s, last)
+		}
+		last = s
+	}
+	if _, e := LeastSquares(0, []LSBand{{0, fc, 1, 1, 1}}, sr); e == nil {
+		t.Errorf("expected taps error")
+	}
+	if _, e := LeastSquares(11, []LSBand{{fc, fc / 2, 1, 1, 1}}, sr); e == nil {
+		t.Errorf("expected band error")
+	}
+	if _, e := LeastSquares(12, []LSBand{{0, fc, 0, 0, 1}, {2 * fc, sr / 2, 1, 1, 1}}, sr); e == nil {
+		t.Errorf("expected Nyquist gain error")
+	}
+}
+
+func TestLeastSquaresLinear(t *testing.T) {
+	// a gain rising linearly from 0 to 1 up to 20kHz, then falling to 0 at
+	// 22kHz.
+	bs := []LSBand{
+		{0, 20000 * freq.Hertz, 0, 1, 1},
+		{20000 * freq.Hertz, 22000 * freq.Hertz, 1, 0, 1}}
+	for _, n := range []int{61, 62} {
+		h, e := LeastSquares(n, bs, sr)
+		if e != nil {
+			t.Fatal(e)
+		}
+		symmetric(t, h)
+		for f := 500.0; f <= 18000; f += 100 {
+			if g := gain(h, f); math.Abs(g-f/20000) > 0.01 {
+				t.Errorf("%d: got gain %f not %f at %f", n, g, f/20000, f)
+			}
+		}
+	}
+}
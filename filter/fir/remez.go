@@ -0,0 +1,691 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package fir
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/zikichombo/sound/freq"
+)
+
+// Kind is the kind of linear phase filter designed by Remez.
+type Kind int
+
+const (
+	// Piecewise filters have symmetric taps and a constant desired gain
+	// in each band, giving type I filters for odd numbers of taps and
+	// type II filters, with gain 0 at the Nyquist frequency, for even
+	// numbers of taps.
+	Piecewise Kind = iota
+	// Differentiator filters have antisymmetric taps and desired gain
+	// proportional to frequency, with errors weighted relative to the
+	// desired gain.
+	Differentiator
+	// Hilbert filters, or Hilbert transformers, have antisymmetric taps
+	// and a constant desired gain in each band.
+	Hilbert
+)
+
+// String implements Stringer.
+func (k Kind) String() string {
+	switch k {
+	case Piecewise:
+		return "piecewise"
+	case Differentiator:
+		return "differentiator"
+	case Hilbert:
+		return "hilbert"
+	}
+	return fmt.Sprintf("Kind(%d)", int(k))
+}
+
+// Band is a frequency band of a Remez design.
+type Band struct {
+	// Lo and Hi are the edges of the band.
+	Lo, Hi freq.T
+	// Gain is the desired gain in the band.  For Differentiator filters
+	// the desired gain is Gain times the frequency in radians per sample,
+	// so that Gain 1 gives the derivative.
+	Gain float64
+	// Weight is the relative weight of errors in the band, 1 if 0.
+	Weight float64
+}
+
+// RemezOpts holds optional parameters of Remez.  Zero fields are replaced
+// by defaults.
+type RemezOpts struct {
+	// Kind is the kind of filter, Piecewise by default.
+	Kind Kind
+	// Density is the number of grid frequencies per coefficient, 16 by
+	// default.
+	Density int
+	// MaxIter is the maximum number of exchange iterations, 40 by
+	// default.
+	MaxIter int
+}
+
+// Equiripple is the result of a Remez design.
+type Equiripple struct {
+	// Taps holds the filter taps.
+	Taps []float64
+	// Dev is the maximum weighted deviation from the desired gains.
+	Dev float64
+	// Iter is the number of iterations of the exchange.
+	Iter int
+	// Ext holds the extremal frequencies of the error.
+	Ext []freq.T
+}
+
+// Remez returns an optimal equiripple linear phase filter with n taps at
+// sample rate sr, designed by the Parks-McClellan algorithm, which uses the
+// Remez exchange to minimise the maximum weighted deviation from the
+// desired gains in bands.  Frequencies outside the bands are transition
+// bands, in which the gain is not constrained.  A nil o gives default
+// options.
+//
+// Piecewise filters with an even number of taps have gain 0 at the Nyquist
+// frequency.  Differentiator and Hilbert filters have gain 0 at 0, and
+// also at the Nyquist frequency if their number of taps is odd.
+//
+// Remez returns a non-nil error if n < 3, if the bands are not ascending,
+// non-overlapping and between 0 and the Nyquist frequency, if a weight is
+// negative, or if the exchange fails to converge, in which case narrower
+// transition bands, a higher grid density or fewer taps may help.
+func Remez(n int, bands []Band, sr freq.T, o *RemezOpts) (*Equiripple, error) {
+	opts := RemezOpts{Density: 16, MaxIter: 40}
+	if o != nil {
+		opts.Kind = o.Kind
+		if o.Density != 0 {
+			opts.Density = o.Density
+		}
+		if o.MaxIter != 0 {
+			opts.MaxIter = o.MaxIter
+		}
+	}
+	if n < 3 {
+		return nil, fmt.Errorf("invalid number of taps %d", n)
+	}
+	if opts.Kind < Piecewise || opts.Kind > Hilbert {
+		return nil, fmt.Errorf("invalid kind %s", opts.Kind)
+	}
+	if opts.Density < 1 || opts.MaxIter < 1 {
+		return nil, fmt.Errorf("invalid density %d or iterations %d", opts.Density, opts.MaxIter)
+	}
+	if len(bands) == 0 {
+		return nil, fmt.Errorf("no bands")
+	}
+	bs := make([]rband, len(bands))
+	for i, b := range bands {
+		if b.Lo < 0 || b.Hi <= b.Lo || 2*b.Hi > sr {
+			return nil, fmt.Errorf("invalid band %s..%s", b.Lo, b.Hi)
+		}
+		if i > 0 && b.Lo < bands[i-1].Hi {
+			return nil, fmt.Errorf("bands %s..%s %s..%s overlap", bands[i-1].Lo, bands[i-1].Hi, b.Lo, b.Hi)
+		}
+		if b.Weight < 0 {
+			return nil, fmt.Errorf("negative weight %f", b.Weight)
+		}
+		rb := rband{
+			lo:     b.Lo.Float64() / sr.Float64(),
+			hi:     b.Hi.Float64() / sr.Float64(),
+			gain:   b.Gain,
+			weight: b.Weight}
+		if opts.Kind == Differentiator {
+			rb.gain *= 2 * math.Pi
+		}
+		if rb.weight == 0 {
+			rb.weight = 1
+		}
+		bs[i] = rb
+	}
+	return mpr(n, bs, opts, sr)
+}
+
+// rband is a Band with frequencies in cycles per sample, a gain including
+// the factor 2*pi of differentiators and a non-zero weight.
+type rband struct {
+	lo, hi, gain, weight float64
+}
+
+// des returns the desired gain of b at f cycles per sample.
+func (b rband) des(f float64, k Kind) float64 {
+	if k == Differentiator {
+		return b.gain * f
+	}
+	return b.gain
+}
+
+// wt returns the weight of b at f cycles per sample.
+func (b rband) wt(f float64, k Kind) float64 {
+	if k == Differentiator && b.gain >= 0.0001 {
+		return b.weight / f
+	}
+	return b.weight
+}
+
+// mpr implements the design of McClellan, Parks and Rabiner, "A Computer
+// Program for Designing Optimum FIR Linear Phase Digital Filters", IEEE
+// Transactions on Audio and Electroacoustics, 1973.
+//
+// The response of a linear phase filter with n taps is a fixed factor
+// times a cosine polynomial of nc terms, so the design reduces to the
+// best weighted approximation by a cosine polynomial on a dense grid of
+// frequencies, found by the Remez exchange.
+func mpr(n int, bs []rband, o RemezOpts, sr freq.T) (*Equiripple, error) {
+	sym := o.Kind == Piecewise
+	odd := n%2 == 1
+	nc := n / 2
+	if odd && sym {
+		nc++
+	}
+	if nc < 2 {
+		return nil, fmt.Errorf("too few taps %d for %s filter", n, o.Kind)
+	}
+
+	// the dense grid and the desired gain and weight on it.  Antisymmetric
+	// filters have gain 0 at 0, and so do type II and IV filters at the
+	// Nyquist frequency, so the grid excludes those.
+	delf := 0.5 / float64(o.Density*nc)
+	var grid, des, wt []float64
+	for _, b := range bs {
+		lo := b.lo
+		if !sym && lo < delf {
+			lo = delf
+		}
+		// the last point before the edge is at least delf/2 from it.
+		for i := 0; lo+float64(i)*delf < b.hi-delf/2; i++ {
+			f := lo + float64(i)*delf
+			grid = append(grid, f)
+			des = append(des, b.des(f, o.Kind))
+			wt = append(wt, b.wt(f, o.Kind))
+		}
+		grid = append(grid, b.hi)
+		des = append(des, b.des(b.hi, o.Kind))
+		wt = append(wt, b.wt(b.hi, o.Kind))
+	}
+	ng := len(grid)
+	if sym != odd && grid[ng-1] > 0.5-delf {
+		ng--
+	}
+	if ng <= nc {
+		return nil, fmt.Errorf("bands too narrow for %d taps", n)
+	}
+	grid, des, wt = grid[:ng], des[:ng], wt[:ng]
+
+	// the equivalent problem of approximation by a cosine polynomial: the
+	// response is c(f) times the polynomial, so the desired gain is divided
+	// and the weight multiplied by c(f).
+	if !(sym && odd) {
+		for i, f := range grid {
+			var c float64
+			switch {
+			case sym:
+				c = math.Cos(math.Pi * f)
+			case !odd:
+				c = math.Sin(math.Pi * f)
+			default:
+				c = math.Sin(2 * math.Pi * f)
+			}
+			des[i] /= c
+			wt[i] *= c
+		}
+	}
+
+	x := newExchange(grid, des, wt, nc)
+	iter, e := x.run(o.MaxIter)
+	if e != nil {
+		return nil, e
+	}
+	res := &Equiripple{Dev: x.dev, Iter: iter}
+	for _, k := range x.ext {
+		res.Ext = append(res.Ext, freq.T(math.Floor(grid[k]*float64(sr)+0.5)))
+	}
+	full := bs[0].lo == 0 && bs[len(bs)-1].hi == 0.5
+	res.Taps = taps(x.coefs(full), n, sym)
+	for _, v := range res.Taps {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, fmt.Errorf("design failed after %d iterations", iter)
+		}
+	}
+	return res, nil
+}
+
+// taps returns the n taps of the linear phase filter whose response is
+// given by the cosine polynomial with coefficients a, symmetric or not.
+func taps(a []float64, n int, sym bool) []float64 {
+	nc := len(a) - 2
+	h := make([]float64, n)
+	switch {
+	case sym && n%2 == 1:
+		for i := 0; i < nc-1; i++ {
+			h[i] = 0.5 * a[nc-1-i]
+		}
+		h[nc-1] = a[0]
+	case sym:
+		h[0] = 0.25 * a[nc-1]
+		for i := 1; i < nc-1; i++ {
+			h[i] = 0.25 * (a[nc-1-i] + a[nc-i])
+		}
+		h[nc-1] = 0.5*a[0] + 0.25*a[1]
+	case n%2 == 1:
+		h[0] = 0.25 * a[nc-1]
+		h[1] = 0.25 * a[nc-2]
+		for i := 2; i < nc-1; i++ {
+			h[i] = 0.25 * (a[nc-1-i] - a[nc+1-i])
+		}
+		h[nc-1] = 0.5*a[0] - 0.25*a[2]
+	default:
+		h[0] = 0.25 * a[nc-1]
+		for i := 1; i < nc-1; i++ {
+			h[i] = 0.25 * (a[nc-1-i] - a[nc-i])
+		}
+		h[nc-1] = 0.5*a[0] - 0.25*a[1]
+	}
+	for i := 0; i < nc; i++ {
+		if sym {
+			h[n-1-i] = h[i]
+		} else {
+			h[n-1-i] = -h[i]
+		}
+	}
+	if !sym && n%2 == 1 {
+		h[nc] = 0
+	}
+	return h
+}
+
+// exchange holds the state of the Remez exchange approximating the desired
+// gains des with weights wt on the grid of frequencies grid, in cycles per
+// sample, by a cosine polynomial of nc terms.
+//
+// The best approximation has nc+1 extremal frequencies at which the
+// weighted error has equal magnitude and alternating sign.  Each iteration
+// interpolates the polynomial with that property on the current extremal
+// frequencies, then moves them to the local extrema of its error, until
+// they no longer move.
+type exchange struct {
+	grid, des, wt []float64
+	ext           []int     // indices of the extremal frequencies in grid
+	x             []float64 // cos(2*pi*f) of the extremal frequencies
+	y             []float64 // the polynomial at x
+	ad            []float64 // barycentric weights of x
+	dev           float64   // deviation of the polynomial at x
+	klow          int       // lower bound of the search for an extremum
+	nchg          int       // number of extrema moved in the iteration
+}
+
+// newExchange returns an exchange with extremal frequencies spread evenly
+// over the grid.
+func newExchange(grid, des, wt []float64, nc int) *exchange {
+	r := nc + 1
+	x := &exchange{
+		grid: grid,
+		des:  des,
+		wt:   wt,
+		ext:  make([]int, r),
+		x:    make([]float64, r),
+		y:    make([]float64, r),
+		ad:   make([]float64, r)}
+	step := float64(len(grid)-1) / float64(nc)
+	for i := 0; i < nc; i++ {
+		x.ext[i] = int(float64(i) * step)
+	}
+	x.ext[nc] = len(grid) - 1
+	return x
+}
+
+// run performs the exchange for at most maxIter iterations, returning the
+// number of iterations performed.
+func (x *exchange) run(maxIter int) (int, error) {
+	devl := -1.0
+	for it := 1; ; it++ {
+		if it > maxIter {
+			return it - 1, fmt.Errorf("no convergence after %d iterations, deviation %g", maxIter, x.dev)
+		}
+		s := x.interp()
+		if x.dev <= devl {
+			// the deviation must increase at each iteration.
+			return it, fmt.Errorf("failure to converge at iteration %d, deviation %g", it, x.dev)
+		}
+		devl = x.dev
+		if !x.move(s) {
+			return it, nil
+		}
+	}
+}
+
+// interp computes the polynomial whose weighted error at the extremal
+// frequencies has equal magnitude x.dev and alternating sign, and returns
+// the sign of the error at the first one.
+func (x *exchange) interp() int {
+	r := len(x.ext)
+	for i, k := range x.ext {
+		x.x[i] = math.Cos(2 * math.Pi * x.grid[k])
+	}
+	m := (r-2)/15 + 1
+	for i := range x.ad {
+		x.ad[i] = bary(i, x.x, m)
+	}
+	num, den := 0.0, 0.0
+	sgn := 1.0
+	for i, k := range x.ext {
+		num += x.ad[i] * x.des[k]
+		den += sgn * x.ad[i] / x.wt[k]
+		sgn = -sgn
+	}
+	dev := num / den
+	s := 1
+	if dev > 0 {
+		s = -1
+	}
+	x.dev = -float64(s) * dev
+	sgn = float64(s)
+	for i, k := range x.ext {
+		x.y[i] = x.des[k] + sgn*x.dev/x.wt[k]
+		sgn = -sgn
+	}
+	return s
+}
+
+// serr returns the weighted error of the polynomial at grid index l times
+// the sign s.
+func (x *exchange) serr(l, s int) float64 {
+	g := lagrange(math.Cos(2*math.Pi*x.grid[l]), x.x, x.y, x.ad)
+	return float64(s) * ((g - x.des[l]) * x.wt[l])
+}
+
+// move moves the extremal frequencies to the local extrema of the error,
+// where the sign of the error at the first one is s, and reports whether
+// any moved.
+func (x *exchange) move(s int) bool {
+	r := len(x.ext)
+	lo, hi := x.ext[0], x.ext[r-1]
+	x.klow, x.nchg = -1, 0
+	var first, last float64
+	sl := s // the sign of the error at extremum i
+	for i := 0; i < r; i++ {
+		kup := len(x.grid)
+		if i+1 < r {
+			kup = x.ext[i+1]
+		}
+		sl = s
+		if i%2 == 1 {
+			sl = -s
+		}
+		v := x.moveExt(i, sl, kup)
+		if i == 0 {
+			first = v
+		}
+		last = v
+	}
+
+	// an extremum beyond either end may replace the one at the other end,
+	// if it exceeds that one.
+	if x.ext[0] < lo {
+		lo = x.ext[0]
+	}
+	if x.ext[r-1] > hi {
+		hi = x.ext[r-1]
+	}
+	k, v := x.below(lo, -s, 1.00001*last)
+	if k >= 0 && v > first {
+		first = v
+	}
+	if kh, _ := x.above(hi, -sl, 1.00001*first); kh >= 0 {
+		// drop the first extremum for one after the last.
+		copy(x.ext, x.ext[1:])
+		x.ext[r-1] = kh
+	} else if k >= 0 {
+		// drop the last extremum for one before the first.
+		copy(x.ext[1:], x.ext[:r-1])
+		x.ext[0] = k
+	}
+	return x.nchg > 0
+}
+
+// moveExt moves extremum i, where the error has sign s, to a local extremum
+// of the error exceeding the deviation between x.klow and kup exclusive,
+// and returns the magnitude of the error there.  Above the current
+// extremum, the nearest such extremum is taken.  Below it, the search only
+// continues beyond the first grid frequency if no extremum moved yet.
+func (x *exchange) moveExt(i, s, kup int) float64 {
+	comp := x.dev
+	k := x.ext[i]
+	if k+1 < kup {
+		if e := x.serr(k+1, s); e > comp {
+			l, e := x.climbUp(k+1, kup, s, e)
+			return x.setExt(i, l, e)
+		}
+	}
+	for l := k - 1; l > x.klow; l-- {
+		if e := x.serr(l, s); e > comp {
+			l, e = x.climbDown(l, x.klow, s, e)
+			x.klow = k
+			x.ext[i] = l
+			x.nchg++
+			return e
+		}
+		if x.nchg > 0 {
+			x.klow = k
+			return comp
+		}
+	}
+	if x.nchg > 0 {
+		return x.setExt(i, k, comp)
+	}
+	for l := k + 2; l < kup; l++ {
+		if e := x.serr(l, s); e > comp {
+			l, e = x.climbUp(l, kup, s, e)
+			return x.setExt(i, l, e)
+		}
+	}
+	x.klow = k
+	return comp
+}
+
+// setExt places extremum i at grid index k, with error magnitude e, which
+// it returns.
+func (x *exchange) setExt(i, k int, e float64) float64 {
+	x.ext[i] = k
+	x.klow = k
+	x.nchg++
+	return e
+}
+
+// below returns the index of a local extremum of the error with sign s
+// exceeding comp below grid index hi, and the magnitude of the error there.
+// If there is none, below returns -1.
+func (x *exchange) below(hi, s int, comp float64) (int, float64) {
+	for l := 0; l < hi; l++ {
+		if e := x.serr(l, s); e > comp {
+			x.nchg++
+			return x.climbUp(l, hi, s, e)
+		}
+	}
+	return -1, 0
+}
+
+// above is like below for grid indices above lo, searching down from the
+// top of the grid.
+func (x *exchange) above(lo, s int, comp float64) (int, float64) {
+	for l := len(x.grid) - 1; l > lo; l-- {
+		if e := x.serr(l, s); e > comp {
+			x.nchg++
+			return x.climbDown(l, lo, s, e)
+		}
+	}
+	return -1, 0
+}
+
+// climbUp returns the grid index below hi of the local maximum of the
+// signed error s*err reached by ascending from l, where it is e, and its
+// value.
+func (x *exchange) climbUp(l, hi, s int, e float64) (int, float64) {
+	for l+1 < hi {
+		f := x.serr(l+1, s)
+		if f <= e {
+			break
+		}
+		l, e = l+1, f
+	}
+	return l, e
+}
+
+// climbDown is like climbUp, descending to grid indices above lo.
+func (x *exchange) climbDown(l, lo, s int, e float64) (int, float64) {
+	for l-1 > lo {
+		f := x.serr(l-1, s)
+		if f <= e {
+			break
+		}
+		l, e = l-1, f
+	}
+	return l, e
+}
+
+// coefs returns the coefficients of the cosine polynomial found by the
+// exchange, followed by 2 zeros.  They are computed by an inverse discrete
+// fourier transform of the polynomial sampled at equally spaced
+// frequencies.  Unless full is true, meaning the bands cover all
+// frequencies, the samples are taken over the range of the grid, and the
+// coefficients mapped back to the full range.
+func (x *exchange) coefs(full bool) []float64 {
+	nc := len(x.ext) - 1
+	mapped := !full && nc > 3
+	var aa, bb float64
+	if mapped {
+		c0 := math.Cos(2 * math.Pi * x.grid[0])
+		c1 := math.Cos(2 * math.Pi * x.grid[len(x.grid)-1])
+		aa = 2 / (c0 - c1)
+		bb = -(c0 + c1) / (c0 - c1)
+	}
+	cn := 2*nc - 1
+	df := 1 / float64(cn)
+	a := make([]float64, nc)
+	l := 0
+	for i := range a {
+		f := float64(i) * df
+		xt := math.Cos(2 * math.Pi * f)
+		if mapped {
+			xt = (xt - bb) / aa
+		}
+		a[i], l = x.sample(xt, l)
+	}
+	alpha := make([]float64, nc+2)
+	dw := 2 * math.Pi / float64(cn)
+	for i := 0; i < nc; i++ {
+		w := float64(i) * dw
+		t := 0.0
+		for k := 1; k < nc; k++ {
+			t += a[k] * math.Cos(w*float64(k))
+		}
+		alpha[i] = 2*t + a[0]
+	}
+	for i := 1; i < nc; i++ {
+		alpha[i] *= 2 / float64(cn)
+	}
+	alpha[0] /= float64(cn)
+	if mapped {
+		unmap(alpha[:nc], aa, bb)
+	}
+	return alpha
+}
+
+// sample returns the polynomial at xt, using its value at an extremal
+// frequency within 1e-6 of xt.  The abscissae x.x are descending and l is
+// the index at which to start looking for xt among them.  sample also
+// returns the index at which to start for the next, smaller, xt.
+func (x *exchange) sample(xt float64, l int) (float64, int) {
+	const fsh = 1e-6
+	var v float64
+	for ; ; l++ {
+		if l == len(x.x) {
+			v = lagrange(xt, x.x, x.y, x.ad)
+			break
+		}
+		xe := x.x[l]
+		if xt > xe {
+			if xt-xe < fsh {
+				v = x.y[l]
+			} else {
+				v = lagrange(xt, x.x, x.y, x.ad)
+			}
+			break
+		}
+		if xe-xt < fsh {
+			v = x.y[l]
+			break
+		}
+	}
+	if l > 0 {
+		l--
+	}
+	return v, l
+}
+
+// unmap replaces the coefficients a of a cosine polynomial in the variable
+// aa*x+bb by those of the same polynomial in x, using the Chebyshev
+// recurrence as in the original program.
+func unmap(a []float64, aa, bb float64) {
+	n := len(a)
+	p := make([]float64, n)
+	q := make([]float64, n)
+	t := make([]float64, n)
+	p[0] = 2*a[n-1]*bb + a[n-2]
+	p[1] = 2 * aa * a[n-1]
+	q[0] = a[n-3] - a[n-1]
+	for j := 2; j < n; j++ {
+		if j == n-1 {
+			aa *= 0.5
+			bb *= 0.5
+		}
+		p[j] = 0
+		for k := 0; k < j; k++ {
+			t[k] = p[k]
+			p[k] = 2 * bb * t[k]
+		}
+		p[1] += t[0] * 2 * aa
+		for k := 0; k < j-1; k++ {
+			p[k] += q[k] + aa*t[k+1]
+		}
+		for k := 2; k <= j; k++ {
+			p[k] += aa * t[k-1]
+		}
+		if j != n-1 {
+			for k := 0; k < j; k++ {
+				q[k] = -t[k]
+			}
+			q[0] += a[n-2-j]
+		}
+	}
+	copy(a, p)
+}
+
+// bary returns the k'th barycentric weight of the abscissae x, computed in
+// m interleaved groups to avoid overflow.
+func bary(k int, x []float64, m int) float64 {
+	res := 1.0
+	q := x[k]
+	for l := 0; l < m; l++ {
+		for j := l; j < len(x); j += m {
+			if j != k {
+				res *= 2 * (q - x[j])
+			}
+		}
+	}
+	return 1 / res
+}
+
+// lagrange returns the value at xf of the polynomial interpolating y at the
+// abscissae x with barycentric weights ad.
+func lagrange(xf float64, x, y, ad []float64) float64 {
+	p, d := 0.0, 0.0
+	for j := range x {
+		c := ad[j] / (xf - x[j])
+		d += c
+		p += c * y[j]
+	}
+	return p / d
+}
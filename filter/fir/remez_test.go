@@ -0,0 +1,118 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package fir
+
+import (
+	"math"
+	"testing"
+
+	"github.com/zikichombo/sound/freq"
+)
+
+// maxErr returns the maximum weighted error of the gain of h over bs.
+func maxErr(h []float64, bs []Band, k Kind) float64 {
+	res := 0.0
+	for _, b := range bs {
+		w := b.Weight
+		if w == 0 {
+			w = 1
+		}
+		for f := b.Lo.Float64(); f <= b.Hi.Float64(); f += 5 {
+			des := b.Gain
+			if k == Differentiator {
+				des *= 2 * math.Pi * f / sr.Float64()
+			}
+			res = math.Max(res, w*math.Abs(gain(h, f)-des))
+		}
+	}
+	return res
+}
+
+func TestRemez(t *testing.T) {
+	k := freq.Hertz
+	low := []Band{{0, 4000 * k, 1, 1}, {6000 * k, 24000 * k, 0, 10}}
+	pass := []Band{{0, 3000 * k, 0, 1}, {5000 * k, 9000 * k, 1, 1}, {11000 * k, 24000 * k, 0, 1}}
+	hilbert := []Band{{1000 * k, 23000 * k, 1, 1}}
+	type tc struct {
+		n     int
+		bands []Band
+		kind  Kind
+	}
+	tcs := []tc{
+		{41, low, Piecewise},
+		{40, low, Piecewise},
+		{61, pass, Piecewise},
+		{31, hilbert, Hilbert},
+		{30, hilbert, Hilbert}}
+	for _, c := range tcs {
+		r, e := Remez(c.n, c.bands, sr, &RemezOpts{Kind: c.kind})
+		if e != nil {
+			t.Fatalf("%s %d: %s", c.kind, c.n, e)
+		}
+		h := r.Taps
+		if len(h) != c.n {
+			t.Fatalf("%s %d: got %d taps", c.kind, c.n, len(h))
+		}
+		sgn := 1.0
+		if c.kind != Piecewise {
+			sgn = -1
+		}
+		for i := range h {
+			if math.Abs(h[i]-sgn*h[len(h)-1-i]) > 1e-12 {
+				t.Fatalf("%s %d: taps not symmetric at %d", c.kind, c.n, i)
+			}
+		}
+		// equiripple: the error reaches the deviation, but not much more.
+		if m := maxErr(h, c.bands, c.kind); m > 1.02*r.Dev || m < r.Dev {
+			t.Errorf("%s %d: got error %f with deviation %f", c.kind, c.n, m, r.Dev)
+		}
+		if len(r.Ext) != (c.n+1)/2+1 && len(r.Ext) != c.n/2+1 {
+			t.Errorf("%s %d: got %d extremal frequencies", c.kind, c.n, len(r.Ext))
+		}
+	}
+	// the stop band of the low pass filter is weighted 10 times.
+	r, _ := Remez(41, low, sr, nil)
+	if g := gain(r.Taps, 8000); g > 1.02*r.Dev/10 {
+		t.Errorf("got stop band gain %f with deviation %f", g, r.Dev)
+	}
+}
+
+func TestRemezDifferentiator(t *testing.T) {
+	bs := []Band{{0, 18000 * freq.Hertz, 1, 1}}
+	for _, n := range []int{30, 31} {
+		r, e := Remez(n, bs, sr, &RemezOpts{Kind: Differentiator})
+		if e != nil {
+			t.Fatal(e)
+		}
+		for f := 100.0; f <= 18000; f += 100 {
+			w := 2 * math.Pi * f / sr.Float64()
+			if g := gain(r.Taps, f); math.Abs(g-w) > 1e-4*w {
+				t.Errorf("%d: got gain %f not %f at %f", n, g, w, f)
+			}
+		}
+	}
+}
+
+func TestRemezErrors(t *testing.T) {
+	k := freq.Hertz
+	low := []Band{{0, 4000 * k, 1, 1}, {6000 * k, 24000 * k, 0, 1}}
+	if _, e := Remez(101, low, sr, &RemezOpts{MaxIter: 1}); e == nil {
+		t.Errorf("expected convergence error")
+	}
+	if _, e := Remez(2, low, sr, nil); e == nil {
+		t.Errorf("expected taps error")
+	}
+	if _, e := Remez(11, []Band{{0, 4000 * k, 1, 1}, {3000 * k, 24000 * k, 0, 1}}, sr, nil); e == nil {
+		t.Errorf("expected overlap error")
+	}
+	if _, e := Remez(11, []Band{{0, 30000 * k, 1, 1}}, sr, nil); e == nil {
+		t.Errorf("expected band error")
+	}
+	if _, e := Remez(11, nil, sr, nil); e == nil {
+		t.Errorf("expected no bands error")
+	}
+	if _, e := Remez(11, low, sr, &RemezOpts{Kind: Kind(5)}); e == nil {
+		t.Errorf("expected kind error")
+	}
+}
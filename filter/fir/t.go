@@ -0,0 +1,209 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package fir
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/zikichombo/dsp/convol"
+	"github.com/zikichombo/sound"
+	"github.com/zikichombo/sound/cil"
+	"github.com/zikichombo/sound/freq"
+)
+
+// DirectMax is the largest number of taps for which T filters in direct
+// form.  Longer filters use fft block convolution.
+const DirectMax = 64
+
+// directBlock is the number of frames filtered at once in direct form.
+const directBlock = 256
+
+// T filters a sound.Source with FIR taps.  The output of T is the full
+// convolution of its source with the taps, including the tail of
+// len(taps)-1 frames after the end of its source.
+//
+// T implements sound.Source.
+type T struct {
+	src   sound.Source
+	nC    int
+	taps  []float64
+	block int
+	olas  []*convol.Ola // per channel, nil in direct form
+	ins   [][]float64   // per channel, ola input or direct form history
+	rbuf  []float64
+	q     [][]float64 // per channel, filtered block
+	qn    int         // frames of q ready for Receive
+	qi    int
+	tail  int  // frames of tail not yet filtered
+	done  bool // src gives no more data
+	err   error
+}
+
+// New creates a new T filtering src with taps.  Filters with at most
+// DirectMax taps are computed in direct form, and longer ones by overlap-add
+// block convolution with convol.Ola.
+//
+// New returns a non-nil error if taps is empty.
+func New(src sound.Source, taps []float64) (*T, error) {
+	m := len(taps)
+	if m == 0 {
+		return nil, fmt.Errorf("no taps")
+	}
+	nC := src.Channels()
+	res := &T{
+		src:  src,
+		nC:   nC,
+		taps: append([]float64{}, taps...),
+		ins:  make([][]float64, nC),
+		q:    make([][]float64, nC)}
+	if m <= DirectMax {
+		res.block = directBlock
+		for c := 0; c < nC; c++ {
+			res.ins[c] = make([]float64, m-1+res.block)
+			res.q[c] = make([]float64, res.block)
+		}
+	} else {
+		// a power of 2 convolution size with blocks of at least 3 times
+		// the taps.
+		l := 1
+		for l < 4*m {
+			l *= 2
+		}
+		res.block = l - m + 1
+		res.olas = make([]*convol.Ola, nC)
+		for c := 0; c < nC; c++ {
+			o := convol.NewOla(res.taps, res.block)
+			res.olas[c] = o
+			res.ins[c] = o.WinSrc(nil)
+			res.q[c] = o.WinDst(nil)
+		}
+	}
+	res.rbuf = make([]float64, nC*res.block)
+	return res, nil
+}
+
+// Taps returns the taps of t.
+func (t *T) Taps() []float64 {
+	return t.taps
+}
+
+// Direct returns whether t filters in direct form.
+func (t *T) Direct() bool {
+	return t.olas == nil
+}
+
+// Channels returns the number of channels.
+func (t *T) Channels() int {
+	return t.nC
+}
+
+// SampleRate returns the sample rate.
+func (t *T) SampleRate() freq.T {
+	return t.src.SampleRate()
+}
+
+// Close closes the source of t.
+func (t *T) Close() error {
+	return t.src.Close()
+}
+
+// Receive implements sound.Source.
+func (t *T) Receive(d []float64) (int, error) {
+	nC := t.nC
+	if len(d)%nC != 0 {
+		return 0, sound.ErrChannelAlignment
+	}
+	nF := len(d) / nC
+	f := 0
+	for f < nF {
+		if t.qi == t.qn {
+			if t.err != nil {
+				break
+			}
+			t.qi, t.qn = 0, 0
+			t.err = t.pull()
+			continue
+		}
+		n := t.qn - t.qi
+		if n > nF-f {
+			n = nF - f
+		}
+		for c, q := range t.q {
+			copy(d[c*nF+f:c*nF+f+n], q[t.qi:t.qi+n])
+		}
+		t.qi += n
+		f += n
+	}
+	if f == 0 {
+		return 0, t.err
+	}
+	if f < nF {
+		cil.Compact(d, nC, f)
+	}
+	return f, nil
+}
+
+// pull filters the next block, returning io.EOF after the tail.
+func (t *T) pull() error {
+	n := 0
+	if !t.done {
+		var e error
+		n, e = t.src.Receive(t.rbuf)
+		if e != nil && e != io.EOF {
+			return e
+		}
+		if n < t.block {
+			t.done = true
+		}
+		if n > 0 {
+			t.tail = len(t.taps) - 1
+		}
+	}
+	// after the source, the input is taken as zeros, giving the tail if the
+	// source was not empty.
+	z := 0
+	if t.done {
+		z = t.block - n
+		if z > t.tail {
+			z = t.tail
+		}
+		t.tail -= z
+	}
+	if n+z == 0 {
+		return io.EOF
+	}
+	m := len(t.taps) - 1
+	for c := 0; c < t.nC; c++ {
+		x := t.rbuf[c*n : (c+1)*n]
+		in := t.ins[c]
+		if t.olas != nil {
+			copy(in, x)
+			zero(in[n:])
+			if e := t.olas[c].Block(in, t.q[c]); e != nil {
+				return e
+			}
+			continue
+		}
+		copy(in[m:], x)
+		zero(in[m+n:])
+		q := t.q[c]
+		for i := 0; i < n+z; i++ {
+			s := 0.0
+			for k, h := range t.taps {
+				s += h * in[m+i-k]
+			}
+			q[i] = s
+		}
+		copy(in, in[t.block:t.block+m])
+	}
+	t.qn = n + z
+	return nil
+}
+
+func zero(d []float64) {
+	for i := range d {
+		d[i] = 0
+	}
+}
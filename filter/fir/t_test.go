@@ -0,0 +1,89 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package fir
+
+import (
+	"io"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/zikichombo/sound"
+	"github.com/zikichombo/sound/sndbuf"
+)
+
+func TestT(t *testing.T) {
+	nC := 2
+	for _, m := range []int{1, 16, DirectMax + 1, 300} {
+		for _, n := range []int{0, 7, 512, 1000, 5000} {
+			taps := make([]float64, m)
+			for i := range taps {
+				taps[i] = rand.Float64()*2 - 1
+			}
+			inter := make([]float64, n*nC)
+			for i := range inter {
+				inter[i] = rand.Float64()*2 - 1
+			}
+			f, e := New(sndbuf.FromSliceChans(inter, nC, sr), taps)
+			if e != nil {
+				t.Fatal(e)
+			}
+			if f.Direct() != (m <= DirectMax) {
+				t.Errorf("%d taps: got direct %t", m, f.Direct())
+			}
+			exp := n + m - 1
+			if n == 0 {
+				exp = 0
+			}
+			got := make([][]float64, nC)
+			d := make([]float64, 0, 2*nC*257)
+			for {
+				d = d[:nC*(rand.Intn(257)+1)]
+				nF, e := f.Receive(d)
+				if e == io.EOF {
+					break
+				}
+				if e != nil {
+					t.Fatal(e)
+				}
+				for c := range got {
+					got[c] = append(got[c], d[c*nF:(c+1)*nF]...)
+				}
+			}
+			for c, g := range got {
+				if len(g) != exp {
+					t.Errorf("%d taps %d frames: got %d frames not %d", m, n, len(g), exp)
+					continue
+				}
+				for i, v := range g {
+					s := 0.0
+					for k, h := range taps {
+						j := i - k
+						if j >= 0 && j < n {
+							s += h * inter[j*nC+c]
+						}
+					}
+					if math.Abs(v-s) > 1e-9 {
+						t.Errorf("%d taps %d frames: got %f not %f at %d chan %d", m, n, v, s, i, c)
+						break
+					}
+				}
+			}
+		}
+	}
+	f, e := New(sndbuf.FromSlice(make([]float64, 10), sr), []float64{1, 2})
+	if e != nil {
+		t.Fatal(e)
+	}
+	if _, e := f.Receive(make([]float64, 3)); e != nil {
+		t.Error(e)
+	}
+	f, _ = New(sndbuf.FromSliceChans(make([]float64, 10), 2, sr), []float64{1, 2})
+	if _, e := f.Receive(make([]float64, 3)); e != sound.ErrChannelAlignment {
+		t.Errorf("expected channel alignment error, got %v", e)
+	}
+	if _, e := New(sndbuf.FromSlice(nil, sr), nil); e == nil {
+		t.Errorf("expected taps error")
+	}
+}
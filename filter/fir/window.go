@@ -0,0 +1,196 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package fir
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/zikichombo/dsp/wfn"
+	"github.com/zikichombo/sound/freq"
+)
+
+// LowPass returns n taps of a filter at sample rate sr passing frequencies
+// below fc, designed by the window method with window function win.  A nil
+// win is the rectangular window.
+//
+// LowPass returns a non-nil error if n < 1 or fc is not strictly between 0
+// and the Nyquist frequency.
+func LowPass(n int, fc, sr freq.T, win func(float64) float64) ([]float64, error) {
+	return Multiband(n, []freq.T{fc}, []float64{1, 0}, sr, win)
+}
+
+// HighPass returns n taps of a filter passing frequencies above fc, as in
+// LowPass.  HighPass returns a non-nil error if n is even, as such filters
+// have 0 gain at the Nyquist frequency.
+func HighPass(n int, fc, sr freq.T, win func(float64) float64) ([]float64, error) {
+	return Multiband(n, []freq.T{fc}, []float64{0, 1}, sr, win)
+}
+
+// BandPass returns n taps of a filter passing frequencies between lo and
+// hi, as in LowPass.
+func BandPass(n int, lo, hi, sr freq.T, win func(float64) float64) ([]float64, error) {
+	return Multiband(n, []freq.T{lo, hi}, []float64{0, 1, 0}, sr, win)
+}
+
+// BandStop returns n taps of a filter passing frequencies outside lo and
+// hi, as in HighPass.
+func BandStop(n int, lo, hi, sr freq.T, win func(float64) float64) ([]float64, error) {
+	return Multiband(n, []freq.T{lo, hi}, []float64{1, 0, 1}, sr, win)
+}
+
+// Multiband returns n taps of a filter at sample rate sr with gain gains[i]
+// between edges[i-1] and edges[i], where the first band starts at 0 and the
+// last ends at the Nyquist frequency, designed by the window method with
+// window function win.  A nil win is the rectangular window.
+//
+// The taps are scaled so that the gain at the center of the first band
+// with largest gain is exact, where the center of the first band is 0 and
+// that of the last band is the Nyquist frequency.
+//
+// Multiband returns a non-nil error if n < 1, if len(gains) is not
+// len(edges)+1, if the edges are not ascending and strictly between 0 and
+// the Nyquist frequency, or if n is even and the last gain is not 0.
+func Multiband(n int, edges []freq.T, gains []float64, sr freq.T, win func(float64) float64) ([]float64, error) {
+	if n < 1 {
+		return nil, fmt.Errorf("invalid number of taps %d", n)
+	}
+	if len(gains) != len(edges)+1 {
+		return nil, fmt.Errorf("wrong number of gains %d != %d", len(gains), len(edges)+1)
+	}
+	fs, e := normEdges(edges, sr)
+	if e != nil {
+		return nil, e
+	}
+	if n%2 == 0 && gains[len(gains)-1] != 0 {
+		return nil, fmt.Errorf("even number of taps %d with gain at Nyquist", n)
+	}
+	m := float64(n-1) / 2
+	res := make([]float64, n)
+	for k, g := range gains {
+		if g == 0 {
+			continue
+		}
+		for i := range res {
+			t := float64(i) - m
+			res[i] += g * (ideal(fs[k+1], t) - ideal(fs[k], t))
+		}
+	}
+	window(res, win)
+	// scale to the reference band.
+	ref := 0
+	for k, g := range gains {
+		if math.Abs(g) > math.Abs(gains[ref]) {
+			ref = k
+		}
+	}
+	if gains[ref] == 0 {
+		return res, nil
+	}
+	f := (fs[ref] + fs[ref+1]) / 2
+	switch ref {
+	case 0:
+		f = 0
+	case len(gains) - 1:
+		f = 0.5
+	}
+	if a := amp(res, f); a != 0 {
+		s := gains[ref] / a
+		for i := range res {
+			res[i] *= s
+		}
+	}
+	return res, nil
+}
+
+// normEdges returns the edges in cycles per sample with 0 and 0.5 added at
+// the ends.
+func normEdges(edges []freq.T, sr freq.T) ([]float64, error) {
+	res := make([]float64, len(edges)+2)
+	for i, e := range edges {
+		if e <= 0 || 2*e >= sr {
+			return nil, fmt.Errorf("edge %s not in (0, %s)", e, sr/2)
+		}
+		if i > 0 && e <= edges[i-1] {
+			return nil, fmt.Errorf("edges %s %s not ascending", edges[i-1], e)
+		}
+		res[i+1] = e.Float64() / sr.Float64()
+	}
+	res[len(res)-1] = 0.5
+	return res, nil
+}
+
+// ideal returns the impulse response at time t of the ideal low pass filter
+// with cutoff f cycles per sample.
+func ideal(f, t float64) float64 {
+	if f == 0 {
+		return 0
+	}
+	return 2 * f * wfn.Sinc(2*f*t)
+}
+
+// window applies the window function win to h.
+func window(h []float64, win func(float64) float64) {
+	if win == nil || len(h) < 2 {
+		return
+	}
+	wfn.New(win, len(h)).Apply(h)
+}
+
+// amp returns the response at f cycles per sample of the symmetric filter
+// h with its delay removed.
+func amp(h []float64, f float64) float64 {
+	m := float64(len(h)-1) / 2
+	res := 0.0
+	for i, v := range h {
+		res += v * math.Cos(2*math.Pi*f*(float64(i)-m))
+	}
+	return res
+}
+
+// KaiserBeta returns the Kaiser window parameter for a filter designed by
+// the window method with attenuation a dB in the stop bands, which is also
+// the size of the pass band ripple relative to the gain.
+func KaiserBeta(a float64) float64 {
+	switch {
+	case a > 50:
+		return 0.1102 * (a - 8.7)
+	case a >= 21:
+		return 0.5842*math.Pow(a-21, 0.4) + 0.07886*(a-21)
+	}
+	return 0
+}
+
+// KaiserOrd returns an estimate of the number of taps and the Kaiser
+// window parameter for a filter at sample rate sr designed by the window
+// method with transition bands of width w, a pass band ripple of at most
+// dp relative to the gain and a stop band attenuation of at least as dB,
+// following Kaiser's formulas.  The window method gives the same deviation
+// in both bands, so the design uses the attenuation
+//
+//	a = -20*log10(min(dp, 10^(-as/20)))
+//
+// for both.  Filters with gain at the Nyquist frequency need an odd number
+// of taps, which may be 1 more than n.
+//
+// KaiserOrd returns a non-nil error if dp is not in (0, 1), if as is not
+// positive or if w is not strictly between 0 and the Nyquist frequency.
+func KaiserOrd(dp, as float64, w, sr freq.T) (n int, beta float64, err error) {
+	if !(dp > 0 && dp < 1) {
+		return 0, 0, fmt.Errorf("invalid pass band ripple %f", dp)
+	}
+	if !(as > 0) || math.IsInf(as, 1) {
+		return 0, 0, fmt.Errorf("invalid attenuation %f dB", as)
+	}
+	if w <= 0 || 2*w >= sr {
+		return 0, 0, fmt.Errorf("transition width %s not in (0, %s)", w, sr/2)
+	}
+	a := math.Max(-20*math.Log10(dp), as)
+	dw := 2 * math.Pi * w.Float64() / sr.Float64()
+	n = int(math.Ceil((a-7.95)/(2.285*dw))) + 1
+	if n < 1 {
+		n = 1
+	}
+	return n, KaiserBeta(a), nil
+}
@@ -0,0 +1,154 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package fir
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+
+	"github.com/zikichombo/dsp/convol"
+	"github.com/zikichombo/dsp/wfn"
+	"github.com/zikichombo/sound/freq"
+)
+
+var sr = 48000 * freq.Hertz
+
+// gain returns the gain of h at f Hertz.
+func gain(h []float64, f float64) float64 {
+	w := 2 * math.Pi * f / sr.Float64()
+	res := complex(0, 0)
+	for i, v := range h {
+		res += complex(v, 0) * cmplx.Rect(1, -w*float64(i))
+	}
+	return cmplx.Abs(res)
+}
+
+func symmetric(t *testing.T, h []float64) {
+	for i := range h {
+		if math.Abs(h[i]-h[len(h)-1-i]) > 1e-15 {
+			t.Fatalf("asymmetric taps at %d: %f %f", i, h[i], h[len(h)-1-i])
+		}
+	}
+}
+
+func TestKaiserLowPass(t *testing.T) {
+	dp, as, w := 0.01, 60.0, 500*freq.Hertz
+	n, beta, e := KaiserOrd(dp, as, w, sr)
+	if e != nil {
+		t.Fatal(e)
+	}
+	fc := 4000 * freq.Hertz
+	h, e := LowPass(n, fc, sr, wfn.Kaiser(beta))
+	if e != nil {
+		t.Fatal(e)
+	}
+	if len(h) != n {
+		t.Fatalf("got %d taps not %d", len(h), n)
+	}
+	symmetric(t, h)
+	ds := math.Pow(10, -as/20)
+	for f := 0.0; f < 24000; f += 10 {
+		g := gain(h, f)
+		switch {
+		case f <= (fc - w/2).Float64():
+			if math.Abs(g-1) > 1.5*math.Min(dp, ds) {
+				t.Errorf("got gain %f at %f in pass band", g, f)
+			}
+		case f >= (fc + w/2).Float64():
+			if g > 1.5*ds {
+				t.Errorf("got gain %f at %f in stop band", g, f)
+			}
+		}
+	}
+}
+
+func TestBands(t *testing.T) {
+	lo, hi := 2000*freq.Hertz, 6000*freq.Hertz
+	type tc struct {
+		name string
+		h    []float64
+		e    error
+		pts  [][2]float64 // frequency, gain
+	}
+	h1, e1 := HighPass(101, lo, sr, wfn.Hamming)
+	h2, e2 := BandPass(101, lo, hi, sr, wfn.Blackman)
+	h3, e3 := BandStop(101, lo, hi, sr, wfn.Hann)
+	h4, e4 := Multiband(201, []freq.T{lo, hi, 12000 * freq.Hertz}, []float64{0.5, 0, 1, 0.25}, sr, wfn.Hamming)
+	tcs := []tc{
+		{"high", h1, e1, [][2]float64{{24000, 1}, {0, 0}, {10000, 1}}},
+		{"pass", h2, e2, [][2]float64{{4000, 1}, {0, 0}, {12000, 0}}},
+		{"stop", h3, e3, [][2]float64{{0, 1}, {4000, 0}, {24000, 1}}},
+		{"multi", h4, e4, [][2]float64{{0, 0.5}, {4000, 0}, {9000, 1}, {20000, 0.25}}}}
+	for _, c := range tcs {
+		if c.e != nil {
+			t.Fatalf("%s: %s", c.name, c.e)
+		}
+		symmetric(t, c.h)
+		for _, pt := range c.pts {
+			if g := gain(c.h, pt[0]); math.Abs(g-pt[1]) > 0.01 {
+				t.Errorf("%s: got gain %f not %f at %f", c.name, g, pt[1], pt[0])
+			}
+		}
+	}
+	if _, e := HighPass(100, lo, sr, nil); e == nil {
+		t.Errorf("expected even taps error")
+	}
+	if _, e := LowPass(0, lo, sr, nil); e == nil {
+		t.Errorf("expected taps error")
+	}
+	if _, e := BandPass(11, hi, lo, sr, nil); e == nil {
+		t.Errorf("expected ascending error")
+	}
+	if _, e := LowPass(11, sr/2, sr, nil); e == nil {
+		t.Errorf("expected edge error")
+	}
+	if _, e := Multiband(11, []freq.T{lo}, []float64{1}, sr, nil); e == nil {
+		t.Errorf("expected gains error")
+	}
+	if h, _ := LowPass(1, lo, sr, wfn.Hann); len(h) != 1 || h[0] != 1 {
+		t.Errorf("got single tap %v", h)
+	}
+	if _, _, e := KaiserOrd(0.01, -1, lo, sr); e == nil {
+		t.Errorf("expected attenuation error")
+	}
+	if _, _, e := KaiserOrd(0, 40, lo, sr); e == nil {
+		t.Errorf("expected ripple error")
+	}
+	if _, _, e := KaiserOrd(0.01, 40, 0, sr); e == nil {
+		t.Errorf("expected width error")
+	}
+	// a pass band ripple of 0.001 needs 60 dB, more than the 40 dB asked for
+	// the stop band.
+	n1, b1, _ := KaiserOrd(0.001, 40, lo, sr)
+	n2, b2, _ := KaiserOrd(0.5, 60, lo, sr)
+	if n1 != n2 || b1 != b2 {
+		t.Errorf("got %d taps beta %f and %d taps beta %f for 60 dB", n1, b1, n2, b2)
+	}
+}
+
+func TestConvol(t *testing.T) {
+	h, e := LowPass(63, 3000*freq.Hertz, sr, wfn.Hamming)
+	if e != nil {
+		t.Fatal(e)
+	}
+	n := 1024
+	d := make([]float64, n)
+	for i := range d {
+		d[i] = math.Sin(2*math.Pi*float64(i)*1000/sr.Float64()) + math.Sin(2*math.Pi*float64(i)*9000/sr.Float64())
+	}
+	k := convol.NewK(h, n)
+	out, e := k.Conv(k.Win(append([]float64{}, d...)))
+	if e != nil {
+		t.Fatal(e)
+	}
+	// after the transient, the output is the 1000Hz component delayed by
+	// half the filter length.
+	for i := len(h); i < n; i++ {
+		exp := math.Sin(2 * math.Pi * float64(i-31) * 1000 / sr.Float64())
+		if math.Abs(out[i]-exp) > 0.01 {
+			t.Fatalf("got %f not %f at %d", out[i], exp, i)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+// Package freqz provides frequency, phase and group delay responses of
+// digital filters, like matlab's freqz.
+//
+// Package freqz is part of http://zikichombo.org
+//
+// Responses of filters given by FIR taps, transfer function coefficients
+// or second order sections are evaluated at the frequencies of a Band,
+// either linearly or logarithmically spaced.  Linearly spaced responses
+// from 0 to Pi or 2 Pi radians per sample are computed with fft.Real, other
+// linearly spaced bands with a chirp-z transform from package czt, and
+// logarithmically spaced ones directly.
+//
+// The group delay is computed from the transforms of the coefficients
+// multiplied by their index, which avoids differentiating the phase.
+//
+// A response R gives its unwrapped phase and may be converted to an fft.S,
+// so that for example Mag, MagDb, Peaks and PlotMag apply.
+package freqz
@@ -0,0 +1,374 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package freqz
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+
+	"github.com/zikichombo/dsp/czt"
+	"github.com/zikichombo/dsp/fft"
+	"github.com/zikichombo/dsp/filter/iir"
+	"github.com/zikichombo/dsp/mathutil"
+	"github.com/zikichombo/sound/freq"
+)
+
+// Spacing gives how the frequencies of a Band are spaced.
+type Spacing int
+
+const (
+	// Linear spacing of N frequencies from Lo up to but not including Hi.
+	Linear Spacing = iota
+	// Log spacing of N frequencies from Lo to Hi inclusive.
+	Log
+)
+
+func (s Spacing) String() string {
+	switch s {
+	case Linear:
+		return "linear"
+	case Log:
+		return "log"
+	}
+	return fmt.Sprintf("Spacing(%d)", int(s))
+}
+
+// Band gives the frequencies at which a response is evaluated, in radians
+// per sample.  freq.T.RadsPer converts from Hertz.
+type Band struct {
+	N       int
+	Lo, Hi  float64
+	Spacing Spacing
+}
+
+// Half returns the band of n linearly spaced frequencies from 0 up to the
+// Nyquist frequency, the default of matlab's freqz.
+func Half(n int) Band {
+	return Band{N: n, Hi: math.Pi}
+}
+
+// Whole returns the band of n linearly spaced frequencies around the unit
+// circle.
+func Whole(n int) Band {
+	return Band{N: n, Hi: 2 * math.Pi}
+}
+
+func (b Band) ck() error {
+	if b.N < 1 {
+		return fmt.Errorf("invalid number of frequencies %d", b.N)
+	}
+	if !(b.Lo >= 0 && b.Lo < b.Hi && b.Hi <= 2*math.Pi) {
+		return fmt.Errorf("invalid band %f..%f", b.Lo, b.Hi)
+	}
+	switch b.Spacing {
+	case Linear:
+	case Log:
+		if b.Lo == 0 {
+			return fmt.Errorf("log spaced band starts at 0")
+		}
+	default:
+		return fmt.Errorf("invalid spacing %s", b.Spacing)
+	}
+	return nil
+}
+
+// W returns the frequencies of b in radians per sample.
+func (b Band) W() []float64 {
+	res := make([]float64, b.N)
+	switch b.Spacing {
+	case Log:
+		if b.N == 1 {
+			res[0] = b.Lo
+			break
+		}
+		r := math.Log(b.Hi / b.Lo)
+		for i := range res {
+			res[i] = b.Lo * math.Exp(r*float64(i)/float64(b.N-1))
+		}
+		res[b.N-1] = b.Hi
+	default:
+		step := (b.Hi - b.Lo) / float64(b.N)
+		for i := range res {
+			res[i] = b.Lo + step*float64(i)
+		}
+	}
+	return res
+}
+
+// fftN returns the size of the real fft whose first bins are the
+// frequencies of b, or 0 if there is none.
+func (b Band) fftN() int {
+	if b.Spacing != Linear || b.Lo != 0 {
+		return 0
+	}
+	m := 0
+	switch b.Hi {
+	case math.Pi:
+		m = 2 * b.N
+	case 2 * math.Pi:
+		m = b.N
+	}
+	if m < 2 {
+		return 0
+	}
+	return m
+}
+
+// R is a frequency response.
+type R struct {
+	// W holds the frequencies in radians per sample.
+	W []float64
+	// H holds the complex response at each frequency.
+	H []complex128
+	// D holds the group delay in samples at each frequency, 0 where
+	// the response is 0.
+	D []float64
+
+	half bool    // whether the band is Half(N)
+	nyq  float64 // response at the Nyquist frequency if half
+}
+
+// N returns the number of frequencies in r.
+func (r *R) N() int {
+	return len(r.W)
+}
+
+// Freq returns the i'th frequency of r at sample rate sr.
+func (r *R) Freq(i int, sr freq.T) freq.T {
+	return sr.FreqOf(r.W[i])
+}
+
+// Phase returns the phase of r unwrapped across its frequencies, so that
+// the differences between successive phases are in [-Pi, Pi).
+func (r *R) Phase() []float64 {
+	res := make([]float64, len(r.H))
+	for i, h := range r.H {
+		p := cmplx.Phase(h)
+		if i == 0 {
+			res[i] = p
+			continue
+		}
+		res[i] = res[i-1] + mathutil.PrincArg(p-res[i-1])
+	}
+	return res
+}
+
+// S returns r as the spectrum of a real signal with 2*r.N() bins, bin i
+// holding r.H[i], bin -i its conjugate and bin r.N() the response at the
+// Nyquist frequency, so that the bins of the result are spaced as the
+// frequencies of r.
+//
+// S returns a non-nil error unless r was computed by FIR, TF or SOS in a
+// band Half(n).
+func (r *R) S() (*fft.S, error) {
+	if !r.half {
+		return nil, fmt.Errorf("response is not in a half band")
+	}
+	n := len(r.H)
+	d := make([]complex128, 2*n)
+	for i, h := range r.H {
+		d[i] = h
+		if i > 0 {
+			d[len(d)-i] = cmplx.Conj(h)
+		}
+	}
+	d[n] = complex(r.nyq, 0)
+	return fft.NewS(d), nil
+}
+
+// FIR returns the response of the filter with taps h in band b.
+//
+// FIR returns a non-nil error if b is invalid.
+func FIR(h []float64, b Band) (*R, error) {
+	return TF(h, []float64{1}, b)
+}
+
+// TF returns the response of the filter with transfer function
+//
+//	(nb[0] + nb[1]/z + nb[2]/z^2 ...) / (a[0] + a[1]/z + a[2]/z^2 ...)
+//
+// in band b.
+//
+// TF returns a non-nil error if b is invalid or if a is empty.
+func TF(nb, a []float64, b Band) (*R, error) {
+	if e := b.ck(); e != nil {
+		return nil, e
+	}
+	if len(a) == 0 {
+		return nil, fmt.Errorf("empty denominator")
+	}
+	l := len(nb)
+	if len(a) > l {
+		l = len(a)
+	}
+	v := newEvaluator(b, l)
+	res := &R{W: b.W(), H: make([]complex128, b.N), D: make([]float64, b.N)}
+	v.resp(res.H, res.D, nb, a)
+	if b == Half(b.N) {
+		res.half = true
+		res.nyq = alt(nb) / alt(a)
+	}
+	return res, nil
+}
+
+// SOS returns the response of the second order sections s in band b.
+//
+// SOS returns a non-nil error if b is invalid.
+func SOS(s iir.SOS, b Band) (*R, error) {
+	if e := b.ck(); e != nil {
+		return nil, e
+	}
+	v := newEvaluator(b, 3)
+	res := &R{W: b.W(), H: make([]complex128, b.N), D: make([]float64, b.N)}
+	for i := range res.H {
+		res.H[i] = 1
+	}
+	h := make([]complex128, b.N)
+	d := make([]float64, b.N)
+	for _, c := range s {
+		v.resp(h, d, []float64{c.B0, c.B1, c.B2}, []float64{1, c.A1, c.A2})
+		for i := range h {
+			res.H[i] *= h[i]
+			res.D[i] += d[i]
+		}
+	}
+	if b == Half(b.N) {
+		res.half = true
+		res.nyq = 1
+		for _, c := range s {
+			res.nyq *= (c.B0 - c.B1 + c.B2) / (1 - c.A1 + c.A2)
+		}
+	}
+	return res, nil
+}
+
+// evaluator evaluates sum c[n] exp(-i w n) over the frequencies w of a
+// band for coefficients c of up to a given length.
+type evaluator struct {
+	w    []float64
+	m    int
+	rft  *fft.Real
+	buf  []float64
+	ct   *czt.T
+	cbuf []complex128
+	nS   int
+}
+
+func newEvaluator(b Band, l int) *evaluator {
+	v := &evaluator{}
+	if m := b.fftN(); m != 0 {
+		v.m = m
+		v.rft = fft.NewReal(m)
+		v.rft.Scale(false)
+		v.buf = make([]float64, m)
+		return v
+	}
+	if b.Spacing == Linear {
+		v.nS = l
+		if b.N > v.nS {
+			v.nS = b.N
+		}
+		v.ct = czt.New(v.nS, b.N, b.Lo, b.Hi)
+		return v
+	}
+	v.w = b.W()
+	return v
+}
+
+// do places the transform of c in dst.
+func (v *evaluator) do(dst []complex128, c []float64) {
+	switch {
+	case v.rft != nil:
+		for i := range v.buf {
+			v.buf[i] = 0
+		}
+		// sampling the spectrum at m frequencies aliases c modulo m.
+		for i, x := range c {
+			v.buf[i%v.m] += x
+		}
+		hc := v.rft.Do(v.buf)
+		for k := range dst {
+			if 2*k <= v.m {
+				dst[k] = hc.Cmplx(k)
+			} else {
+				dst[k] = cmplx.Conj(hc.Cmplx(v.m - k))
+			}
+		}
+	case v.ct != nil:
+		v.cbuf = v.ct.Win(v.cbuf)
+		for i := range v.cbuf {
+			v.cbuf[i] = 0
+		}
+		for i, x := range c {
+			v.cbuf[i] = complex(x, 0)
+		}
+		out := v.ct.Do(v.cbuf)
+		// czt is scaled by 1/sqrt(nS).
+		r := complex(math.Sqrt(float64(v.nS)), 0)
+		for k := range dst {
+			dst[k] = out[k] * r
+		}
+	default:
+		for k, w := range v.w {
+			x := cmplx.Rect(1, -w)
+			s := complex(0, 0)
+			for i := len(c) - 1; i >= 0; i-- {
+				s = s*x + complex(c[i], 0)
+			}
+			dst[k] = s
+		}
+	}
+}
+
+// resp places the response and group delay of nb/a in h and d.
+func (v *evaluator) resp(h []complex128, d []float64, nb, a []float64) {
+	bv, br := make([]complex128, len(h)), make([]complex128, len(h))
+	av, ar := make([]complex128, len(h)), make([]complex128, len(h))
+	v.do(bv, nb)
+	v.do(br, ramp(nb))
+	v.do(av, a)
+	v.do(ar, ramp(a))
+	sb, sa := absSum(nb), absSum(a)
+	for i := range h {
+		h[i] = bv[i] / av[i]
+		// the group delay is undefined where the response is 0.
+		if cmplx.Abs(bv[i]) <= 1e-12*sb || cmplx.Abs(av[i]) <= 1e-12*sa {
+			d[i] = 0
+			continue
+		}
+		d[i] = real(br[i]/bv[i]) - real(ar[i]/av[i])
+	}
+}
+
+// ramp returns c[i]*i.
+func ramp(c []float64) []float64 {
+	res := make([]float64, len(c))
+	for i, x := range c {
+		res[i] = x * float64(i)
+	}
+	return res
+}
+
+// alt returns the alternating sum of c, which is its transform at the
+// Nyquist frequency.
+func alt(c []float64) float64 {
+	res := 0.0
+	for i, x := range c {
+		if i%2 == 0 {
+			res += x
+		} else {
+			res -= x
+		}
+	}
+	return res
+}
+
+func absSum(c []float64) float64 {
+	res := 0.0
+	for _, x := range c {
+		res += math.Abs(x)
+	}
+	return res
+}
@@ -0,0 +1,168 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package freqz
+
+import (
+	"math"
+	"math/cmplx"
+	"math/rand"
+	"testing"
+
+	"github.com/zikichombo/dsp/filter/iir"
+	"github.com/zikichombo/sound/freq"
+)
+
+// direct returns the response of nb/a at w.
+func direct(nb, a []float64, w float64) complex128 {
+	ev := func(c []float64) complex128 {
+		res := complex(0, 0)
+		for i, x := range c {
+			res += complex(x, 0) * cmplx.Rect(1, -w*float64(i))
+		}
+		return res
+	}
+	return ev(nb) / ev(a)
+}
+
+var bands = []Band{
+	Half(64),
+	Whole(33),
+	{N: 50, Lo: 0.1, Hi: 2},
+	{N: 40, Lo: 0.01, Hi: math.Pi, Spacing: Log}}
+
+func TestFIR(t *testing.T) {
+	h := make([]float64, 150)
+	for i := range h {
+		h[i] = rand.Float64()*2 - 1
+	}
+	for _, b := range bands {
+		r, e := FIR(h, b)
+		if e != nil {
+			t.Fatal(e)
+		}
+		if r.N() != b.N {
+			t.Fatalf("%v: got %d frequencies", b, r.N())
+		}
+		for i, w := range r.W {
+			if d := cmplx.Abs(r.H[i] - direct(h, []float64{1}, w)); d > 1e-9 {
+				t.Errorf("%v: error %g at %f", b, d, w)
+			}
+		}
+	}
+	// linear phase.
+	r, _ := FIR([]float64{1, 2, 3, 2, 1}, Half(32))
+	for i, d := range r.D {
+		if math.Abs(d-2) > 1e-9 {
+			t.Errorf("got delay %f at %f", d, r.W[i])
+		}
+	}
+	ph := r.Phase()
+	for i, p := range ph {
+		if math.Abs(p+2*r.W[i]) > 1e-9 {
+			t.Errorf("got phase %f at %f", p, r.W[i])
+		}
+	}
+}
+
+func TestTF(t *testing.T) {
+	a := 0.9
+	for _, b := range bands {
+		r, e := TF([]float64{1}, []float64{1, -a}, b)
+		if e != nil {
+			t.Fatal(e)
+		}
+		for i, w := range r.W {
+			if d := cmplx.Abs(r.H[i] - direct([]float64{1}, []float64{1, -a}, w)); d > 1e-9 {
+				t.Errorf("%v: error %g at %f", b, d, w)
+			}
+			c := math.Cos(w)
+			exp := (a*c - a*a) / (1 - 2*a*c + a*a)
+			if math.Abs(r.D[i]-exp) > 1e-9 {
+				t.Errorf("%v: got delay %f not %f at %f", b, r.D[i], exp, w)
+			}
+		}
+		sp, e := r.S()
+		if b != Half(b.N) {
+			if e == nil {
+				t.Errorf("%v: expected S error", b)
+			}
+			continue
+		}
+		if e != nil {
+			t.Fatal(e)
+		}
+		if sp.N() != 2*b.N {
+			t.Fatalf("%v: got %d bins", b, sp.N())
+		}
+		if d := cmplx.Abs(sp.At(b.N) - direct([]float64{1}, []float64{1, -a}, math.Pi)); d > 1e-9 {
+			t.Errorf("%v: error %g at Nyquist", b, d)
+		}
+	}
+	if _, e := TF([]float64{1}, nil, Half(8)); e == nil {
+		t.Errorf("expected denominator error")
+	}
+}
+
+func TestSOS(t *testing.T) {
+	sr := 48000 * freq.Hertz
+	s, e := iir.Cheby1(6, 1, iir.Pass, sr, 2000*freq.Hertz, 4000*freq.Hertz)
+	if e != nil {
+		t.Fatal(e)
+	}
+	r, e := SOS(s, Half(512))
+	if e != nil {
+		t.Fatal(e)
+	}
+	for i, w := range r.W {
+		if d := cmplx.Abs(r.H[i] - s.Eval(cmplx.Rect(1, w))); d > 1e-9 {
+			t.Errorf("error %g at %f", d, w)
+		}
+		if cmplx.Abs(r.H[i]) < 1e-6 {
+			continue
+		}
+		// the group delay is the negative derivative of the phase.
+		dw := 1e-6
+		exp := -cmplx.Phase(s.Eval(cmplx.Rect(1, w+dw))/s.Eval(cmplx.Rect(1, w-dw))) / (2 * dw)
+		if math.Abs(r.D[i]-exp) > 1e-4*math.Max(1, math.Abs(exp)) {
+			t.Errorf("got delay %f not %f at %f", r.D[i], exp, w)
+		}
+	}
+	sp, e := r.S()
+	if e != nil {
+		t.Fatal(e)
+	}
+	if sp.N() != 2*r.N() {
+		t.Fatalf("got %d bins", sp.N())
+	}
+	for i, h := range r.H {
+		if math.Abs(sp.Mag(i)-cmplx.Abs(h)) > 1e-9 || math.Abs(sp.Mag(-i)-cmplx.Abs(h)) > 1e-9 {
+			t.Fatalf("got magnitude %f not %f at %d", sp.Mag(i), cmplx.Abs(h), i)
+		}
+	}
+	if exp := cmplx.Abs(s.Eval(-1)); math.Abs(sp.Mag(r.N())-exp) > 1e-9 {
+		t.Errorf("got magnitude %f not %f at Nyquist", sp.Mag(r.N()), exp)
+	}
+	for _, p := range sp.Peaks() {
+		if f := r.Freq(p, sr); f < 1900*freq.Hertz || f > 4100*freq.Hertz {
+			t.Errorf("got peak at %s", f)
+		}
+	}
+}
+
+func TestBand(t *testing.T) {
+	for _, b := range []Band{
+		{N: 0, Hi: 1},
+		{N: 4, Lo: 1, Hi: 1},
+		{N: 4, Hi: 7},
+		{N: 4, Hi: 1, Spacing: Log},
+		{N: 4, Hi: 1, Spacing: Spacing(3)}} {
+		if _, e := FIR([]float64{1}, b); e == nil {
+			t.Errorf("%v: expected error", b)
+		}
+	}
+	w := Band{N: 3, Lo: 0.1, Hi: 10, Spacing: Log}.W()
+	if math.Abs(w[1]-1) > 1e-12 || w[2] != 10 {
+		t.Errorf("got log frequencies %v", w)
+	}
+}
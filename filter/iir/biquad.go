@@ -0,0 +1,140 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package iir
+
+import "fmt"
+
+// Coefs holds the coefficients of a biquad with transfer function
+//
+//	H(z) = (B0 + B1/z + B2/z^2) / (1 + A1/z + A2/z^2)
+type Coefs struct {
+	B0, B1, B2 float64
+	A1, A2     float64
+}
+
+// Form is a structure for computing a biquad.
+type Form int
+
+const (
+	// TDF2 is the transposed direct form II.
+	TDF2 Form = iota
+	// DF1 is the direct form I.
+	DF1
+)
+
+// String implements Stringer.
+func (f Form) String() string {
+	switch f {
+	case TDF2:
+		return "tdf2"
+	case DF1:
+		return "df1"
+	}
+	return fmt.Sprintf("Form(%d)", int(f))
+}
+
+// Biquad is a second order iir filter of one or more channels.
+type Biquad struct {
+	c    Coefs
+	form Form
+	nC   int
+	// per channel, s1, s2 for TDF2 or x1, x2, y1, y2 for DF1.
+	state []float64
+}
+
+// NewBiquad creates a new Biquad with coefficients c for nC channels,
+// which must be positive or NewBiquad panics.
+func NewBiquad(c Coefs, nC int) *Biquad {
+	if nC < 1 {
+		panic("non-positive number of channels")
+	}
+	return &Biquad{c: c, nC: nC, state: make([]float64, 4*nC)}
+}
+
+// Channels returns the number of channels of b.
+func (b *Biquad) Channels() int {
+	return b.nC
+}
+
+// Coefs returns the coefficients of b.
+func (b *Biquad) Coefs() Coefs {
+	return b.c
+}
+
+// SetCoefs sets the coefficients of b, keeping its state.
+func (b *Biquad) SetCoefs(c Coefs) {
+	b.c = c
+}
+
+// Form returns the form of b.
+func (b *Biquad) Form() Form {
+	return b.form
+}
+
+// SetForm sets the form of b, which defaults to TDF2, and resets its state.
+func (b *Biquad) SetForm(f Form) {
+	b.form = f
+	b.Reset()
+}
+
+// Reset sets the state of b to that of silence.
+func (b *Biquad) Reset() {
+	for i := range b.state {
+		b.state[i] = 0
+	}
+}
+
+// Process filters the samples d of channel 0 of b in place.
+func (b *Biquad) Process(d []float64) {
+	b.ProcessChannel(0, d)
+}
+
+// ProcessChannel filters the samples d of channel c of b in place.
+//
+// ProcessChannel panics if c is not a channel of b.
+func (b *Biquad) ProcessChannel(c int, d []float64) {
+	st := b.state[4*c : 4*c+4]
+	b.process(st, d, 1)
+}
+
+// ProcessInterleaved filters the interleaved samples d of all channels of b
+// in place.
+//
+// ProcessInterleaved returns a non-nil error if the length of d is not a
+// multiple of the number of channels.
+func (b *Biquad) ProcessInterleaved(d []float64) error {
+	if len(d)%b.nC != 0 {
+		return fmt.Errorf("wrong length %d for %d channels", len(d), b.nC)
+	}
+	for c := 0; c < b.nC; c++ {
+		b.process(b.state[4*c:4*c+4], d[c:], b.nC)
+	}
+	return nil
+}
+
+// process filters d[0], d[stride], ... in place.
+func (b *Biquad) process(st, d []float64, stride int) {
+	c := &b.c
+	if b.form == DF1 {
+		x1, x2, y1, y2 := st[0], st[1], st[2], st[3]
+		for i := 0; i < len(d); i += stride {
+			x := d[i]
+			y := c.B0*x + c.B1*x1 + c.B2*x2 - c.A1*y1 - c.A2*y2
+			x2, x1 = x1, x
+			y2, y1 = y1, y
+			d[i] = y
+		}
+		st[0], st[1], st[2], st[3] = x1, x2, y1, y2
+		return
+	}
+	s1, s2 := st[0], st[1]
+	for i := 0; i < len(d); i += stride {
+		x := d[i]
+		y := c.B0*x + s1
+		s1 = c.B1*x - c.A1*y + s2
+		s2 = c.B2*x - c.A2*y
+		d[i] = y
+	}
+	st[0], st[1] = s1, s2
+}
@@ -0,0 +1,167 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package iir
+
+import (
+	"math"
+	"math/cmplx"
+	"math/rand"
+	"testing"
+
+	"github.com/zikichombo/sound/freq"
+)
+
+const sr = 48000 * freq.Hertz
+
+// gain returns |H| of c at frequency f in Hertz.
+func gain(c Coefs, f float64) float64 {
+	z := cmplx.Rect(1, -2*math.Pi*f/sr.Float64())
+	num := complex(c.B0, 0) + complex(c.B1, 0)*z + complex(c.B2, 0)*z*z
+	den := 1 + complex(c.A1, 0)*z + complex(c.A2, 0)*z*z
+	return cmplx.Abs(num / den)
+}
+
+func db(g float64) float64 {
+	return 20 * math.Log10(g)
+}
+
+func TestDesigns(t *testing.T) {
+	fc := 1000 * freq.Hertz
+	ny := sr.Float64()/2 - 1e-6
+	type tc struct {
+		name         string
+		c            Coefs
+		dc, at, high float64 // expected gains in db, NaN for don't care
+	}
+	must := func(c Coefs, e error) Coefs {
+		if e != nil {
+			t.Fatal(e)
+		}
+		return c
+	}
+	nan := math.NaN()
+	q := 1 / math.Sqrt2
+	tcs := []tc{
+		{"lowpass", must(LowPass(fc, sr, q)), 0, -3.0103, nan},
+		{"highpass", must(HighPass(fc, sr, q)), nan, -3.0103, 0},
+		{"bandpass", must(BandPass(fc, sr, 2)), nan, 0, nan},
+		{"notch", must(Notch(fc, sr, 2)), 0, nan, 0},
+		{"allpass", must(AllPass(fc, sr, 2)), 0, 0, 0},
+		{"peak", must(Peak(fc, sr, 2, 6)), 0, 6, 0},
+		{"lowshelf", must(LowShelf(fc, sr, 1, -6)), -6, -3, 0},
+		{"highshelf", must(HighShelf(fc, sr, 1, 6)), 0, 3, 6},
+	}
+	for _, c := range tcs {
+		for i, f := range []float64{0, fc.Float64(), ny} {
+			exp := []float64{c.dc, c.at, c.high}[i]
+			if math.IsNaN(exp) {
+				continue
+			}
+			if g := db(gain(c.c, f)); math.Abs(g-exp) > 0.01 {
+				t.Errorf("%s: got %f dB at %f not %f", c.name, g, f, exp)
+			}
+		}
+	}
+	if g := gain(tcs[3].c, fc.Float64()); g > 1e-9 {
+		t.Errorf("notch: got gain %f", g)
+	}
+	if g := db(gain(tcs[0].c, 10000)); g > -40 {
+		t.Errorf("lowpass: got %f dB at 10kHz", g)
+	}
+	if _, e := LowPass(sr/2, sr, q); e == nil {
+		t.Errorf("expected frequency error")
+	}
+	if _, e := Peak(fc, sr, 0, 3); e == nil {
+		t.Errorf("expected q error")
+	}
+	if _, e := LowShelf(fc, sr, 0, 3); e == nil {
+		t.Errorf("expected slope error")
+	}
+}
+
+func TestProcess(t *testing.T) {
+	c, e := Peak(2000*freq.Hertz, sr, 1, -9)
+	if e != nil {
+		t.Fatal(e)
+	}
+	n := 4800
+	f := 2000.0
+	d := make([]float64, n)
+	for i := range d {
+		d[i] = math.Sin(2 * math.Pi * f * float64(i) / sr.Float64())
+	}
+	for _, fm := range []Form{TDF2, DF1} {
+		b := NewBiquad(c, 1)
+		b.SetForm(fm)
+		y := append([]float64{}, d...)
+		b.Process(y[:1000])
+		b.Process(y[1000:])
+		// steady state amplitude
+		mx := 0.0
+		for _, v := range y[n/2:] {
+			mx = math.Max(mx, math.Abs(v))
+		}
+		if exp := gain(c, f); math.Abs(mx-exp) > 1e-3 {
+			t.Errorf("%s: got amplitude %f not %f", fm, mx, exp)
+		}
+	}
+}
+
+func TestForms(t *testing.T) {
+	c, e := LowShelf(300*freq.Hertz, sr, 0.7, 4)
+	if e != nil {
+		t.Fatal(e)
+	}
+	d := make([]float64, 1000)
+	for i := range d {
+		d[i] = rand.Float64()*2 - 1
+	}
+	a, b := append([]float64{}, d...), append([]float64{}, d...)
+	t2 := NewBiquad(c, 1)
+	d1 := NewBiquad(c, 1)
+	d1.SetForm(DF1)
+	t2.Process(a)
+	d1.Process(b)
+	for i := range a {
+		if math.Abs(a[i]-b[i]) > 1e-9 {
+			t.Fatalf("%d: tdf2 %f df1 %f", i, a[i], b[i])
+		}
+	}
+}
+
+func TestInterleaved(t *testing.T) {
+	c, e := HighPass(500*freq.Hertz, sr, 0.9)
+	if e != nil {
+		t.Fatal(e)
+	}
+	nC, nF := 3, 500
+	d := make([]float64, nC*nF)
+	for i := range d {
+		d[i] = rand.Float64()*2 - 1
+	}
+	inter := append([]float64{}, d...)
+	b := NewBiquad(c, nC)
+	if e := b.ProcessInterleaved(inter[:nC*100]); e != nil {
+		t.Fatal(e)
+	}
+	if e := b.ProcessInterleaved(inter[nC*100:]); e != nil {
+		t.Fatal(e)
+	}
+	s := NewBiquad(c, nC)
+	for ch := 0; ch < nC; ch++ {
+		chd := make([]float64, nF)
+		for f := range chd {
+			chd[f] = d[f*nC+ch]
+		}
+		s.ProcessChannel(ch, chd)
+		for f, v := range chd {
+			if v != inter[f*nC+ch] {
+				t.Fatalf("channel %d frame %d: got %f not %f", ch, f, inter[f*nC+ch], v)
+			}
+		}
+	}
+	if e := b.ProcessInterleaved(d[:4]); e == nil {
+		t.Errorf("expected alignment error")
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package iir
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/zikichombo/sound/freq"
+)
+
+// Band is the kind of band a designed filter passes.
+type Band int
+
+const (
+	// Low passes frequencies below an edge.
+	Low Band = iota
+	// High passes frequencies above an edge.
+	High
+	// Pass passes frequencies between two edges.
+	Pass
+	// Stop passes frequencies outside two edges.
+	Stop
+)
+
+// String implements Stringer.
+func (b Band) String() string {
+	switch b {
+	case Low:
+		return "low"
+	case High:
+		return "high"
+	case Pass:
+		return "pass"
+	case Stop:
+		return "stop"
+	}
+	return fmt.Sprintf("Band(%d)", int(b))
+}
+
+// Design returns the digital filter at sample rate sr of band b obtained
+// from the analog low pass prototype proto, whose edge is at 1 radian per
+// second, by a frequency transformation and the bilinear transform.  The
+// edges are prewarped so that the edge of proto maps to edges[0] for Low
+// and High bands and to both edges[0] and edges[1] for Pass and Stop
+// bands.
+//
+// Design returns a non-nil error if the number of edges does not match b
+// or if the edges are not ascending and strictly between 0 and the Nyquist
+// frequency.
+func Design(proto ZPK, b Band, sr freq.T, edges ...freq.T) (ZPK, error) {
+	n := 1
+	if b == Pass || b == Stop {
+		n = 2
+	}
+	if len(edges) != n {
+		return ZPK{}, fmt.Errorf("%d edges for %s band, need %d", len(edges), b, n)
+	}
+	fs := sr.Float64()
+	ws := make([]float64, n)
+	for i, e := range edges {
+		if e <= 0 || 2*e >= sr {
+			return ZPK{}, fmt.Errorf("edge %s not in (0, %s)", e, sr/2)
+		}
+		if i > 0 && e <= edges[i-1] {
+			return ZPK{}, fmt.Errorf("edges %s %s not ascending", edges[i-1], e)
+		}
+		ws[i] = Prewarp(e.Float64(), fs)
+	}
+	var a ZPK
+	switch b {
+	case Low:
+		a = proto.LowToLow(ws[0])
+	case High:
+		a = proto.LowToHigh(ws[0])
+	case Pass:
+		a = proto.LowToBand(math.Sqrt(ws[0]*ws[1]), ws[1]-ws[0])
+	case Stop:
+		a = proto.LowToStop(math.Sqrt(ws[0]*ws[1]), ws[1]-ws[0])
+	default:
+		return ZPK{}, fmt.Errorf("unknown band %s", b)
+	}
+	return a.Bilinear(fs), nil
+}
+
+func design(proto ZPK, e error, b Band, sr freq.T, edges []freq.T) (SOS, error) {
+	if e != nil {
+		return nil, e
+	}
+	d, e := Design(proto, b, sr, edges...)
+	if e != nil {
+		return nil, e
+	}
+	return d.SOS(), nil
+}
+
+// Butter returns a Butterworth filter of order n of band b at sample rate
+// sr as second order sections, with gains of -3dB at the edges.  Pass and
+// Stop bands have order 2n.
+func Butter(n int, b Band, sr freq.T, edges ...freq.T) (SOS, error) {
+	p, e := ButterAP(n)
+	return design(p, e, b, sr, edges)
+}
+
+// Cheby1 returns a Chebyshev type I filter of order n with rp decibels of
+// ripple in the passband, whose edges are given by edges, as in Butter.
+func Cheby1(n int, rp float64, b Band, sr freq.T, edges ...freq.T) (SOS, error) {
+	p, e := Cheby1AP(n, rp)
+	return design(p, e, b, sr, edges)
+}
+
+// Cheby2 returns a Chebyshev type II filter of order n with rs decibels of
+// attenuation in the stopband, whose edges are given by edges, as in
+// Butter.
+func Cheby2(n int, rs float64, b Band, sr freq.T, edges ...freq.T) (SOS, error) {
+	p, e := Cheby2AP(n, rs)
+	return design(p, e, b, sr, edges)
+}
+
+// Ellip returns an elliptic filter of order n with rp decibels of ripple in
+// the passband, whose edges are given by edges, and rs decibels of
+// attenuation in the stopband, as in Butter.
+func Ellip(n int, rp, rs float64, b Band, sr freq.T, edges ...freq.T) (SOS, error) {
+	p, e := EllipAP(n, rp, rs)
+	return design(p, e, b, sr, edges)
+}
+
+// Bessel returns a Bessel filter of order n, as in Butter.  Bessel filters
+// have nearly constant group delay in the passband.
+func Bessel(n int, b Band, sr freq.T, edges ...freq.T) (SOS, error) {
+	p, e := BesselAP(n)
+	return design(p, e, b, sr, edges)
+}
@@ -0,0 +1,222 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package iir
+
+import (
+	"math"
+	"math/cmplx"
+	"math/rand"
+	"testing"
+
+	"github.com/zikichombo/sound/freq"
+)
+
+func ag(f ZPK, w float64) float64 {
+	return db(cmplx.Abs(f.Eval(complex(0, w))))
+}
+
+func dg(s SOS, f float64) float64 {
+	return db(cmplx.Abs(s.Eval(cmplx.Rect(1, 2*math.Pi*f/sr.Float64()))))
+}
+
+func TestPrototypes(t *testing.T) {
+	for n := 1; n <= 9; n++ {
+		b, _ := ButterAP(n)
+		if g := ag(b, 1); math.Abs(g+3.0103) > 1e-3 {
+			t.Errorf("butter %d: got %f dB", n, g)
+		}
+		c1, _ := Cheby1AP(n, 0.5)
+		if g := ag(c1, 1); math.Abs(g+0.5) > 1e-9 {
+			t.Errorf("cheby1 %d: got %f dB at edge", n, g)
+		}
+		c2, _ := Cheby2AP(n, 40)
+		if g := ag(c2, 1); math.Abs(g+40) > 1e-6 {
+			t.Errorf("cheby2 %d: got %f dB at edge", n, g)
+		}
+		if g := ag(c2, 0); math.Abs(g) > 1e-9 {
+			t.Errorf("cheby2 %d: got %f dB at dc", n, g)
+		}
+		bs, _ := BesselAP(n)
+		if g := ag(bs, 1); math.Abs(g+3.0103) > 1e-3 {
+			t.Errorf("bessel %d: got %f dB", n, g)
+		}
+		for _, f := range []ZPK{b, c1, c2, bs} {
+			if len(f.P) != n {
+				t.Errorf("order %d: got %d poles", n, len(f.P))
+			}
+			for _, p := range f.P {
+				if !(real(p) < 0) {
+					t.Errorf("order %d: unstable pole %v", n, p)
+				}
+			}
+		}
+	}
+}
+
+func TestEllipAP(t *testing.T) {
+	rp, rs := 0.5, 50.0
+	for n := 1; n <= 8; n++ {
+		f, e := EllipAP(n, rp, rs)
+		if e != nil {
+			t.Fatal(e)
+		}
+		for _, p := range f.P {
+			if !(real(p) < 0) {
+				t.Errorf("order %d: unstable pole %v", n, p)
+			}
+		}
+		for w := 0.0; w <= 1; w += 0.01 {
+			if g := ag(f, w); g > 1e-9 || g < -rp-1e-6 {
+				t.Errorf("order %d: got %f dB at %f in passband", n, g, w)
+			}
+		}
+		if n == 1 {
+			continue
+		}
+		ws := 1 / ellipDeg(n, math.Sqrt(math.Pow(10, rp/10)-1)/math.Sqrt(math.Pow(10, rs/10)-1))
+		for w := ws; w < 100*ws; w *= 1.01 {
+			if g := ag(f, w); g > -rs+1e-6 {
+				t.Errorf("order %d: got %f dB at %f in stopband from %f", n, g, w, ws)
+				break
+			}
+		}
+	}
+	if _, e := EllipAP(4, 3, 2); e == nil {
+		t.Errorf("expected attenuation error")
+	}
+}
+
+func TestDesign(t *testing.T) {
+	f1, f2 := 1000*freq.Hertz, 4000*freq.Hertz
+	c := math.Sqrt(Prewarp(f1.Float64(), sr.Float64()) * Prewarp(f2.Float64(), sr.Float64()))
+	fc := math.Atan(c/(2*sr.Float64())) * sr.Float64() / math.Pi
+	type tc struct {
+		name  string
+		b     Band
+		edges []freq.T
+		pts   [][2]float64 // frequency, db
+	}
+	tcs := []tc{
+		{"low", Low, []freq.T{f1}, [][2]float64{{0, 0}, {1000, -3.0103}}},
+		{"high", High, []freq.T{f1}, [][2]float64{{23999, 0}, {1000, -3.0103}}},
+		{"pass", Pass, []freq.T{f1, f2}, [][2]float64{{fc, 0}, {1000, -3.0103}, {4000, -3.0103}}},
+		{"stop", Stop, []freq.T{f1, f2}, [][2]float64{{0, 0}, {1000, -3.0103}, {4000, -3.0103}, {23999, 0}}},
+	}
+	for _, c := range tcs {
+		for n := 1; n <= 6; n++ {
+			s, e := Butter(n, c.b, sr, c.edges...)
+			if e != nil {
+				t.Fatal(e)
+			}
+			for _, pt := range c.pts {
+				if g := dg(s, pt[0]); math.Abs(g-pt[1]) > 1e-3 {
+					t.Errorf("%s %d: got %f dB at %f not %f", c.name, n, g, pt[0], pt[1])
+				}
+			}
+			z, _ := ButterAP(n)
+			d, _ := Design(z, c.b, sr, c.edges...)
+			for _, p := range d.P {
+				if cmplx.Abs(p) >= 1 {
+					t.Errorf("%s %d: unstable pole %v", c.name, n, p)
+				}
+			}
+			for _, f := range []float64{100, 2345, 9000} {
+				zz := cmplx.Rect(1, 2*math.Pi*f/sr.Float64())
+				if a, b := d.Eval(zz), s.Eval(zz); cmplx.Abs(a-b) > 1e-9*math.Max(1, cmplx.Abs(a)) {
+					t.Errorf("%s %d: zpk %v sos %v at %f", c.name, n, a, b, f)
+				}
+			}
+		}
+	}
+	if g := dg(mustSOS(t)(Butter(4, Stop, sr, f1, f2)), fc); g > -100 {
+		t.Errorf("stop: got %f dB at center", g)
+	}
+	if _, e := Butter(2, Pass, sr, f1); e == nil {
+		t.Errorf("expected edges error")
+	}
+	if _, e := Butter(2, Low, sr, sr/2); e == nil {
+		t.Errorf("expected edge range error")
+	}
+	if _, e := Butter(2, Pass, sr, f2, f1); e == nil {
+		t.Errorf("expected ascending error")
+	}
+}
+
+func mustSOS(t *testing.T) func(SOS, error) SOS {
+	return func(s SOS, e error) SOS {
+		if e != nil {
+			t.Fatal(e)
+		}
+		return s
+	}
+}
+
+func TestFamilies(t *testing.T) {
+	must := mustSOS(t)
+	fc := 2000 * freq.Hertz
+	s := must(Cheby1(5, 1, Low, sr, fc))
+	if g := dg(s, 2000); math.Abs(g+1) > 1e-6 {
+		t.Errorf("cheby1: got %f dB at edge", g)
+	}
+	s = must(Cheby2(5, 60, High, sr, fc))
+	if g := dg(s, 2000); math.Abs(g+60) > 1e-6 {
+		t.Errorf("cheby2: got %f dB at edge", g)
+	}
+	s = must(Ellip(6, 0.1, 70, Pass, sr, fc, 2*fc))
+	if g := dg(s, 2000); math.Abs(g+0.1) > 1e-6 {
+		t.Errorf("ellip: got %f dB at edge", g)
+	}
+	if g := dg(s, 500); g > -70 {
+		t.Errorf("ellip: got %f dB in stopband", g)
+	}
+	s = must(Bessel(8, Low, sr, fc))
+	if g := dg(s, 2000); math.Abs(g+3.0103) > 1e-3 {
+		t.Errorf("bessel: got %f dB at edge", g)
+	}
+	if len(s) != 4 {
+		t.Errorf("bessel: got %d sections", len(s))
+	}
+}
+
+func TestCascade(t *testing.T) {
+	s, e := Ellip(5, 1, 60, Low, sr, 3000*freq.Hertz)
+	if e != nil {
+		t.Fatal(e)
+	}
+	d := make([]float64, 2000)
+	for i := range d {
+		d[i] = rand.Float64()*2 - 1
+	}
+	exp := append([]float64{}, d...)
+	for _, c := range s {
+		NewBiquad(c, 1).Process(exp)
+	}
+	nC := 2
+	inter := make([]float64, nC*len(d))
+	for i, v := range d {
+		inter[i*nC] = v
+		inter[i*nC+1] = -v
+	}
+	c := NewCascade(s, nC)
+	if e := c.ProcessInterleaved(inter); e != nil {
+		t.Fatal(e)
+	}
+	for i, v := range exp {
+		if math.Abs(inter[i*nC]-v) > 1e-12 || math.Abs(inter[i*nC+1]+v) > 1e-12 {
+			t.Fatalf("%d: got %f %f not %f", i, inter[i*nC], inter[i*nC+1], v)
+		}
+	}
+	// impulse response matches the transfer function at dc.
+	c.Reset()
+	imp := make([]float64, 4000)
+	imp[0] = 1
+	c.Process(imp)
+	sum := 0.0
+	for _, v := range imp {
+		sum += v
+	}
+	if h := real(s.Eval(1)); math.Abs(sum-h) > 1e-6 {
+		t.Errorf("got dc gain %f not %f", sum, h)
+	}
+}
@@ -0,0 +1,32 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+// Package iir provides infinite impulse response (feedback) filters.
+//
+// Package iir is part of http://zikichombo.org
+//
+// A Biquad is a second order section with coefficients Coefs, filtering
+// one or more channels of samples in place, either one channel at a time
+// or interleaved.  By default, a Biquad uses the transposed direct form II,
+// which needs the least state.  The direct form I keeps the past inputs and
+// outputs as state and so behaves better when the coefficients are changed
+// while filtering, for example when sweeping a cutoff.
+//
+// The functions LowPass, HighPass, BandPass, Notch, AllPass, Peak, LowShelf
+// and HighShelf design biquads following Robert Bristow-Johnson's "Audio
+// EQ Cookbook".
+//
+// Higher order filters are designed from analog low pass prototypes
+// (ButterAP, Cheby1AP, Cheby2AP, EllipAP and BesselAP) in zero, pole and
+// gain form, ZPK, which are transformed to low, high, band pass or band
+// stop filters and then to digital filters by the bilinear transform with
+// prewarped edges.  Design, or Butter, Cheby1, Cheby2, Ellip and Bessel,
+// do this and give second order sections, SOS, which a Cascade filters.
+//
+// A digital filter may also be given in transfer function form, TF, by the
+// coefficients of its numerator and denominator.  TF, ZPK and SOS convert
+// between each other, finding zeros and poles with Roots and pairing them
+// into sections.  Each form can check stability and give the minimum phase
+// filter with the same magnitude response.  Second order sections are the
+// most robust numerically, transfer functions of high order the least.
+package iir
@@ -0,0 +1,124 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package iir
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// Jacobi elliptic functions by Landen transformations, following
+// S. J. Orfanidis, "Lecture Notes on Elliptic Filter Design", 2006.
+//
+// Arguments u are normalized by the complete elliptic integral K of the
+// modulus k, so that cd(u*K, k) = cde(u, k).
+
+// landen returns the descending Landen moduli of k.
+func landen(k float64) []float64 {
+	var res []float64
+	if k == 0 || k == 1 {
+		return res
+	}
+	for k > 1e-16 {
+		kp := math.Sqrt((1 - k) * (1 + k))
+		k = k / (1 + kp)
+		k *= k
+		res = append(res, k)
+		if len(res) > 64 {
+			break
+		}
+	}
+	return res
+}
+
+// ellipK returns the complete elliptic integrals of the first kind of the
+// modulus k and of its complement.
+func ellipK(k float64) (kk, kkp float64) {
+	kp := math.Sqrt((1 - k) * (1 + k))
+	return ellipK1(k), ellipK1(kp)
+}
+
+func ellipK1(k float64) float64 {
+	if k >= 1 {
+		return math.Inf(1)
+	}
+	if k < 1e-8 {
+		return math.Pi / 2
+	}
+	if kp := math.Sqrt((1 - k) * (1 + k)); kp < 1e-8 {
+		return math.Log(4 / kp)
+	}
+	res := math.Pi / 2
+	for _, v := range landen(k) {
+		res *= 1 + v
+	}
+	return res
+}
+
+// cde returns cd(u*K, k).
+func cde(u complex128, k float64) complex128 {
+	v := landen(k)
+	w := cmplx.Cos(u * math.Pi / 2)
+	for i := len(v) - 1; i >= 0; i-- {
+		cv := complex(v[i], 0)
+		w = (1 + cv) * w / (1 + cv*w*w)
+	}
+	return w
+}
+
+// sne returns sn(u*K, k).
+func sne(u complex128, k float64) complex128 {
+	v := landen(k)
+	w := cmplx.Sin(u * math.Pi / 2)
+	for i := len(v) - 1; i >= 0; i-- {
+		cv := complex(v[i], 0)
+		w = (1 + cv) * w / (1 + cv*w*w)
+	}
+	return w
+}
+
+// acde returns u such that cde(u, k) = w.
+func acde(w complex128, k float64) complex128 {
+	v := landen(k)
+	for i, vi := range v {
+		v1 := k
+		if i > 0 {
+			v1 = v[i-1]
+		}
+		cv1 := complex(v1, 0)
+		w = w / (1 + cmplx.Sqrt(1-w*w*cv1*cv1)) * complex(2/(1+vi), 0)
+	}
+	u := 2 / math.Pi * cmplx.Acos(w)
+	kk, kkp := ellipK(k)
+	r := kkp / kk
+	return complex(srem(real(u), 4), srem(imag(u), 2*r))
+}
+
+// asne returns u such that sne(u, k) = w.
+func asne(w complex128, k float64) complex128 {
+	return 1 - acde(w, k)
+}
+
+// srem returns the symmetric remainder of x modulo y, in [-y/2, y/2].
+func srem(x, y float64) float64 {
+	if math.IsInf(y, 0) || y == 0 {
+		return x
+	}
+	z := math.Remainder(x, y)
+	return z
+}
+
+// ellipDeg returns the modulus k of an elliptic filter of order n with
+// discrimination modulus k1, solving the degree equation.
+func ellipDeg(n int, k1 float64) float64 {
+	k1p := math.Sqrt((1 - k1) * (1 + k1))
+	l := n / 2
+	prod := 1.0
+	for i := 1; i <= l; i++ {
+		ui := float64(2*i-1) / float64(n)
+		prod *= real(sne(complex(ui, 0), k1p))
+	}
+	kp := math.Pow(k1p, float64(n)) * math.Pow(prod, 4)
+	return math.Sqrt((1 - kp) * (1 + kp))
+}
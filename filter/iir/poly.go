@@ -0,0 +1,153 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package iir
+
+import (
+	"math"
+	"math/cmplx"
+	"sort"
+)
+
+// Roots returns the roots of the polynomial c[0] + c[1]*x + c[2]*x^2 ...
+// whose degree is that of its highest non-zero coefficient.
+//
+// Roots are found simultaneously by the Aberth-Ehrlich method, polished by
+// Newton's method on the original coefficients and then made to occur in
+// exact conjugate pairs or be exactly real.  Roots at 0 are found exactly.
+func Roots(c []float64) []complex128 {
+	n := len(c) - 1
+	for n > 0 && c[n] == 0 {
+		n--
+	}
+	var res []complex128
+	lo := 0
+	for lo < n && c[lo] == 0 {
+		res = append(res, 0)
+		lo++
+	}
+	p := make([]complex128, n-lo+1)
+	for i := range p {
+		p[i] = complex(c[lo+i]/c[n], 0)
+	}
+	m := len(p) - 1
+	if m < 1 {
+		return res
+	}
+	zs := aberth(p)
+	for i, z := range zs {
+		for j := 0; j < 3; j++ {
+			v, d := horner(p, z)
+			if d == 0 {
+				break
+			}
+			nz := z - v/d
+			if cv, _ := horner(p, nz); cmplx.Abs(cv) >= cmplx.Abs(v) {
+				break
+			}
+			z = nz
+		}
+		zs[i] = z
+	}
+	return append(res, conjugates(zs)...)
+}
+
+// aberth returns the roots of the monic polynomial p, in ascending order.
+func aberth(p []complex128) []complex128 {
+	m := len(p) - 1
+	// initial guesses on a circle of radius the geometric mean of the
+	// magnitudes of the roots.
+	r := math.Pow(cmplx.Abs(p[0]), 1/float64(m))
+	if r == 0 || math.IsInf(r, 0) || math.IsNaN(r) {
+		r = 1
+	}
+	zs := make([]complex128, m)
+	for i := range zs {
+		zs[i] = cmplx.Rect(r, 2*math.Pi*float64(i)/float64(m)+0.4)
+	}
+	for iter := 0; iter < 500; iter++ {
+		done := true
+		for i, z := range zs {
+			v, d := horner(p, z)
+			if v == 0 {
+				continue
+			}
+			w := v / d
+			s := complex(0, 0)
+			for j, y := range zs {
+				if j != i {
+					s += 1 / (z - y)
+				}
+			}
+			dz := w / (1 - w*s)
+			zs[i] = z - dz
+			if cmplx.Abs(dz) > 1e-15*math.Max(1, cmplx.Abs(z)) {
+				done = false
+			}
+		}
+		if done {
+			break
+		}
+	}
+	return zs
+}
+
+// horner returns the value and derivative of the polynomial p at z.
+func horner(p []complex128, z complex128) (v, d complex128) {
+	for i := len(p) - 1; i >= 0; i-- {
+		d = d*z + v
+		v = v*z + p[i]
+	}
+	return v, d
+}
+
+// conjugates returns the roots rs of a real polynomial with nearly real
+// roots made real and the others matched into exact conjugate pairs.
+func conjugates(rs []complex128) []complex128 {
+	var res, up, down []complex128
+	for _, r := range rs {
+		switch {
+		case math.Abs(imag(r)) <= 1e-8*math.Max(1, cmplx.Abs(r)):
+			res = append(res, complex(real(r), 0))
+		case imag(r) > 0:
+			up = append(up, r)
+		default:
+			down = append(down, r)
+		}
+	}
+	if len(up) != len(down) {
+		return rs
+	}
+	sort.Slice(up, func(i, j int) bool { return real(up[i]) < real(up[j]) })
+	for _, u := range up {
+		best := 0
+		for j, d := range down {
+			if cmplx.Abs(d-cmplx.Conj(u)) < cmplx.Abs(down[best]-cmplx.Conj(u)) {
+				best = j
+			}
+		}
+		d := down[best]
+		down = append(down[:best], down[best+1:]...)
+		a := complex((real(u)+real(d))/2, (imag(u)-imag(d))/2)
+		res = append(res, a, cmplx.Conj(a))
+	}
+	return res
+}
+
+// poly returns the coefficients of the product of (1 - r*x) for r in rs,
+// in ascending order, which are real if the complex rs occur in conjugate
+// pairs.
+func poly(rs []complex128) []float64 {
+	c := make([]complex128, len(rs)+1)
+	c[0] = 1
+	for i, r := range rs {
+		for j := i + 1; j > 0; j-- {
+			c[j] -= r * c[j-1]
+		}
+	}
+	res := make([]float64, len(c))
+	for i, v := range c {
+		res[i] = real(v)
+	}
+	return res
+}
@@ -0,0 +1,171 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package iir
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+)
+
+func ckOrder(n int) error {
+	if n < 1 {
+		return fmt.Errorf("invalid order %d", n)
+	}
+	return nil
+}
+
+func ckDb(name string, v float64) error {
+	if !(v > 0) || math.IsInf(v, 1) {
+		return fmt.Errorf("invalid %s %f dB", name, v)
+	}
+	return nil
+}
+
+// gainTo returns the gain for which a filter with zeros zs and poles ps
+// has gain g at s = 0.
+func gainTo(g float64, zs, ps []complex128) float64 {
+	return g * real(prodNeg(ps)/prodNeg(zs))
+}
+
+// ButterAP returns the analog low pass Butterworth prototype of order n,
+// with gain 1/sqrt(2) at 1 radian per second.
+func ButterAP(n int) (ZPK, error) {
+	if e := ckOrder(n); e != nil {
+		return ZPK{}, e
+	}
+	ps := make([]complex128, n)
+	for k := range ps {
+		ps[k] = cmplx.Rect(1, math.Pi*float64(2*k+n+1)/float64(2*n))
+	}
+	return ZPK{P: ps, K: gainTo(1, nil, ps)}, nil
+}
+
+// Cheby1AP returns the analog low pass Chebyshev type I prototype of order
+// n with rp decibels of ripple in the passband, whose gain is -rp dB at 1
+// radian per second.
+func Cheby1AP(n int, rp float64) (ZPK, error) {
+	if e := ckOrder(n); e != nil {
+		return ZPK{}, e
+	}
+	if e := ckDb("passband ripple", rp); e != nil {
+		return ZPK{}, e
+	}
+	eps := math.Sqrt(math.Pow(10, rp/10) - 1)
+	mu := math.Asinh(1/eps) / float64(n)
+	ps := make([]complex128, n)
+	for k := range ps {
+		th := math.Pi * float64(2*k+1) / float64(2*n)
+		ps[k] = complex(-math.Sinh(mu)*math.Sin(th), math.Cosh(mu)*math.Cos(th))
+	}
+	g := 1.0
+	if n%2 == 0 {
+		g = 1 / math.Sqrt(1+eps*eps)
+	}
+	return ZPK{P: ps, K: gainTo(g, nil, ps)}, nil
+}
+
+// Cheby2AP returns the analog low pass Chebyshev type II prototype of
+// order n with at least rs decibels of attenuation in the stopband, which
+// starts at 1 radian per second.
+func Cheby2AP(n int, rs float64) (ZPK, error) {
+	if e := ckOrder(n); e != nil {
+		return ZPK{}, e
+	}
+	if e := ckDb("stopband attenuation", rs); e != nil {
+		return ZPK{}, e
+	}
+	eps := 1 / math.Sqrt(math.Pow(10, rs/10)-1)
+	mu := math.Asinh(1/eps) / float64(n)
+	var zs []complex128
+	ps := make([]complex128, n)
+	for k := range ps {
+		th := math.Pi * float64(2*k+1) / float64(2*n)
+		if 2*k+1 != n {
+			zs = append(zs, complex(0, 1/math.Cos(th)))
+		}
+		p := complex(-math.Sinh(mu)*math.Sin(th), math.Cosh(mu)*math.Cos(th))
+		ps[k] = 1 / p
+	}
+	return ZPK{Z: zs, P: ps, K: gainTo(1, zs, ps)}, nil
+}
+
+// EllipAP returns the analog low pass elliptic (Cauer) prototype of order n
+// with rp decibels of ripple in the passband, which ends at 1 radian per
+// second, and at least rs decibels of attenuation in the stopband.
+func EllipAP(n int, rp, rs float64) (ZPK, error) {
+	if e := ckOrder(n); e != nil {
+		return ZPK{}, e
+	}
+	if e := ckDb("passband ripple", rp); e != nil {
+		return ZPK{}, e
+	}
+	if e := ckDb("stopband attenuation", rs); e != nil {
+		return ZPK{}, e
+	}
+	if rs <= rp {
+		return ZPK{}, fmt.Errorf("stopband attenuation %f dB not greater than passband ripple %f dB", rs, rp)
+	}
+	if n == 1 {
+		// the first order elliptic filter is the first order
+		// Chebyshev type I filter.
+		return Cheby1AP(1, rp)
+	}
+	ep := math.Sqrt(math.Pow(10, rp/10) - 1)
+	es := math.Sqrt(math.Pow(10, rs/10) - 1)
+	k1 := ep / es
+	k := ellipDeg(n, k1)
+	v0 := real(-1i * asne(complex(0, 1/ep), k1) / complex(float64(n), 0))
+	var zs, ps []complex128
+	for i := 1; i <= n/2; i++ {
+		ui := float64(2*i-1) / float64(n)
+		z := complex(0, 1) / (complex(k, 0) * cde(complex(ui, 0), k))
+		p := complex(0, 1) * cde(complex(ui, -v0), k)
+		zs = append(zs, z, cmplx.Conj(z))
+		ps = append(ps, p, cmplx.Conj(p))
+	}
+	if n%2 == 1 {
+		p0 := complex(0, 1) * sne(complex(0, v0), k)
+		ps = append(ps, complex(real(p0), 0))
+	}
+	g := 1.0
+	if n%2 == 0 {
+		g = math.Pow(10, -rp/20)
+	}
+	return ZPK{Z: zs, P: ps, K: gainTo(g, zs, ps)}, nil
+}
+
+// BesselAP returns the analog low pass Bessel prototype of order n, with
+// gain 1/sqrt(2) at 1 radian per second.
+func BesselAP(n int) (ZPK, error) {
+	if e := ckOrder(n); e != nil {
+		return ZPK{}, e
+	}
+	// reverse Bessel polynomial, in ascending order.
+	c := make([]float64, n+1)
+	c[n] = 1
+	for k := n; k > 0; k-- {
+		c[k-1] = c[k] * float64((2*n-k+1)*k) / float64(2*(n-k+1))
+	}
+	ps := Roots(c)
+	f := ZPK{P: ps, K: gainTo(1, nil, ps)}
+	// find the -3dB frequency by bisection, the gain is decreasing.
+	lo, hi := 0.0, 1.0
+	for cmplx.Abs(f.Eval(complex(0, hi))) > math.Sqrt(0.5) {
+		hi *= 2
+	}
+	for i := 0; i < 100; i++ {
+		mid := (lo + hi) / 2
+		if cmplx.Abs(f.Eval(complex(0, mid))) > math.Sqrt(0.5) {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	w := (lo + hi) / 2
+	for i := range ps {
+		ps[i] /= complex(w, 0)
+	}
+	return ZPK{P: ps, K: gainTo(1, nil, ps)}, nil
+}
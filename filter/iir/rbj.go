@@ -0,0 +1,172 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package iir
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/zikichombo/sound/freq"
+)
+
+// rbj returns the cosine and sine of the normalized angular frequency of
+// f at sample rate sr, checking that f is strictly between 0 and the
+// Nyquist frequency.
+func rbj(f, sr freq.T) (cw, sw float64, e error) {
+	if sr <= 0 {
+		return 0, 0, fmt.Errorf("invalid sample rate %s", sr)
+	}
+	if f <= 0 || 2*f >= sr {
+		return 0, 0, fmt.Errorf("frequency %s not in (0, %s)", f, sr/2)
+	}
+	sw, cw = math.Sincos(2 * math.Pi * f.Float64() / sr.Float64())
+	return cw, sw, nil
+}
+
+func ckQ(q float64) error {
+	if !(q > 0) || math.IsInf(q, 1) {
+		return fmt.Errorf("invalid q %f", q)
+	}
+	return nil
+}
+
+// norm returns the coefficients divided by a0.
+func norm(b0, b1, b2, a0, a1, a2 float64) Coefs {
+	return Coefs{B0: b0 / a0, B1: b1 / a0, B2: b2 / a0, A1: a1 / a0, A2: a2 / a0}
+}
+
+// LowPass returns a second order low pass filter with cutoff f at sample
+// rate sr and quality q, which gives a Butterworth response for q =
+// 1/sqrt(2).
+func LowPass(f, sr freq.T, q float64) (Coefs, error) {
+	cw, sw, e := rbj(f, sr)
+	if e == nil {
+		e = ckQ(q)
+	}
+	if e != nil {
+		return Coefs{}, e
+	}
+	al := sw / (2 * q)
+	return norm((1-cw)/2, 1-cw, (1-cw)/2, 1+al, -2*cw, 1-al), nil
+}
+
+// HighPass returns a second order high pass filter as in LowPass.
+func HighPass(f, sr freq.T, q float64) (Coefs, error) {
+	cw, sw, e := rbj(f, sr)
+	if e == nil {
+		e = ckQ(q)
+	}
+	if e != nil {
+		return Coefs{}, e
+	}
+	al := sw / (2 * q)
+	return norm((1+cw)/2, -(1 + cw), (1+cw)/2, 1+al, -2*cw, 1-al), nil
+}
+
+// BandPass returns a band pass filter centered at f at sample rate sr with
+// a gain of 1 at f and quality q.
+func BandPass(f, sr freq.T, q float64) (Coefs, error) {
+	cw, sw, e := rbj(f, sr)
+	if e == nil {
+		e = ckQ(q)
+	}
+	if e != nil {
+		return Coefs{}, e
+	}
+	al := sw / (2 * q)
+	return norm(al, 0, -al, 1+al, -2*cw, 1-al), nil
+}
+
+// Notch returns a filter rejecting frequency f at sample rate sr with
+// quality q.
+func Notch(f, sr freq.T, q float64) (Coefs, error) {
+	cw, sw, e := rbj(f, sr)
+	if e == nil {
+		e = ckQ(q)
+	}
+	if e != nil {
+		return Coefs{}, e
+	}
+	al := sw / (2 * q)
+	return norm(1, -2*cw, 1, 1+al, -2*cw, 1-al), nil
+}
+
+// AllPass returns a filter with gain 1 whose phase shift passes through
+// -pi at frequency f at sample rate sr, with quality q.
+func AllPass(f, sr freq.T, q float64) (Coefs, error) {
+	cw, sw, e := rbj(f, sr)
+	if e == nil {
+		e = ckQ(q)
+	}
+	if e != nil {
+		return Coefs{}, e
+	}
+	al := sw / (2 * q)
+	return norm(1-al, -2*cw, 1+al, 1+al, -2*cw, 1-al), nil
+}
+
+// Peak returns a peaking equalizer with gain db decibels at frequency f at
+// sample rate sr, quality q and gain 1 far from f.
+func Peak(f, sr freq.T, q, db float64) (Coefs, error) {
+	cw, sw, e := rbj(f, sr)
+	if e == nil {
+		e = ckQ(q)
+	}
+	if e != nil {
+		return Coefs{}, e
+	}
+	a := math.Pow(10, db/40)
+	al := sw / (2 * q)
+	return norm(1+al*a, -2*cw, 1-al*a, 1+al/a, -2*cw, 1-al/a), nil
+}
+
+// shelf returns the cosine of the normalized frequency, the amplitude and
+// the term 2*sqrt(A)*alpha of a shelf.
+func shelf(f, sr freq.T, slope, db float64) (cw, a, sa float64, e error) {
+	cw, sw, e := rbj(f, sr)
+	if e != nil {
+		return 0, 0, 0, e
+	}
+	a = math.Pow(10, db/40)
+	v := (a+1/a)*(1/slope-1) + 2
+	if !(slope > 0) || v < 0 {
+		return 0, 0, 0, fmt.Errorf("invalid slope %f for gain %f", slope, db)
+	}
+	al := sw / 2 * math.Sqrt(v)
+	return cw, a, 2 * math.Sqrt(a) * al, nil
+}
+
+// LowShelf returns a filter with gain db decibels below frequency f at
+// sample rate sr and gain 1 above it.  The slope gives the steepness of
+// the transition, with 1 giving the steepest transition for which the
+// gain is monotonic.
+func LowShelf(f, sr freq.T, slope, db float64) (Coefs, error) {
+	cw, a, sa, e := shelf(f, sr, slope, db)
+	if e != nil {
+		return Coefs{}, e
+	}
+	return norm(
+		a*((a+1)-(a-1)*cw+sa),
+		2*a*((a-1)-(a+1)*cw),
+		a*((a+1)-(a-1)*cw-sa),
+		(a+1)+(a-1)*cw+sa,
+		-2*((a-1)+(a+1)*cw),
+		(a+1)+(a-1)*cw-sa), nil
+}
+
+// HighShelf returns a filter with gain db decibels above frequency f at
+// sample rate sr and gain 1 below it, as in LowShelf.
+func HighShelf(f, sr freq.T, slope, db float64) (Coefs, error) {
+	cw, a, sa, e := shelf(f, sr, slope, db)
+	if e != nil {
+		return Coefs{}, e
+	}
+	return norm(
+		a*((a+1)+(a-1)*cw+sa),
+		-2*a*((a-1)+(a+1)*cw),
+		a*((a+1)+(a-1)*cw-sa),
+		(a+1)-(a-1)*cw+sa,
+		2*((a-1)-(a+1)*cw),
+		(a+1)-(a-1)*cw-sa), nil
+}
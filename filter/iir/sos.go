@@ -0,0 +1,98 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package iir
+
+import "fmt"
+
+// SOS represents a digital filter as a cascade of second order sections.
+type SOS []Coefs
+
+// Eval returns the transfer function of s at z.
+func (s SOS) Eval(z complex128) complex128 {
+	res := complex(1, 0)
+	zi := 1 / z
+	zi2 := zi * zi
+	for _, c := range s {
+		num := complex(c.B0, 0) + complex(c.B1, 0)*zi + complex(c.B2, 0)*zi2
+		den := 1 + complex(c.A1, 0)*zi + complex(c.A2, 0)*zi2
+		res *= num / den
+	}
+	return res
+}
+
+// Cascade filters one or more channels with a cascade of biquads.
+type Cascade struct {
+	bs []*Biquad
+	nC int
+}
+
+// NewCascade creates a new Cascade of the sections s for nC channels,
+// which must be positive or NewCascade panics.
+func NewCascade(s SOS, nC int) *Cascade {
+	if nC < 1 {
+		panic("non-positive number of channels")
+	}
+	res := &Cascade{bs: make([]*Biquad, len(s)), nC: nC}
+	for i, c := range s {
+		res.bs[i] = NewBiquad(c, nC)
+	}
+	return res
+}
+
+// Channels returns the number of channels of c.
+func (c *Cascade) Channels() int {
+	return c.nC
+}
+
+// SOS returns the sections of c.
+func (c *Cascade) SOS() SOS {
+	res := make(SOS, len(c.bs))
+	for i, b := range c.bs {
+		res[i] = b.Coefs()
+	}
+	return res
+}
+
+// SetForm sets the form of each biquad of c and resets its state.
+func (c *Cascade) SetForm(f Form) {
+	for _, b := range c.bs {
+		b.SetForm(f)
+	}
+}
+
+// Reset sets the state of c to that of silence.
+func (c *Cascade) Reset() {
+	for _, b := range c.bs {
+		b.Reset()
+	}
+}
+
+// Process filters the samples d of channel 0 of c in place.
+func (c *Cascade) Process(d []float64) {
+	c.ProcessChannel(0, d)
+}
+
+// ProcessChannel filters the samples d of channel ch of c in place.
+//
+// ProcessChannel panics if ch is not a channel of c.
+func (c *Cascade) ProcessChannel(ch int, d []float64) {
+	for _, b := range c.bs {
+		b.ProcessChannel(ch, d)
+	}
+}
+
+// ProcessInterleaved filters the interleaved samples d of all channels of
+// c in place.
+//
+// ProcessInterleaved returns a non-nil error if the length of d is not a
+// multiple of the number of channels.
+func (c *Cascade) ProcessInterleaved(d []float64) error {
+	if len(d)%c.nC != 0 {
+		return fmt.Errorf("wrong length %d for %d channels", len(d), c.nC)
+	}
+	for _, b := range c.bs {
+		b.ProcessInterleaved(d)
+	}
+	return nil
+}
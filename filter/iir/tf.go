@@ -0,0 +1,247 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package iir
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+)
+
+// TF represents a digital filter by the coefficients of the numerator and
+// denominator of its transfer function,
+//
+//	H(z) = (B[0] + B[1]/z + B[2]/z^2 ...) / (A[0] + A[1]/z + A[2]/z^2 ...)
+//
+// A filter is causal if A[0] is not 0.
+type TF struct {
+	B, A []float64
+}
+
+// Eval returns H(z).
+func (t TF) Eval(z complex128) complex128 {
+	return evalInv(t.B, z) / evalInv(t.A, z)
+}
+
+// evalInv returns the sum of c[i]/z^i.
+func evalInv(c []float64, z complex128) complex128 {
+	zi := 1 / z
+	res := complex(0, 0)
+	for i := len(c) - 1; i >= 0; i-- {
+		res = res*zi + complex(c[i], 0)
+	}
+	return res
+}
+
+// lead returns the index of the first non-zero element of c, or len(c).
+func lead(c []float64) int {
+	for i, v := range c {
+		if v != 0 {
+			return i
+		}
+	}
+	return len(c)
+}
+
+func (t TF) ck() error {
+	if lead(t.A) == len(t.A) {
+		return fmt.Errorf("zero denominator")
+	}
+	return nil
+}
+
+// ZPK returns the zeros, poles and gain of t.  Leading zero coefficients
+// of B or A are delays, which appear as fewer zeros or poles.
+//
+// ZPK returns a non-nil error if A is zero.
+func (t TF) ZPK() (ZPK, error) {
+	if e := t.ck(); e != nil {
+		return ZPK{}, e
+	}
+	n := len(t.B)
+	if len(t.A) > n {
+		n = len(t.A)
+	}
+	n--
+	// multiplying by z^n gives polynomials in z.
+	asc := func(c []float64) []float64 {
+		res := make([]float64, n+1)
+		for i, v := range c {
+			res[n-i] = v
+		}
+		return res
+	}
+	lb, la := lead(t.B), lead(t.A)
+	if lb == len(t.B) {
+		return ZPK{}, nil
+	}
+	return ZPK{
+		Z: Roots(asc(t.B)),
+		P: Roots(asc(t.A)),
+		K: t.B[lb] / t.A[la]}, nil
+}
+
+// SOS returns t as second order sections, as in ZPK and ZPK.SOS.
+func (t TF) SOS() (SOS, error) {
+	z, e := t.ZPK()
+	if e != nil {
+		return nil, e
+	}
+	return z.SOS(), nil
+}
+
+// Stable returns whether t is causal with all poles strictly inside the
+// unit circle, determined by the Schur-Cohn stability test without finding
+// roots.
+func (t TF) Stable() bool {
+	if len(t.A) == 0 || t.A[0] == 0 {
+		return false
+	}
+	n := len(t.A) - 1
+	for n > 0 && t.A[n] == 0 {
+		n--
+	}
+	a := make([]float64, n+1)
+	for i := range a {
+		a[i] = t.A[i] / t.A[0]
+	}
+	b := make([]float64, n+1)
+	// step down through the reflection coefficients.
+	for m := n; m > 0; m-- {
+		k := a[m]
+		if !(math.Abs(k) < 1) {
+			return false
+		}
+		d := 1 - k*k
+		for i := 0; i <= m; i++ {
+			b[i] = (a[i] - k*a[m-i]) / d
+		}
+		copy(a, b[:m])
+	}
+	return true
+}
+
+// MinPhase returns the minimum phase filter with the same magnitude
+// response as t, as in ZPK.MinPhase.
+//
+// MinPhase returns a non-nil error if A is zero.
+func (t TF) MinPhase() (TF, error) {
+	z, e := t.ZPK()
+	if e != nil {
+		return TF{}, e
+	}
+	return z.MinPhase().TF(), nil
+}
+
+// TF returns f in transfer function form.  If f has fewer zeros than poles,
+// B starts with the corresponding zeros, and if f has more zeros than
+// poles, A does and the result is not causal.
+func (f ZPK) TF() TF {
+	b := poly(f.Z)
+	for i := range b {
+		b[i] *= f.K
+	}
+	a := poly(f.P)
+	if d := len(a) - len(b); d > 0 {
+		b = append(make([]float64, d), b...)
+	} else if d < 0 {
+		a = append(make([]float64, -d), a...)
+	}
+	return TF{B: b, A: a}
+}
+
+// Stable returns whether the digital filter f has all poles strictly inside
+// the unit circle.
+func (f ZPK) Stable() bool {
+	for _, p := range f.P {
+		if !(cmplx.Abs(p) < 1) {
+			return false
+		}
+	}
+	return true
+}
+
+// StableAnalog returns whether the analog filter f has all poles strictly
+// in the left half plane.
+func (f ZPK) StableAnalog() bool {
+	for _, p := range f.P {
+		if !(real(p) < 0) {
+			return false
+		}
+	}
+	return true
+}
+
+// MinPhase returns the minimum phase digital filter with the same magnitude
+// response as f, obtained by reflecting the zeros of f outside the unit
+// circle to their conjugate reciprocals inside it and adjusting the gain.
+// Zeros on the unit circle are kept.
+func (f ZPK) MinPhase() ZPK {
+	res := ZPK{Z: make([]complex128, len(f.Z)), P: append([]complex128{}, f.P...), K: f.K}
+	for i, z := range f.Z {
+		if m := cmplx.Abs(z); m > 1 {
+			res.Z[i] = 1 / cmplx.Conj(z)
+			res.K *= m
+			continue
+		}
+		res.Z[i] = z
+	}
+	return res
+}
+
+// ZPK returns the zeros, poles and gain of s.  Sections with trailing zero
+// coefficients in both numerator and denominator are taken to be of first
+// order.
+func (s SOS) ZPK() ZPK {
+	res := ZPK{K: 1}
+	for _, c := range s {
+		b := []float64{c.B0, c.B1, c.B2}
+		a := []float64{1, c.A1, c.A2}
+		for len(b) > 1 && b[len(b)-1] == 0 && a[len(a)-1] == 0 {
+			b, a = b[:len(b)-1], a[:len(a)-1]
+		}
+		z, _ := TF{B: b, A: a}.ZPK()
+		res.Z = append(res.Z, z.Z...)
+		res.P = append(res.P, z.P...)
+		res.K *= z.K
+	}
+	return res
+}
+
+// TF returns s in transfer function form.
+func (s SOS) TF() TF {
+	b, a := []float64{1}, []float64{1}
+	for _, c := range s {
+		b = polyMul(b, []float64{c.B0, c.B1, c.B2})
+		a = polyMul(a, []float64{1, c.A1, c.A2})
+	}
+	return TF{B: b, A: a}
+}
+
+// Stable returns whether every section of s has poles strictly inside the
+// unit circle.
+func (s SOS) Stable() bool {
+	for _, c := range s {
+		if !(math.Abs(c.A2) < 1 && math.Abs(c.A1) < 1+c.A2) {
+			return false
+		}
+	}
+	return true
+}
+
+// MinPhase returns the minimum phase filter with the same magnitude
+// response as s, as in ZPK.MinPhase.
+func (s SOS) MinPhase() SOS {
+	return s.ZPK().MinPhase().SOS()
+}
+
+func polyMul(a, b []float64) []float64 {
+	res := make([]float64, len(a)+len(b)-1)
+	for i, u := range a {
+		for j, v := range b {
+			res[i+j] += u * v
+		}
+	}
+	return res
+}
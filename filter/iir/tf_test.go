@@ -0,0 +1,161 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package iir
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+
+	"github.com/zikichombo/sound/freq"
+)
+
+func TestRoots(t *testing.T) {
+	rs := []complex128{0, 0.5, -2, complex(0.3, 0.9), complex(0.3, -0.9), complex(-1, 1e-3), complex(-1, -1e-3), 3}
+	c := poly(rs)
+	// poly gives the reversed polynomial, whose roots are 1/r.
+	for i, j := 0, len(c)-1; i < j; i, j = i+1, j-1 {
+		c[i], c[j] = c[j], c[i]
+	}
+	got := Roots(c)
+	if len(got) != len(rs) {
+		t.Fatalf("got %d roots not %d", len(got), len(rs))
+	}
+	for _, r := range rs {
+		best := math.Inf(1)
+		for _, g := range got {
+			best = math.Min(best, cmplx.Abs(g-r))
+		}
+		if best > 1e-6 {
+			t.Errorf("root %v not found in %v", r, got)
+		}
+	}
+	for _, g := range got {
+		if imag(g) == 0 {
+			continue
+		}
+		n := 0
+		for _, h := range got {
+			if h == cmplx.Conj(g) {
+				n++
+			}
+		}
+		if n != 1 {
+			t.Errorf("root %v has no exact conjugate", g)
+		}
+	}
+	if got := Roots([]float64{2, 0, 0}); len(got) != 0 {
+		t.Errorf("constant: got roots %v", got)
+	}
+}
+
+func evalEq(t *testing.T, name string, a, b func(complex128) complex128, tol float64) {
+	for _, f := range []float64{0, 100, 1234, 5000, 17000} {
+		z := cmplx.Rect(1, 2*math.Pi*f/sr.Float64())
+		if u, v := a(z), b(z); cmplx.Abs(u-v) > tol*math.Max(1, cmplx.Abs(u)) {
+			t.Errorf("%s: got %v not %v at %f", name, v, u, f)
+		}
+	}
+}
+
+func TestConversions(t *testing.T) {
+	s, e := Ellip(6, 0.5, 60, Pass, sr, 2000*freq.Hertz, 5000*freq.Hertz)
+	if e != nil {
+		t.Fatal(e)
+	}
+	tf := s.TF()
+	if len(tf.B) != 13 || len(tf.A) != 13 {
+		t.Fatalf("got lengths %d %d", len(tf.B), len(tf.A))
+	}
+	// high order transfer functions are badly conditioned.
+	evalEq(t, "sos tf", s.Eval, tf.Eval, 1e-6)
+	z, e := tf.ZPK()
+	if e != nil {
+		t.Fatal(e)
+	}
+	if len(z.Z) != 12 || len(z.P) != 12 {
+		t.Errorf("got %d zeros %d poles", len(z.Z), len(z.P))
+	}
+	evalEq(t, "tf zpk", tf.Eval, z.Eval, 1e-6)
+	evalEq(t, "sos zpk", s.Eval, s.ZPK().Eval, 1e-9)
+	s2, e := tf.SOS()
+	if e != nil {
+		t.Fatal(e)
+	}
+	evalEq(t, "tf sos", tf.Eval, s2.Eval, 1e-6)
+	evalEq(t, "zpk tf", z.Eval, z.TF().Eval, 1e-6)
+
+	// delays and first order sections.
+	d := TF{B: []float64{0, 0, 1, 0.5}, A: []float64{1, -0.9}}
+	dz, e := d.ZPK()
+	if e != nil {
+		t.Fatal(e)
+	}
+	if len(dz.Z) != 1 || len(dz.P) != 3 {
+		t.Errorf("delay: got %d zeros %d poles", len(dz.Z), len(dz.P))
+	}
+	evalEq(t, "delay zpk", d.Eval, dz.Eval, 1e-9)
+	ds := dz.SOS()
+	evalEq(t, "delay sos", d.Eval, ds.Eval, 1e-9)
+	evalEq(t, "delay sos zpk", d.Eval, ds.ZPK().Eval, 1e-9)
+	evalEq(t, "delay sos tf", d.Eval, ds.TF().Eval, 1e-9)
+	if _, e := (TF{B: []float64{1}, A: []float64{0}}).ZPK(); e == nil {
+		t.Errorf("expected zero denominator error")
+	}
+}
+
+func TestStable(t *testing.T) {
+	s, e := Cheby1(8, 1, Low, sr, 500*freq.Hertz)
+	if e != nil {
+		t.Fatal(e)
+	}
+	tf := s.TF()
+	z, _ := tf.ZPK()
+	if !s.Stable() || !tf.Stable() || !z.Stable() {
+		t.Errorf("got unstable %t %t %t", s.Stable(), tf.Stable(), z.Stable())
+	}
+	for _, a := range [][]float64{{1, -1.1}, {1, 0, 1}, {1, -2.5, 1}, {1, 0.5, -0.6}, {0, 1}} {
+		u := TF{B: []float64{1}, A: a}
+		if u.Stable() {
+			t.Errorf("%v: got stable", a)
+		}
+		if uz, e := u.ZPK(); e == nil && uz.Stable() && a[0] != 0 {
+			t.Errorf("%v: zpk got stable", a)
+		}
+	}
+	if !(TF{B: []float64{1}, A: []float64{2, -1.8, 0.9}}).Stable() {
+		t.Errorf("expected stable")
+	}
+	ap, _ := ButterAP(4)
+	if !ap.StableAnalog() {
+		t.Errorf("expected analog stable")
+	}
+	ap.P[0] = -ap.P[0]
+	if ap.StableAnalog() {
+		t.Errorf("expected analog unstable")
+	}
+}
+
+func TestMinPhase(t *testing.T) {
+	tf := TF{B: []float64{1, -2.5, 1, 3}, A: []float64{1, -0.5, 0.2}}
+	m, e := tf.MinPhase()
+	if e != nil {
+		t.Fatal(e)
+	}
+	mz, _ := m.ZPK()
+	for _, z := range mz.Z {
+		if cmplx.Abs(z) > 1+1e-9 {
+			t.Errorf("zero %v outside unit circle", z)
+		}
+	}
+	for _, f := range []float64{0, 700, 3000, 11000, 23000} {
+		z := cmplx.Rect(1, 2*math.Pi*f/sr.Float64())
+		if a, b := cmplx.Abs(tf.Eval(z)), cmplx.Abs(m.Eval(z)); math.Abs(a-b) > 1e-9*a {
+			t.Errorf("got magnitude %f not %f at %f", b, a, f)
+		}
+	}
+	s, _ := tf.SOS()
+	ms := s.MinPhase()
+	evalEq(t, "sos min phase", m.Eval, ms.Eval, 1e-9)
+}
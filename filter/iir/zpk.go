@@ -0,0 +1,320 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package iir
+
+import (
+	"math"
+	"math/cmplx"
+	"sort"
+)
+
+// ZPK represents a filter by the zeros and poles of its transfer function
+// and a gain,
+//
+//	H(s) = K * (s-Z[0])*(s-Z[1])... / ((s-P[0])*(s-P[1])...)
+//
+// An analog filter has s the complex angular frequency in radians per
+// second and a digital filter has s = z.  Complex zeros and poles of
+// real filters occur in conjugate pairs.
+type ZPK struct {
+	Z, P []complex128
+	K    float64
+}
+
+// Eval returns H(s).
+func (f ZPK) Eval(s complex128) complex128 {
+	res := complex(f.K, 0)
+	for _, z := range f.Z {
+		res *= s - z
+	}
+	for _, p := range f.P {
+		res /= s - p
+	}
+	return res
+}
+
+// Order returns the order of f, the larger of the number of zeros and the
+// number of poles.
+func (f ZPK) Order() int {
+	if len(f.Z) > len(f.P) {
+		return len(f.Z)
+	}
+	return len(f.P)
+}
+
+// prodNeg returns the product of -r for r in rs.
+func prodNeg(rs []complex128) complex128 {
+	res := complex(1, 0)
+	for _, r := range rs {
+		res *= -r
+	}
+	return res
+}
+
+// LowToLow returns the analog low pass filter with cutoff w radians per
+// second from the analog low pass prototype f with cutoff 1.
+func (f ZPK) LowToLow(w float64) ZPK {
+	res := ZPK{Z: make([]complex128, len(f.Z)), P: make([]complex128, len(f.P))}
+	cw := complex(w, 0)
+	for i, z := range f.Z {
+		res.Z[i] = z * cw
+	}
+	for i, p := range f.P {
+		res.P[i] = p * cw
+	}
+	res.K = f.K * math.Pow(w, float64(len(f.P)-len(f.Z)))
+	return res
+}
+
+// LowToHigh returns the analog high pass filter with cutoff w radians per
+// second from the analog low pass prototype f with cutoff 1.
+func (f ZPK) LowToHigh(w float64) ZPK {
+	deg := len(f.P) - len(f.Z)
+	res := ZPK{Z: make([]complex128, 0, len(f.Z)+deg), P: make([]complex128, len(f.P))}
+	cw := complex(w, 0)
+	for _, z := range f.Z {
+		res.Z = append(res.Z, cw/z)
+	}
+	for i, p := range f.P {
+		res.P[i] = cw / p
+	}
+	for i := 0; i < deg; i++ {
+		res.Z = append(res.Z, 0)
+	}
+	res.K = f.K * real(prodNeg(f.Z)/prodNeg(f.P))
+	return res
+}
+
+// LowToBand returns the analog band pass filter with center w and
+// bandwidth bw radians per second from the analog low pass prototype f
+// with cutoff 1.
+func (f ZPK) LowToBand(w, bw float64) ZPK {
+	deg := len(f.P) - len(f.Z)
+	h := complex(bw/2, 0)
+	w2 := complex(w*w, 0)
+	split := func(rs []complex128) []complex128 {
+		res := make([]complex128, 0, 2*len(rs)+deg)
+		for _, r := range rs {
+			a := r * h
+			d := cmplx.Sqrt(a*a - w2)
+			res = append(res, a+d, a-d)
+		}
+		return res
+	}
+	res := ZPK{Z: split(f.Z), P: split(f.P)}
+	for i := 0; i < deg; i++ {
+		res.Z = append(res.Z, 0)
+	}
+	res.K = f.K * math.Pow(bw, float64(deg))
+	return res
+}
+
+// LowToStop returns the analog band stop filter with center w and
+// bandwidth bw radians per second from the analog low pass prototype f
+// with cutoff 1.
+func (f ZPK) LowToStop(w, bw float64) ZPK {
+	deg := len(f.P) - len(f.Z)
+	h := complex(bw/2, 0)
+	w2 := complex(w*w, 0)
+	split := func(rs []complex128) []complex128 {
+		res := make([]complex128, 0, 2*len(rs)+2*deg)
+		for _, r := range rs {
+			a := h / r
+			d := cmplx.Sqrt(a*a - w2)
+			res = append(res, a+d, a-d)
+		}
+		return res
+	}
+	res := ZPK{Z: split(f.Z), P: split(f.P)}
+	for i := 0; i < deg; i++ {
+		res.Z = append(res.Z, complex(0, w), complex(0, -w))
+	}
+	res.K = f.K * real(prodNeg(f.Z)/prodNeg(f.P))
+	return res
+}
+
+// Bilinear returns the digital filter corresponding to the analog filter f
+// by the bilinear transform s = 2*fs*(z-1)/(z+1) at sample rate fs Hertz.
+// Zeros at infinity map to -1.
+func (f ZPK) Bilinear(fs float64) ZPK {
+	deg := len(f.P) - len(f.Z)
+	fs2 := complex(2*fs, 0)
+	res := ZPK{Z: make([]complex128, 0, len(f.Z)+deg), P: make([]complex128, len(f.P))}
+	num, den := complex(1, 0), complex(1, 0)
+	for _, z := range f.Z {
+		res.Z = append(res.Z, (fs2+z)/(fs2-z))
+		num *= fs2 - z
+	}
+	for i, p := range f.P {
+		res.P[i] = (fs2 + p) / (fs2 - p)
+		den *= fs2 - p
+	}
+	for i := 0; i < deg; i++ {
+		res.Z = append(res.Z, -1)
+	}
+	res.K = f.K * real(num/den)
+	return res
+}
+
+// Prewarp returns the analog angular frequency in radians per second which
+// the bilinear transform at sample rate fs Hertz maps to f Hertz.
+func Prewarp(f, fs float64) float64 {
+	return 2 * fs * math.Tan(math.Pi*f/fs)
+}
+
+// SOS returns the digital filter f as second order sections.  Poles are
+// paired with their conjugates, or with other real poles, and each pair of
+// poles is matched with the nearest zeros.  Sections are ordered with the
+// poles closest to the unit circle last, and the gain of f is applied to
+// the first section.
+//
+// If f has fewer zeros than poles, the sections include the corresponding
+// delay.  If f has more zeros than poles, poles at 0 are added, so that the
+// sections give f delayed to be causal.
+func (f ZPK) SOS() SOS {
+	zs := append([]complex128{}, f.Z...)
+	ps := append([]complex128{}, f.P...)
+	for len(zs) < len(ps) {
+		// a zero at infinity is a factor 1/z.
+		zs = append(zs, complex(math.Inf(1), 0))
+	}
+	for len(ps) < len(zs) {
+		ps = append(ps, 0)
+	}
+	zu := units(zs)
+	pu := units(ps)
+	// pair real pole units into sections of two poles.
+	sort.Slice(pu, func(i, j int) bool {
+		return cmplx.Abs(pu[i][0]) > cmplx.Abs(pu[j][0])
+	})
+	var secs [][2][]complex128
+	for len(pu) > 0 {
+		p := pu[0]
+		pu = pu[1:]
+		if len(p) == 1 {
+			for i, q := range pu {
+				if len(q) == 1 {
+					p = []complex128{p[0], q[0]}
+					pu = append(pu[:i:i], pu[i+1:]...)
+					break
+				}
+			}
+		}
+		var z []complex128
+		z, zu = nearest(zu, p[0], len(p))
+		secs = append(secs, [2][]complex128{z, p})
+	}
+	res := make(SOS, len(secs))
+	for i := range secs {
+		s := secs[len(secs)-1-i]
+		res[i] = section(s[0], s[1])
+	}
+	if len(res) == 0 {
+		return SOS{{B0: f.K}}
+	}
+	res[0].B0 *= f.K
+	res[0].B1 *= f.K
+	res[0].B2 *= f.K
+	return res
+}
+
+// rootTol is the relative size of the imaginary part below which a root is
+// considered real.
+const rootTol = 1e-9
+
+func isReal(r complex128) bool {
+	return math.Abs(imag(r)) <= rootTol*math.Max(1, cmplx.Abs(r))
+}
+
+// units groups roots into conjugate pairs, represented by the root with
+// positive imaginary part, and real roots.
+func units(rs []complex128) [][]complex128 {
+	var res [][]complex128
+	for _, r := range rs {
+		if isReal(r) {
+			res = append(res, []complex128{complex(real(r), 0)})
+			continue
+		}
+		if imag(r) > 0 {
+			res = append(res, []complex128{r, cmplx.Conj(r)})
+		}
+	}
+	return res
+}
+
+// nearest removes and returns n roots from us closest to p, taking either
+// a conjugate pair or up to n real roots.
+func nearest(us [][]complex128, p complex128, n int) ([]complex128, [][]complex128) {
+	best := -1
+	for i, u := range us {
+		if len(u) > n {
+			continue
+		}
+		if n == 2 && len(u) == 1 && nReal(us) < 2 {
+			continue
+		}
+		if best == -1 || cmplx.Abs(u[0]-p) < cmplx.Abs(us[best][0]-p) {
+			best = i
+		}
+	}
+	if best == -1 {
+		return nil, us
+	}
+	u := us[best]
+	us = append(us[:best:best], us[best+1:]...)
+	if len(u) < n {
+		var v []complex128
+		v, us = nearest(us, p, n-len(u))
+		u = append(u, v...)
+	}
+	return u, us
+}
+
+func nReal(us [][]complex128) int {
+	res := 0
+	for _, u := range us {
+		if len(u) == 1 {
+			res++
+		}
+	}
+	return res
+}
+
+// section returns the biquad with up to two zeros and poles.
+func section(z, p []complex128) Coefs {
+	b0, b1, b2 := zeroCoefs(z)
+	a1, a2 := coefs(p)
+	return Coefs{B0: b0, B1: b1, B2: b2, A1: a1, A2: a2}
+}
+
+// coefs returns the coefficients of 1/z and 1/z^2 of the product of
+// (1-r/z) for up to two roots r.
+func coefs(rs []complex128) (c1, c2 float64) {
+	switch len(rs) {
+	case 1:
+		return -real(rs[0]), 0
+	case 2:
+		return -real(rs[0] + rs[1]), real(rs[0] * rs[1])
+	}
+	return 0, 0
+}
+
+// zeroCoefs returns the coefficients of 1, 1/z and 1/z^2 of the product of
+// (1-r/z) for up to two zeros r, where a zero at infinity gives 1/z.
+func zeroCoefs(rs []complex128) (c0, c1, c2 float64) {
+	var fin []complex128
+	for _, r := range rs {
+		if !cmplx.IsInf(r) {
+			fin = append(fin, r)
+		}
+	}
+	c1, c2 = coefs(fin)
+	switch len(rs) - len(fin) {
+	case 1:
+		return 0, 1, c1
+	case 2:
+		return 0, 0, 1
+	}
+	return 1, c1, c2
+}
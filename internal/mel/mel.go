@@ -0,0 +1,44 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+// Package mel provides the mel scales shared by the packages of dsp.
+package mel
+
+import "math"
+
+// FromHz returns the frequency f in Hertz in mels on the scale
+// 2595*log10(1+f/700) used by HTK.
+func FromHz(f float64) float64 {
+	return 2595 * math.Log10(1+f/700)
+}
+
+// ToHz returns the frequency in Hertz of m mels on the scale of FromHz.
+func ToHz(m float64) float64 {
+	return 700 * (math.Pow(10, m/2595) - 1)
+}
+
+const (
+	slaneyLin     = 200.0 / 3 // Hz per mel below slaneyBreak
+	slaneyBreak   = 1000.0
+	slaneyBreakMl = slaneyBreak / slaneyLin
+)
+
+var slaneyLogStep = math.Log(6.4) / 27
+
+// SlaneyFromHz returns the frequency f in Hertz in mels on the scale of
+// Slaney's Auditory Toolbox, linear below 1kHz and logarithmic above.
+func SlaneyFromHz(f float64) float64 {
+	if f < slaneyBreak {
+		return f / slaneyLin
+	}
+	return slaneyBreakMl + math.Log(f/slaneyBreak)/slaneyLogStep
+}
+
+// SlaneyToHz returns the frequency in Hertz of m mels on the scale of
+// SlaneyFromHz.
+func SlaneyToHz(m float64) float64 {
+	if m < slaneyBreakMl {
+		return m * slaneyLin
+	}
+	return slaneyBreak * math.Exp((m-slaneyBreakMl)*slaneyLogStep)
+}
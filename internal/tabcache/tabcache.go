@@ -0,0 +1,124 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+// Package tabcache provides the bounded cache of immutable tables shared
+// by the transforms of package fft and those built on them.
+//
+// Tables are keyed by comparable values; packages use their own key types
+// so that keys of different packages never collide.  The cache is bounded
+// by the number of bytes of tables it holds, evicting the least recently
+// used tables first.  Evicted tables remain valid for those holding them.
+package tabcache
+
+import (
+	"container/list"
+	"sync"
+)
+
+// DefaultLimit is the default bound on the number of bytes of tables held
+// in the cache.
+const DefaultLimit = 64 << 20
+
+type entry struct {
+	key  interface{}
+	val  interface{}
+	size int
+}
+
+var cache = struct {
+	sync.Mutex
+	m            map[interface{}]*list.Element
+	lru          list.List // front is most recently used
+	size, limit  int
+	hits, misses uint64
+}{
+	m:     make(map[interface{}]*list.Element),
+	limit: DefaultLimit}
+
+// Stats gives statistics for the cache.
+type Stats struct {
+	Hits    uint64 // number of tables found in the cache
+	Misses  uint64 // number of tables computed
+	Entries int    // number of tables in the cache
+	Bytes   int    // size of tables in the cache
+	Limit   int    // bound on Bytes
+}
+
+// ReadStats returns the current statistics of the cache.
+func ReadStats() Stats {
+	cache.Lock()
+	defer cache.Unlock()
+	return Stats{
+		Hits:    cache.hits,
+		Misses:  cache.misses,
+		Entries: cache.lru.Len(),
+		Bytes:   cache.size,
+		Limit:   cache.limit}
+}
+
+// SetLimit sets the bound on the number of bytes of tables held in the
+// cache to b, evicting tables as needed, and returns the previous bound.
+// If b <= 0, then tables are no longer cached.
+func SetLimit(b int) int {
+	cache.Lock()
+	defer cache.Unlock()
+	old := cache.limit
+	if b < 0 {
+		b = 0
+	}
+	cache.limit = b
+	evict()
+	return old
+}
+
+// Purge removes all tables from the cache and resets the hit and miss
+// counts.
+func Purge() {
+	cache.Lock()
+	defer cache.Unlock()
+	cache.m = make(map[interface{}]*list.Element)
+	cache.lru.Init()
+	cache.size = 0
+	cache.hits, cache.misses = 0, 0
+}
+
+// Get returns the table for k, calling f to compute it and its size in
+// bytes if it is not in the cache.  k must be comparable.
+func Get(k interface{}, f func() (interface{}, int)) interface{} {
+	cache.Lock()
+	if e, ok := cache.m[k]; ok {
+		cache.hits++
+		cache.lru.MoveToFront(e)
+		cache.Unlock()
+		return e.Value.(*entry).val
+	}
+	cache.misses++
+	cache.Unlock()
+	// compute without holding the lock, another goroutine
+	// may compute the same table at the same time.
+	v, sz := f()
+	cache.Lock()
+	defer cache.Unlock()
+	if e, ok := cache.m[k]; ok {
+		cache.lru.MoveToFront(e)
+		return e.Value.(*entry).val
+	}
+	if sz > cache.limit {
+		return v
+	}
+	cache.m[k] = cache.lru.PushFront(&entry{key: k, val: v, size: sz})
+	cache.size += sz
+	evict()
+	return v
+}
+
+// evict removes least recently used tables until the cache is within its
+// limit.  The cache must be locked.
+func evict() {
+	for cache.size > cache.limit {
+		e := cache.lru.Back()
+		ent := cache.lru.Remove(e).(*entry)
+		delete(cache.m, ent.key)
+		cache.size -= ent.size
+	}
+}
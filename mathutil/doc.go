@@ -0,0 +1,6 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+// Package mathutil provides small mathematical helpers shared by the dsp
+// packages.
+package mathutil
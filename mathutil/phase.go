@@ -0,0 +1,11 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package mathutil
+
+import "math"
+
+// PrincArg returns the principal argument of phase p, in [-pi, pi).
+func PrincArg(p float64) float64 {
+	return p - 2*math.Pi*math.Floor((p+math.Pi)/(2*math.Pi))
+}
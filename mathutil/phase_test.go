@@ -0,0 +1,26 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package mathutil
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPrincArg(t *testing.T) {
+	for _, c := range []struct{ p, exp float64 }{
+		{0, 0},
+		{1, 1},
+		{-1, -1},
+		{math.Pi, -math.Pi},
+		{-math.Pi, -math.Pi},
+		{3 * math.Pi / 2, -math.Pi / 2},
+		{-3 * math.Pi / 2, math.Pi / 2},
+		{20*math.Pi + 0.5, 0.5},
+	} {
+		if r := PrincArg(c.p); math.Abs(r-c.exp) > 1e-12 {
+			t.Errorf("PrincArg(%f): got %f not %f", c.p, r, c.exp)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+// Package pv provides a phase vocoder for time stretching and pitch
+// shifting.
+//
+// Package pv is part of http://zikichombo.org
+//
+// The phase vocoder analyzes a source with a short time fourier transform
+// whose frames start every hop/ratio samples on average, estimates the
+// instantaneous frequency of each bin from the phase difference between
+// frames, and resynthesizes frames every hop samples with phases advanced
+// accordingly.  The result lasts ratio times as long as the source with the
+// same pitch.  Pitch shifting is time stretching followed by resampling.
+//
+// By default, the phases of bins near a spectral peak are locked to the
+// phase of the peak, as described by Laroche and Dolson, which reduces the
+// reverberant "phasiness" of the plain phase vocoder.
+package pv
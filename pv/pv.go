@@ -0,0 +1,249 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package pv
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/zikichombo/dsp/fft"
+	"github.com/zikichombo/dsp/mathutil"
+	"github.com/zikichombo/dsp/resample"
+	"github.com/zikichombo/dsp/stft"
+	"github.com/zikichombo/dsp/wfn"
+	"github.com/zikichombo/sound"
+	"github.com/zikichombo/sound/freq"
+)
+
+// T is a phase vocoder giving the frames of a source stretched in time by
+// a ratio.  T implements stft.Framer, so stft.NewSynth(t, nil) gives the
+// stretched sound.
+type T struct {
+	a     *stft.T
+	ratio float64
+	size  int
+	hop   int // synthesis hop
+	n, m  int // transform size, number of non-negative bins
+	lock  bool
+	k     int // number of frames given
+	a0    int // offset of first analysis frame
+	o0    int // offset of first synthesis frame
+	aOff  int // offset of last analysis frame
+	prev  [][]float64
+	syn   [][]float64
+	cur   []float64
+	peaks []int
+	hcs   []fft.HalfComplex
+	frame stft.Frame
+}
+
+// New creates a new phase vocoder for src, with frames of size samples
+// resynthesized every hop samples and stretched by ratio.  A hop of at
+// most size/4 is recommended.
+//
+// New returns a non-nil error if ratio is not positive, if hop is not less
+// than size or if size is not valid for stft.New.
+func New(src sound.Source, size, hop int, ratio float64) (*T, error) {
+	if !(ratio > 0) || math.IsInf(ratio, 1) {
+		return nil, fmt.Errorf("invalid ratio %f", ratio)
+	}
+	if hop >= size {
+		return nil, fmt.Errorf("hop %d not less than size %d", hop, size)
+	}
+	aHop := int(math.Floor(float64(hop)/ratio + 0.5))
+	if aHop < 1 {
+		aHop = 1
+	}
+	a, e := stft.New(src, size, aHop, wfn.NewPeriodic(wfn.Hann, size), 1)
+	if e != nil {
+		return nil, e
+	}
+	a.SetPrime(true)
+	nC, n := a.Channels(), a.N()
+	m := n/2 + 1
+	res := &T{
+		a:     a,
+		ratio: ratio,
+		size:  size,
+		hop:   hop,
+		n:     n,
+		m:     m,
+		lock:  true,
+		prev:  make([][]float64, nC),
+		syn:   make([][]float64, nC),
+		cur:   make([]float64, m),
+		peaks: make([]int, 0, m),
+		hcs:   make([]fft.HalfComplex, nC)}
+	for c := 0; c < nC; c++ {
+		res.prev[c] = make([]float64, m)
+		res.syn[c] = make([]float64, m)
+		res.hcs[c] = fft.HalfComplex(make([]float64, n))
+	}
+	res.frame.HC = res.hcs
+	return res, nil
+}
+
+// SetLock sets whether or not the phases of bins are locked to the phases
+// of nearby peaks.  The default is true.
+func (t *T) SetLock(v bool) {
+	t.lock = v
+}
+
+// Ratio returns the time stretch ratio of t.
+func (t *T) Ratio() float64 {
+	return t.ratio
+}
+
+// Channels returns the number of channels.
+func (t *T) Channels() int {
+	return t.a.Channels()
+}
+
+// SampleRate returns the sample rate.
+func (t *T) SampleRate() freq.T {
+	return t.a.SampleRate()
+}
+
+// Size returns the frame size.
+func (t *T) Size() int {
+	return t.size
+}
+
+// Hop returns the synthesis hop.
+func (t *T) Hop() int {
+	return t.hop
+}
+
+// N returns the transform size.
+func (t *T) N() int {
+	return t.n
+}
+
+// Win returns the analysis window.
+func (t *T) Win() wfn.T {
+	return t.a.Win()
+}
+
+// Close closes the source of t.
+func (t *T) Close() error {
+	return t.a.Close()
+}
+
+// Next returns the next stretched frame, as in stft.Framer.
+func (t *T) Next() (*stft.Frame, error) {
+	af, e := t.a.Next()
+	if e != nil {
+		return nil, e
+	}
+	if t.k == 0 {
+		// align the centers of analysis and synthesis frames.
+		h := float64(t.size) / 2
+		t.a0 = af.Offset
+		t.o0 = int(math.Floor((float64(t.a0)+h)*t.ratio - h + 0.5))
+	}
+	ha := float64(af.Offset - t.aOff)
+	hs := float64(t.hop)
+	for c := range t.syn {
+		s := af.S(c)
+		prev, syn, cur := t.prev[c], t.syn[c], t.cur
+		for i := range cur {
+			cur[i] = s.Phase(i)
+			if t.k == 0 {
+				syn[i] = cur[i]
+				continue
+			}
+			w := 2 * math.Pi * float64(i) / float64(t.n)
+			d := mathutil.PrincArg(cur[i] - prev[i] - w*ha)
+			syn[i] = mathutil.PrincArg(syn[i] + (w+d/ha)*hs)
+		}
+		if t.lock && t.k > 0 {
+			t.lockPhases(s, syn, cur)
+		}
+		// dc and nyquist remain real.
+		syn[0] = cur[0]
+		if t.n%2 == 0 {
+			syn[t.m-1] = cur[t.m-1]
+		}
+		copy(prev, cur)
+		for i, p := range syn {
+			s.SetPhase(i, p)
+		}
+		s.ToHalfComplex(t.hcs[c])
+	}
+	f := &t.frame
+	f.Index = t.k
+	f.Offset = t.o0 + t.k*t.hop
+	f.Time = 0
+	if sr := t.SampleRate(); sr > 0 {
+		f.Time = time.Duration(float64(f.Offset) / sr.Float64() * float64(time.Second))
+	}
+	end := float64(af.Offset+af.N) * t.ratio
+	f.N = int(math.Floor(end+0.5)) - f.Offset
+	if f.N < 0 {
+		f.N = 0
+	}
+	if f.N > t.size {
+		f.N = t.size
+	}
+	t.k++
+	t.aOff = af.Offset
+	next := t.a0 + int(math.Floor(float64(t.k*t.hop)/t.ratio+0.5))
+	t.a.SetHop(next - af.Offset)
+	return f, nil
+}
+
+// lockPhases sets the synthesis phases of bins in the region of each peak
+// in s to the analysis phase of the bin rotated as the peak is.
+func (t *T) lockPhases(s *fft.S, syn, cur []float64) {
+	t.peaks = s.PeaksTo(t.peaks)
+	lo := 0
+	for j, p := range t.peaks {
+		hi := t.m
+		if j+1 < len(t.peaks) {
+			hi = (p + t.peaks[j+1] + 1) / 2
+		}
+		rot := syn[p] - cur[p]
+		for i := lo; i < hi; i++ {
+			if i != p {
+				syn[i] = mathutil.PrincArg(cur[i] + rot)
+			}
+		}
+		lo = hi
+	}
+}
+
+// Stretch returns a sound.Source giving src stretched in time by ratio with
+// the same pitch, using a phase vocoder with frames of size samples and a
+// synthesis hop of hop samples.
+func Stretch(src sound.Source, ratio float64, size, hop int) (sound.Source, error) {
+	t, e := New(src, size, hop, ratio)
+	if e != nil {
+		return nil, e
+	}
+	return stft.NewSynth(t, nil)
+}
+
+// Shift returns a sound.Source giving src with all frequencies multiplied
+// by ratio and the same duration, by stretching src in time by ratio as in
+// Stretch and resampling the result by 1/ratio with itp.  If itp is nil,
+// the default of resample.Resample is used.
+func Shift(src sound.Source, ratio float64, size, hop int, itp resample.Itper) (sound.Source, error) {
+	st, e := Stretch(src, ratio, size, hop)
+	if e != nil {
+		return nil, e
+	}
+	sr := src.SampleRate()
+	return resample.Resample(&asIf{Source: st, sr: freq.T(float64(sr) * ratio)}, sr, itp), nil
+}
+
+// asIf is a source with a different sample rate.
+type asIf struct {
+	sound.Source
+	sr freq.T
+}
+
+func (a *asIf) SampleRate() freq.T {
+	return a.sr
+}
@@ -0,0 +1,142 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package pv
+
+import (
+	"io"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/zikichombo/dsp/stft"
+	"github.com/zikichombo/sound"
+	"github.com/zikichombo/sound/freq"
+	"github.com/zikichombo/sound/sndbuf"
+)
+
+const sr = 8000 * freq.Hertz
+
+func sine(f float64, n int) []float64 {
+	d := make([]float64, n)
+	for i := range d {
+		d[i] = math.Sin(2 * math.Pi * f * float64(i) / sr.Float64())
+	}
+	return d
+}
+
+func slurp(src sound.Source, t *testing.T) []float64 {
+	var res []float64
+	buf := make([]float64, 100)
+	for {
+		n, e := src.Receive(buf)
+		if e == io.EOF {
+			return res
+		}
+		if e != nil {
+			t.Fatal(e)
+		}
+		res = append(res, buf[:n]...)
+	}
+}
+
+// crossFreq estimates the frequency of a sinusoid in d from
+// its rising zero crossings.
+func crossFreq(d []float64) float64 {
+	first, last, n := -1, -1, 0
+	for i := 1; i < len(d); i++ {
+		if d[i-1] < 0 && d[i] >= 0 {
+			if first == -1 {
+				first = i
+			} else {
+				n++
+			}
+			last = i
+		}
+	}
+	return float64(n) * sr.Float64() / float64(last-first)
+}
+
+func TestIdentity(t *testing.T) {
+	d := make([]float64, 1000)
+	for i := range d {
+		d[i] = rand.Float64()*2 - 1
+	}
+	for _, lock := range []bool{false, true} {
+		p, e := New(sndbuf.FromSlice(d, sr), 256, 64, 1)
+		if e != nil {
+			t.Fatal(e)
+		}
+		p.SetLock(lock)
+		s, e := stft.NewSynth(p, nil)
+		if e != nil {
+			t.Fatal(e)
+		}
+		res := slurp(s, t)
+		if len(res) != len(d) {
+			t.Fatalf("lock=%t got %d samples not %d", lock, len(res), len(d))
+		}
+		for i, v := range res {
+			if math.Abs(v-d[i]) > 1e-9 {
+				t.Errorf("lock=%t sample %d: got %f not %f", lock, i, v, d[i])
+			}
+		}
+	}
+}
+
+func TestStretch(t *testing.T) {
+	n := 8000
+	for _, r := range []float64{0.6, 1.37, 2} {
+		for _, lock := range []bool{false, true} {
+			p, e := New(sndbuf.FromSlice(sine(440, n), sr), 1024, 256, r)
+			if e != nil {
+				t.Fatal(e)
+			}
+			p.SetLock(lock)
+			s, e := stft.NewSynth(p, nil)
+			if e != nil {
+				t.Fatal(e)
+			}
+			res := slurp(s, t)
+			exp := int(float64(n) * r)
+			if len(res) < exp-512 || len(res) > exp+512 {
+				t.Errorf("ratio %f: got %d samples not about %d", r, len(res), exp)
+			}
+			f := crossFreq(res[1024 : len(res)-1024])
+			if math.Abs(f-440) > 2 {
+				t.Errorf("ratio %f lock %t: got frequency %f not 440", r, lock, f)
+			}
+		}
+	}
+}
+
+func TestShift(t *testing.T) {
+	n := 8000
+	for _, r := range []float64{0.8, 1.25} {
+		s, e := Shift(sndbuf.FromSlice(sine(440, n), sr), r, 1024, 256, nil)
+		if e != nil {
+			t.Fatal(e)
+		}
+		if s.SampleRate() != sr {
+			t.Errorf("got sample rate %s", s.SampleRate())
+		}
+		res := slurp(s, t)
+		if len(res) < n-512 || len(res) > n+512 {
+			t.Errorf("ratio %f: got %d samples not about %d", r, len(res), n)
+		}
+		f := crossFreq(res[1024 : len(res)-1024])
+		if math.Abs(f-440*r) > 3 {
+			t.Errorf("ratio %f: got frequency %f not %f", r, f, 440*r)
+		}
+	}
+}
+
+func TestNewErrs(t *testing.T) {
+	src := sndbuf.FromSlice(make([]float64, 100), sr)
+	if _, e := New(src, 64, 16, 0); e == nil {
+		t.Errorf("expected ratio error")
+	}
+	if _, e := New(src, 64, 64, 1); e == nil {
+		t.Errorf("expected hop error")
+	}
+}
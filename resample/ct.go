@@ -90,6 +90,9 @@ func (r *DynResampler) Receive(d []float64) (int, error) {
 	for f := 0; f < nF; f++ {
 		if err := r.ct.FrameAt(r.buf, r.lasti); err != nil {
 			if err == io.EOF {
+				if f == 0 {
+					return 0, io.EOF
+				}
 				cil.Compact(d, nC, f)
 				return f, nil
 			}
@@ -250,10 +253,14 @@ func (c *C) FrameAt(dst []float64, i float64) error {
 	for ci := range dst {
 		buf := c.cbufs[ci]
 		cj := j - c.off
-		if jr <= c.eps || (1-jr) <= c.eps {
+		if jr <= c.eps {
 			dst[ci] = buf[cj]
 			continue
 		}
+		if 1-jr <= c.eps {
+			dst[ci] = buf[cj+1]
+			continue
+		}
 		if cj+order >= len(buf) {
 			order = len(buf) - 1 - cj
 		}
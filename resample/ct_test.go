@@ -1,12 +1,14 @@
 package resample
 
 import (
+	"io"
 	"math"
 	"testing"
 
 	"github.com/zikichombo/sound/freq"
 	"github.com/zikichombo/sound/gen"
 	"github.com/zikichombo/sound/ops"
+	"github.com/zikichombo/sound/sndbuf"
 )
 
 func TestCDefaultMonoChan(t *testing.T) {
@@ -152,3 +154,48 @@ func TestResampleConst(t *testing.T) {
 		t.Errorf("resample error too large %f\n", err)
 	}
 }
+
+func TestResampleEOF(t *testing.T) {
+	src := sndbuf.FromSlice(make([]float64, 1000), 8000*freq.Hertz)
+	rez := Resample(src, 6000*freq.Hertz, nil)
+	d := make([]float64, 100)
+	ttl := 0
+	for i := 0; i < 100; i++ {
+		n, e := rez.Receive(d)
+		if e == io.EOF {
+			if n != 0 {
+				t.Errorf("got %d frames with io.EOF", n)
+			}
+			if ttl < 700 || ttl > 750 {
+				t.Errorf("got %d frames not about 750", ttl)
+			}
+			return
+		}
+		if e != nil {
+			t.Fatal(e)
+		}
+		ttl += n
+	}
+	t.Errorf("no io.EOF after %d frames", ttl)
+}
+
+func TestCNearInt(t *testing.T) {
+	d := make([]float64, 200)
+	for i := range d {
+		d[i] = float64(i)
+	}
+	c := NewC(sndbuf.FromSlice(d, 8000*freq.Hertz), LinItp())
+	for i := 0; i < 150; i++ {
+		x := float64(i) - 1e-12
+		if i == 0 {
+			x = 0
+		}
+		v, e := c.At(x)
+		if e != nil {
+			t.Fatal(e)
+		}
+		if math.Abs(v-float64(i)) > 1e-6 {
+			t.Errorf("at %.12f got %f not %d", x, v, i)
+		}
+	}
+}
@@ -0,0 +1,16 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+// Package spectrogram provides time frequency images of sound.
+//
+// Package spectrogram is part of http://zikichombo.org
+//
+// A spectrogram is computed from the short time fourier transform of a
+// source, as given by package stft, in decibels of power.  The frequency axis
+// may be linear, with one row per frequency bin, or logarithmic or mel
+// spaced, with rows formed by triangular weighting of the bins.  Values
+// below a configurable floor relative to the maximum are clipped.
+//
+// Spectrograms may be rendered to an *image.RGBA or PNG with a colormap
+// and tick marks at round frequencies and times.
+package spectrogram
@@ -0,0 +1,252 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package spectrogram
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"os"
+	"time"
+
+	"github.com/zikichombo/sound/freq"
+)
+
+// Colormap maps values in [0, 1] to colors.
+type Colormap func(v float64) color.RGBA
+
+// Gray maps 0 to black and 1 to white.
+func Gray(v float64) color.RGBA {
+	g := uint8(clamp(v)*255 + 0.5)
+	return color.RGBA{R: g, G: g, B: g, A: 255}
+}
+
+// Hot maps 0 to black through red and yellow to white.
+func Hot(v float64) color.RGBA {
+	return ramp(hotStops, v)
+}
+
+// Viridis maps values along an approximation of the perceptually uniform
+// viridis colormap, from dark blue through green to yellow.
+func Viridis(v float64) color.RGBA {
+	return ramp(viridisStops, v)
+}
+
+var hotStops = []color.RGBA{
+	{0, 0, 0, 255},
+	{230, 0, 0, 255},
+	{255, 210, 0, 255},
+	{255, 255, 255, 255}}
+
+var viridisStops = []color.RGBA{
+	{68, 1, 84, 255},
+	{72, 40, 120, 255},
+	{62, 74, 137, 255},
+	{49, 104, 142, 255},
+	{38, 130, 142, 255},
+	{31, 158, 137, 255},
+	{53, 183, 121, 255},
+	{109, 205, 89, 255},
+	{180, 222, 44, 255},
+	{253, 231, 37, 255}}
+
+// ramp interpolates linearly between equally spaced colors.
+func ramp(stops []color.RGBA, v float64) color.RGBA {
+	x := clamp(v) * float64(len(stops)-1)
+	i := int(x)
+	if i >= len(stops)-1 {
+		return stops[len(stops)-1]
+	}
+	r := x - float64(i)
+	a, b := stops[i], stops[i+1]
+	lerp := func(p, q uint8) uint8 {
+		return uint8(float64(p)*(1-r) + float64(q)*r + 0.5)
+	}
+	return color.RGBA{R: lerp(a.R, b.R), G: lerp(a.G, b.G), B: lerp(a.B, b.B), A: 255}
+}
+
+func clamp(v float64) float64 {
+	if !(v > 0) {
+		return 0
+	}
+	if v > 1 {
+		return 1
+	}
+	return v
+}
+
+// tickLen is the length in pixels of axis ticks.
+const tickLen = 5
+
+// FreqTick gives the position of a frequency on the vertical axis of an
+// image, as a y coordinate.
+type FreqTick struct {
+	F freq.T
+	Y int
+}
+
+// TimeTick gives the position of a time on the horizontal axis of an
+// image, as an x coordinate.
+type TimeTick struct {
+	T time.Duration
+	X int
+}
+
+// plotRect returns the rectangle in b in which Image draws the values of
+// the spectrogram, leaving room for a border and ticks.
+func plotRect(b image.Rectangle) image.Rectangle {
+	return image.Rect(b.Min.X+tickLen+1, b.Min.Y+1, b.Max.X-1, b.Max.Y-tickLen-1)
+}
+
+// Image renders s in an image of bounds b using the colormap cm, or Viridis
+// if cm is nil, with ticks at the positions given by FreqTicks and
+// TimeTicks along the left and bottom edges.
+func (s *T) Image(b image.Rectangle, cm Colormap) *image.RGBA {
+	if cm == nil {
+		cm = Viridis
+	}
+	im := image.NewRGBA(b)
+	white := color.RGBA{255, 255, 255, 255}
+	black := color.RGBA{A: 255}
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			im.SetRGBA(x, y, white)
+		}
+	}
+	pr := plotRect(b)
+	if pr.Dx() <= 0 || pr.Dy() <= 0 {
+		return im
+	}
+	for x := pr.Min.X - 1; x <= pr.Max.X; x++ {
+		im.SetRGBA(x, pr.Min.Y-1, black)
+		im.SetRGBA(x, pr.Max.Y, black)
+	}
+	for y := pr.Min.Y - 1; y <= pr.Max.Y; y++ {
+		im.SetRGBA(pr.Min.X-1, y, black)
+		im.SetRGBA(pr.Max.X, y, black)
+	}
+	nc, nr := s.Cols(), s.Rows()
+	if nc == 0 || nr == 0 {
+		return im
+	}
+	lim := s.Max + s.Floor
+	for x := pr.Min.X; x < pr.Max.X; x++ {
+		col := s.Db[(x-pr.Min.X)*nc/pr.Dx()]
+		for y := pr.Min.Y; y < pr.Max.Y; y++ {
+			v := col[(pr.Max.Y-1-y)*nr/pr.Dy()]
+			im.SetRGBA(x, y, cm((v-lim)/-s.Floor))
+		}
+	}
+	for _, t := range s.FreqTicks(b) {
+		for x := pr.Min.X - 1 - tickLen; x < pr.Min.X-1; x++ {
+			im.SetRGBA(x, t.Y, black)
+		}
+	}
+	for _, t := range s.TimeTicks(b) {
+		for y := pr.Max.Y + 1; y <= pr.Max.Y+tickLen; y++ {
+			im.SetRGBA(t.X, y, black)
+		}
+	}
+	return im
+}
+
+// WritePNG writes s rendered as in Image to w in PNG format.
+func (s *T) WritePNG(w io.Writer, b image.Rectangle, cm Colormap) error {
+	return png.Encode(w, s.Image(b, cm))
+}
+
+// PlotTo writes s rendered as in Image to a PNG file at path p.
+func (s *T) PlotTo(b image.Rectangle, cm Colormap, p string) error {
+	f, e := os.Create(p)
+	if e != nil {
+		return e
+	}
+	if e := s.WritePNG(f, b, cm); e != nil {
+		f.Close()
+		return e
+	}
+	return f.Close()
+}
+
+// FreqTicks returns ticks at round frequencies within the range of s for
+// an image of bounds b, as drawn by Image.  For Log axes the ticks are at
+// 1, 2 and 5 times powers of 10, otherwise they are equally spaced.
+func (s *T) FreqTicks(b image.Rectangle) []FreqTick {
+	nr := len(s.Freqs)
+	pr := plotRect(b)
+	if nr < 2 || pr.Dy() <= 0 {
+		return nil
+	}
+	to, _ := axisFuncs(s.Axis)
+	f0, f1 := s.Freqs[0].Float64(), s.Freqs[nr-1].Float64()
+	a0, a1 := to(f0), to(f1)
+	var fs []float64
+	if s.Axis == Log {
+		for p := math.Pow(10, math.Floor(math.Log10(f0))); p <= f1; p *= 10 {
+			for _, m := range []float64{1, 2, 5} {
+				if f := m * p; f >= f0 && f <= f1 {
+					fs = append(fs, f)
+				}
+			}
+		}
+	} else {
+		step := niceStep(f1-f0, 8)
+		for f := math.Ceil(f0/step) * step; f <= f1; f += step {
+			fs = append(fs, f)
+		}
+	}
+	// rows are equally spaced on the axis, row j is drawn over
+	// [j, j+1) * Dy / nr from the bottom.
+	var res []FreqTick
+	for _, f := range fs {
+		j := (to(f) - a0) / (a1 - a0) * float64(nr-1)
+		y := pr.Max.Y - 1 - int(math.Floor((j+0.5)*float64(pr.Dy())/float64(nr)))
+		res = append(res, FreqTick{F: freq.T(f * float64(freq.Hertz)), Y: y})
+	}
+	return res
+}
+
+// TimeTicks returns ticks at equally spaced round times within the range of
+// s for an image of bounds b, as drawn by Image.
+func (s *T) TimeTicks(b image.Rectangle) []TimeTick {
+	nc := len(s.Times)
+	pr := plotRect(b)
+	if nc < 2 || pr.Dx() <= 0 {
+		return nil
+	}
+	t0, t1 := s.Times[0], s.Times[nc-1]
+	dt := float64(t1-t0) / float64(nc-1)
+	step := time.Duration(niceStep(float64(t1-t0), 8))
+	if step <= 0 {
+		return nil
+	}
+	var res []TimeTick
+	for t := t0 - t0%step; t <= t1; t += step {
+		if t < t0 {
+			continue
+		}
+		j := float64(t-t0) / dt
+		x := pr.Min.X + int(math.Floor((j+0.5)*float64(pr.Dx())/float64(nc)))
+		res = append(res, TimeTick{T: t, X: x})
+	}
+	return res
+}
+
+// niceStep returns a step of 1, 2 or 5 times a power of 10 giving at most
+// about n steps over a range of r.
+func niceStep(r float64, n int) float64 {
+	if !(r > 0) {
+		return 0
+	}
+	raw := r / float64(n)
+	p := math.Pow(10, math.Floor(math.Log10(raw)))
+	for _, m := range []float64{1, 2, 5, 10} {
+		if m*p >= raw {
+			return m * p
+		}
+	}
+	return 10 * p
+}
@@ -0,0 +1,286 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package spectrogram
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/zikichombo/dsp/fft"
+	"github.com/zikichombo/dsp/internal/mel"
+	"github.com/zikichombo/dsp/stft"
+	"github.com/zikichombo/dsp/wfn"
+	"github.com/zikichombo/sound"
+	"github.com/zikichombo/sound/freq"
+	"github.com/zikichombo/sound/sndbuf"
+)
+
+// Axis gives the spacing of the frequency axis of a spectrogram.
+type Axis int
+
+const (
+	// Linear gives one row per frequency bin.
+	Linear Axis = iota
+	// Log gives rows with logarithmically spaced frequencies.
+	Log
+	// Mel gives rows with frequencies equally spaced in mels.
+	Mel
+)
+
+// String implements Stringer.
+func (a Axis) String() string {
+	switch a {
+	case Linear:
+		return "linear"
+	case Log:
+		return "log"
+	case Mel:
+		return "mel"
+	}
+	return fmt.Sprintf("Axis(%d)", int(a))
+}
+
+// Opts gives options for computing a spectrogram.  Zero values give
+// defaults.
+type Opts struct {
+	Size int   // frame size, default 1024
+	Hop  int   // hop between frames, default Size/4
+	Pad  int   // zero padding factor, default 1
+	Win  wfn.T // window, default periodic Hann
+	Axis Axis  // frequency axis, default Linear
+	// Rows gives the number of rows for Log and Mel axes, default 128.
+	Rows int
+	// Min and Max give the range of frequencies.  Max defaults to the
+	// Nyquist frequency and Min to 0, or to the first non-zero bin for
+	// Log axes.
+	Min, Max freq.T
+	// Floor gives the lowest value in dB relative to the maximum, default
+	// -80.
+	Floor float64
+}
+
+// T is a spectrogram.
+type T struct {
+	Axis Axis
+	// Freqs gives the center frequency of each row, ascending.
+	Freqs []freq.T
+	// Times gives the time of the start of each column.
+	Times []time.Duration
+	// Db gives the power in decibels of each row in each column, indexed
+	// by column then row.  Values are no less than Max+Floor.
+	Db [][]float64
+	// Max is the maximum value in Db.
+	Max float64
+	// Floor is the floor relative to Max.
+	Floor float64
+}
+
+// row gives the weights of the frequency bins in a row.
+type row struct {
+	lo int
+	w  []float64
+}
+
+// New computes the spectrogram of src with options o, which may be nil.
+// The power of all channels of src is summed.
+//
+// New returns a non-nil error if the options are invalid or if src returns
+// an error other than io.EOF.
+func New(src sound.Source, o *Opts) (*T, error) {
+	var opts Opts
+	if o != nil {
+		opts = *o
+	}
+	if opts.Size == 0 {
+		opts.Size = 1024
+	}
+	if opts.Hop == 0 {
+		opts.Hop = opts.Size / 4
+		if opts.Hop == 0 {
+			opts.Hop = 1
+		}
+	}
+	if opts.Pad == 0 {
+		opts.Pad = 1
+	}
+	if opts.Rows == 0 {
+		opts.Rows = 128
+	}
+	if opts.Floor == 0 {
+		opts.Floor = -80
+	}
+	if opts.Floor > 0 {
+		return nil, fmt.Errorf("positive floor %f", opts.Floor)
+	}
+	a, e := stft.New(src, opts.Size, opts.Hop, opts.Win, opts.Pad)
+	if e != nil {
+		return nil, e
+	}
+	sr := src.SampleRate()
+	res := &T{Axis: opts.Axis, Floor: opts.Floor, Max: math.Inf(-1)}
+	rows, e := res.rows(sr, a.N(), &opts)
+	if e != nil {
+		return nil, e
+	}
+	pow := make([]float64, a.N()/2+1)
+	for {
+		f, e := a.Next()
+		if e == io.EOF {
+			break
+		}
+		if e != nil {
+			return nil, e
+		}
+		pow = f.Power(pow)
+		col := make([]float64, len(rows))
+		for j, r := range rows {
+			p := 0.0
+			for k, w := range r.w {
+				p += pow[r.lo+k] * w
+			}
+			v := 10 * math.Log10(p)
+			col[j] = v
+			if v > res.Max {
+				res.Max = v
+			}
+		}
+		res.Db = append(res.Db, col)
+		res.Times = append(res.Times, f.Time)
+	}
+	lim := res.Max + res.Floor
+	for _, col := range res.Db {
+		for j, v := range col {
+			if !(v >= lim) {
+				col[j] = lim
+			}
+		}
+	}
+	return res, nil
+}
+
+// FromSlice computes the spectrogram of the single channel samples d at
+// sample rate sr, as in New.
+func FromSlice(d []float64, sr freq.T, o *Opts) (*T, error) {
+	return New(sndbuf.FromSlice(d, sr), o)
+}
+
+// Rows returns the number of rows (frequencies) of s.
+func (s *T) Rows() int {
+	return len(s.Freqs)
+}
+
+// Cols returns the number of columns (frames) of s.
+func (s *T) Cols() int {
+	return len(s.Db)
+}
+
+// rows sets s.Freqs and returns the weights of each row for transforms of
+// size n.
+func (s *T) rows(sr freq.T, n int, o *Opts) ([]row, error) {
+	m := n/2 + 1
+	bin := sr.Float64() / float64(n)
+	min, max := o.Min.Float64(), o.Max.Float64()
+	if max == 0 || max > sr.Float64()/2 {
+		max = sr.Float64() / 2
+	}
+	if min == 0 && o.Axis == Log {
+		min = bin
+	}
+	if min < 0 || min >= max {
+		return nil, fmt.Errorf("invalid frequency range %s..%s", freq.T(min*float64(freq.Hertz)), freq.T(max*float64(freq.Hertz)))
+	}
+	if o.Axis == Linear {
+		var rows []row
+		for k := 0; k < m; k++ {
+			f := float64(k) * bin
+			if f < min || f > max {
+				continue
+			}
+			rows = append(rows, row{lo: k, w: []float64{1}})
+			s.Freqs = append(s.Freqs, fft.FreqAt(sr, n, float64(k)))
+		}
+		return rows, nil
+	}
+	to, from := axisFuncs(o.Axis)
+	if to == nil {
+		return nil, fmt.Errorf("unknown axis %s", o.Axis)
+	}
+	nr := o.Rows
+	if nr < 1 {
+		return nil, fmt.Errorf("invalid number of rows %d", nr)
+	}
+	lo, hi := to(min), to(max)
+	step := 0.0
+	if nr > 1 {
+		step = (hi - lo) / float64(nr-1)
+	}
+	rows := make([]row, nr)
+	s.Freqs = make([]freq.T, nr)
+	for j := range rows {
+		c := lo + float64(j)*step
+		fc := from(c)
+		s.Freqs[j] = freq.T(fc * float64(freq.Hertz))
+		fl, fh := from(c-step), from(c+step)
+		// triangle over [fl, fh] peaking at fc.
+		kl := int(math.Ceil(fl / bin))
+		kh := int(math.Floor(fh / bin))
+		if kl < 0 {
+			kl = 0
+		}
+		if kh >= m {
+			kh = m - 1
+		}
+		var w []float64
+		ttl := 0.0
+		for k := kl; k <= kh; k++ {
+			f := float64(k) * bin
+			v := 0.0
+			switch {
+			case f < fc && fc > fl:
+				v = (f - fl) / (fc - fl)
+			case f > fc && fh > fc:
+				v = (fh - f) / (fh - fc)
+			case f == fc:
+				v = 1
+			}
+			w = append(w, v)
+			ttl += v
+		}
+		switch {
+		case ttl == 0 && m < 2:
+			// a single bin.
+			kl, w, ttl = 0, []float64{1}, 1
+		case ttl == 0:
+			// narrower than a bin, interpolate linearly.
+			x := fc / bin
+			kl = int(math.Floor(x))
+			if kl >= m-1 {
+				kl = m - 2
+			}
+			r := x - float64(kl)
+			w, ttl = []float64{1 - r, r}, 1
+		}
+		for i := range w {
+			w[i] /= ttl
+		}
+		rows[j] = row{lo: kl, w: w}
+	}
+	return rows, nil
+}
+
+// axisFuncs returns the functions mapping Hertz to the axis a and back.
+func axisFuncs(a Axis) (to, from func(float64) float64) {
+	switch a {
+	case Linear:
+		id := func(f float64) float64 { return f }
+		return id, id
+	case Log:
+		return math.Log, math.Exp
+	case Mel:
+		return mel.FromHz, mel.ToHz
+	}
+	return nil, nil
+}
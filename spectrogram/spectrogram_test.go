@@ -0,0 +1,147 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package spectrogram
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"math"
+	"testing"
+
+	"github.com/zikichombo/sound/freq"
+)
+
+const sr = 16000 * freq.Hertz
+
+func sine(f float64, n int) []float64 {
+	d := make([]float64, n)
+	for i := range d {
+		d[i] = math.Sin(2 * math.Pi * f * float64(i) / sr.Float64())
+	}
+	return d
+}
+
+func TestPeakRow(t *testing.T) {
+	d := sine(1000, 16000)
+	for _, a := range []Axis{Linear, Log, Mel} {
+		s, e := FromSlice(d, sr, &Opts{Size: 512, Axis: a, Rows: 64, Min: 50 * freq.Hertz})
+		if e != nil {
+			t.Fatal(e)
+		}
+		if s.Cols() != 16000/128 {
+			t.Errorf("%s: got %d columns not %d", a, s.Cols(), 16000/128)
+		}
+		for i := 1; i < s.Rows(); i++ {
+			if s.Freqs[i] <= s.Freqs[i-1] {
+				t.Fatalf("%s: frequencies not ascending at %d", a, i)
+			}
+		}
+		col := s.Db[s.Cols()/2]
+		mx := 0
+		for j, v := range col {
+			if v > col[mx] {
+				mx = j
+			}
+			if v < s.Max+s.Floor {
+				t.Errorf("%s: row %d below floor", a, j)
+			}
+		}
+		f := s.Freqs[mx].Float64()
+		if math.Abs(f-1000) > 1000*0.06 {
+			t.Errorf("%s: got peak at %f not 1000", a, f)
+		}
+	}
+}
+
+func TestSmall(t *testing.T) {
+	for _, sz := range []int{1, 2, 3} {
+		s, e := FromSlice(sine(1000, 100), sr, &Opts{Size: sz, Axis: Mel, Rows: 8})
+		if e != nil {
+			t.Fatal(e)
+		}
+		if s.Rows() != 8 || s.Cols() != 100 {
+			t.Errorf("size %d: got %d rows %d columns", sz, s.Rows(), s.Cols())
+		}
+	}
+}
+
+func TestRange(t *testing.T) {
+	d := sine(1000, 4096)
+	s, e := FromSlice(d, sr, &Opts{Size: 256, Min: 1000 * freq.Hertz, Max: 4000 * freq.Hertz, Floor: -40})
+	if e != nil {
+		t.Fatal(e)
+	}
+	if s.Freqs[0] != 1000*freq.Hertz || s.Freqs[s.Rows()-1] != 4000*freq.Hertz {
+		t.Errorf("got range %s..%s", s.Freqs[0], s.Freqs[s.Rows()-1])
+	}
+	for _, col := range s.Db {
+		for _, v := range col {
+			if v < s.Max-40 {
+				t.Fatalf("got %f below floor %f", v, s.Max-40)
+			}
+		}
+	}
+	if _, e := FromSlice(d, sr, &Opts{Min: 4000 * freq.Hertz, Max: 1000 * freq.Hertz}); e == nil {
+		t.Errorf("expected range error")
+	}
+	if _, e := FromSlice(d, sr, &Opts{Floor: 3}); e == nil {
+		t.Errorf("expected floor error")
+	}
+}
+
+func TestImage(t *testing.T) {
+	s, e := FromSlice(sine(440, 16000), sr, &Opts{Size: 1024, Axis: Log, Min: 20 * freq.Hertz})
+	if e != nil {
+		t.Fatal(e)
+	}
+	b := image.Rect(0, 0, 300, 200)
+	var buf bytes.Buffer
+	for _, cm := range []Colormap{nil, Gray, Hot} {
+		buf.Reset()
+		if e := s.WritePNG(&buf, b, cm); e != nil {
+			t.Fatal(e)
+		}
+		im, e := png.Decode(&buf)
+		if e != nil {
+			t.Fatal(e)
+		}
+		if im.Bounds() != b {
+			t.Errorf("got bounds %s not %s", im.Bounds(), b)
+		}
+	}
+	fts := s.FreqTicks(b)
+	if len(fts) == 0 {
+		t.Fatalf("no frequency ticks")
+	}
+	for i, ft := range fts {
+		if ft.Y < b.Min.Y || ft.Y >= b.Max.Y {
+			t.Errorf("tick %s at %d out of bounds", ft.F, ft.Y)
+		}
+		if i > 0 && (ft.F <= fts[i-1].F || ft.Y >= fts[i-1].Y) {
+			t.Errorf("ticks %s %s not ascending", fts[i-1].F, ft.F)
+		}
+	}
+	tts := s.TimeTicks(b)
+	if len(tts) < 2 {
+		t.Fatalf("got %d time ticks", len(tts))
+	}
+	for i := 1; i < len(tts); i++ {
+		if tts[i].X <= tts[i-1].X {
+			t.Errorf("time ticks not ascending")
+		}
+	}
+}
+
+func TestColormaps(t *testing.T) {
+	if c := Gray(0); c.R != 0 || c.A != 255 {
+		t.Errorf("got %v", c)
+	}
+	if c := Hot(1); c.R != 255 || c.G != 255 || c.B != 255 {
+		t.Errorf("got %v", c)
+	}
+	if c := Viridis(2); c != Viridis(1) {
+		t.Errorf("not clamped")
+	}
+}
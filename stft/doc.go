@@ -0,0 +1,21 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+// Package stft provides short time fourier transforms.
+//
+// Package stft is part of http://zikichombo.org
+//
+// An analyzer, T, reads a sound.Source, or a slice of samples, in frames of
+// a given size which start every hop samples.  Each frame of each channel is
+// multiplied by a window, zero padded to a multiple of the frame size and
+// transformed with a real fft, giving a sequence of spectra in half complex
+// form together with the position in time of each frame.
+//
+// A synthesizer, Synth, turns a sequence of possibly modified frames back
+// into sound by weighted overlap-add, and is a sound.Source.  Frames are
+// given to Synth by a Framer, such as T or the result of Map.  COLA checks
+// whether a window satisfies the constant overlap-add condition for a hop.
+//
+// Buffers are reused from frame to frame, so analysis and synthesis of a
+// stream do not allocate once started.
+package stft
@@ -0,0 +1,329 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package stft
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/zikichombo/dsp/fft"
+	"github.com/zikichombo/dsp/wfn"
+	"github.com/zikichombo/sound"
+	"github.com/zikichombo/sound/freq"
+	"github.com/zikichombo/sound/sndbuf"
+)
+
+// Frame holds the spectra of all channels of one analysis frame.
+type Frame struct {
+	// Index is the number of the frame, starting at 0.
+	Index int
+	// Offset is the index of the first sample of the frame in the source,
+	// which is negative for the first frames if the analyzer is primed.
+	Offset int
+	// N is the number of samples of the frame preceding the end of the
+	// source, which is less than the frame size only for the last frames.
+	N int
+	// Time is the time of the first sample of the frame relative to the
+	// start of the source.
+	Time time.Duration
+	// HC holds the spectrum of each channel.
+	HC []fft.HalfComplex
+
+	ss []*fft.S
+}
+
+// S returns the spectrum of channel c of f as an *fft.S.  The result is
+// stored in f and is overwritten by the next frame.
+func (f *Frame) S(c int) *fft.S {
+	hc := f.HC[c]
+	if len(f.ss) != len(f.HC) {
+		f.ss = make([]*fft.S, len(f.HC))
+	}
+	if f.ss[c] == nil || f.ss[c].N() != len(hc) {
+		f.ss[c] = fft.NewSN(len(hc))
+	}
+	f.ss[c].FromHalfComplex(hc)
+	return f.ss[c]
+}
+
+// Power places the power of frequency bins 0 through n/2 of f, summed over
+// the channels, in dst and returns it, where n is the transform size.  If
+// dst does not have capacity n/2+1, then a new slice is allocated.
+func (f *Frame) Power(dst []float64) []float64 {
+	m := 1
+	if len(f.HC) > 0 {
+		m = len(f.HC[0])/2 + 1
+	}
+	if cap(dst) < m {
+		dst = make([]float64, m)
+	}
+	dst = dst[:m]
+	for i := range dst {
+		dst[i] = 0
+	}
+	for _, hc := range f.HC {
+		for i := range dst {
+			c := hc.Cmplx(i)
+			dst[i] += real(c)*real(c) + imag(c)*imag(c)
+		}
+	}
+	return dst
+}
+
+// T is a short time fourier transform analyzer.
+type T struct {
+	src   sound.Source
+	nC    int
+	size  int
+	hop   int
+	win   wfn.T
+	ft    *fft.RealBatch
+	in    [][]float64 // per channel, the samples of the current frame
+	bufs  [][]float64 // per channel, transform buffers
+	rbuf  []float64
+	valid int // number of samples in in preceding the end of src
+	eof   bool
+	lead  int
+	off   int // offset of the current frame
+	frame Frame
+}
+
+// New creates a new analyzer of src, whose frames have size samples and
+// start every hop samples.  Each frame is multiplied by win, which should
+// have length size.  If win is nil, then a periodic Hann window is used,
+// which unlike the symmetric one of wfn.New satisfies the constant
+// overlap-add condition when size is a multiple of hop and hop < size, see
+// COLA.  Frames are zero padded to transforms of size size*pad.
+//
+// The last frame is the last one containing a sample of src, missing samples
+// are taken to be 0.
+//
+// New returns a non-nil error if the arguments are inconsistent.
+func New(src sound.Source, size, hop int, win wfn.T, pad int) (*T, error) {
+	if size < 1 || hop < 1 || pad < 1 {
+		return nil, fmt.Errorf("invalid size %d, hop %d or pad %d", size, hop, pad)
+	}
+	if win == nil {
+		win = wfn.NewPeriodic(wfn.Hann, size)
+	}
+	if len(win) != size {
+		return nil, fmt.Errorf("wrong window length %d != %d", len(win), size)
+	}
+	nC := src.Channels()
+	if nC < 1 {
+		return nil, fmt.Errorf("invalid channels %d", nC)
+	}
+	n := size * pad
+	res := &T{
+		src:  src,
+		nC:   nC,
+		size: size,
+		hop:  hop,
+		win:  win,
+		ft:   fft.NewRealBatch(n, nC),
+		in:   make([][]float64, nC),
+		bufs: make([][]float64, nC),
+		rbuf: make([]float64, size*nC)}
+	for c := 0; c < nC; c++ {
+		res.in[c] = make([]float64, size)
+		res.bufs[c] = make([]float64, n)
+	}
+	res.frame.Index = -1
+	return res, nil
+}
+
+// NewSlice creates a new analyzer of the single channel samples d at
+// sample rate sr, as in New.
+func NewSlice(d []float64, sr freq.T, size, hop int, win wfn.T, pad int) (*T, error) {
+	return New(sndbuf.FromSlice(d, sr), size, hop, win, pad)
+}
+
+// Channels returns the number of channels of the source of t.
+func (t *T) Channels() int {
+	return t.nC
+}
+
+// SampleRate returns the sample rate of the source of t.
+func (t *T) SampleRate() freq.T {
+	return t.src.SampleRate()
+}
+
+// Size returns the number of samples in a frame.
+func (t *T) Size() int {
+	return t.size
+}
+
+// Hop returns the number of samples between the starts of frames.
+func (t *T) Hop() int {
+	return t.hop
+}
+
+// SetHop sets the number of samples between the start of the last frame
+// returned by Next and the next frame to h, or 1 if h < 1.  Hops set by
+// SetHop remain in effect until the next call to SetHop.
+//
+// A Synth assumes a constant hop, so changing the hop of a T read by a
+// Synth causes the Synth to return an error.
+func (t *T) SetHop(h int) {
+	if h < 1 {
+		h = 1
+	}
+	t.hop = h
+}
+
+// N returns the size of the transforms, which is the frame size times the
+// padding factor.
+func (t *T) N() int {
+	return t.ft.N()
+}
+
+// Win returns the window applied to frames.
+func (t *T) Win() wfn.T {
+	return t.win
+}
+
+// Scale sets whether the transforms are scaled, as in fft.Real.Scale.
+func (t *T) Scale(v bool) {
+	t.ft.Scale(v)
+}
+
+// SetWorkers sets the number of goroutines used to transform the channels
+// of a frame, as in fft.RealBatch.SetWorkers.
+func (t *T) SetWorkers(w int) {
+	t.ft.SetWorkers(w)
+}
+
+// SetPrime sets whether the source is preceded by Size()-Hop() zeros, so
+// that every sample of the source lies in the same number of frames.  This
+// is needed for exact resynthesis of the start of the source.  SetPrime
+// should be called before the first call to Next.
+func (t *T) SetPrime(v bool) {
+	t.lead = 0
+	if v && t.hop < t.size {
+		t.lead = t.size - t.hop
+	}
+}
+
+// Close closes the source of t.
+func (t *T) Close() error {
+	return t.src.Close()
+}
+
+// Next returns the next frame.  The result is stored in t and is overwritten
+// by the next call to Next.  Next returns io.EOF after the last frame, and
+// any other error returned from the source.
+func (t *T) Next() (*Frame, error) {
+	if e := t.advance(); e != nil {
+		return nil, e
+	}
+	if t.valid <= 0 {
+		return nil, io.EOF
+	}
+	for c, buf := range t.bufs {
+		copy(buf, t.in[c])
+		t.win.Apply(buf[:t.size])
+		for i := t.size; i < len(buf); i++ {
+			buf[i] = 0
+		}
+	}
+	hcs, e := t.ft.Do(t.bufs)
+	if e != nil {
+		return nil, e
+	}
+	f := &t.frame
+	f.Index++
+	if f.Index == 0 {
+		t.off = -t.lead
+	} else {
+		t.off += t.hop
+	}
+	f.Offset = t.off
+	f.N = t.valid
+	f.Time = 0
+	if sr := t.src.SampleRate(); sr > 0 {
+		f.Time = time.Duration(float64(f.Offset) / sr.Float64() * float64(time.Second))
+	}
+	f.HC = hcs
+	return f, nil
+}
+
+// advance moves the input to the next frame.
+func (t *T) advance() error {
+	if t.frame.Index == -1 {
+		for _, ch := range t.in {
+			for i := 0; i < t.lead; i++ {
+				ch[i] = 0
+			}
+		}
+		if e := t.fill(t.lead); e != nil {
+			return e
+		}
+		if t.valid == t.lead {
+			t.valid = 0
+		}
+		return nil
+	}
+	if t.hop >= t.size {
+		t.valid = 0
+		if e := t.skip(t.hop - t.size); e != nil {
+			return e
+		}
+		return t.fill(0)
+	}
+	for _, ch := range t.in {
+		copy(ch, ch[t.hop:])
+	}
+	t.valid -= t.hop
+	if t.valid < 0 {
+		t.valid = 0
+	}
+	return t.fill(t.valid)
+}
+
+// fill reads samples from the source into t.in starting at i, zeroing
+// what the source does not provide.
+func (t *T) fill(i int) error {
+	for i < t.size && !t.eof {
+		n, e := t.src.Receive(t.rbuf[:(t.size-i)*t.nC])
+		if e == io.EOF {
+			t.eof = true
+			break
+		}
+		if e != nil {
+			return e
+		}
+		for c, ch := range t.in {
+			copy(ch[i:i+n], t.rbuf[c*n:(c+1)*n])
+		}
+		i += n
+	}
+	t.valid = i
+	for _, ch := range t.in {
+		for j := i; j < t.size; j++ {
+			ch[j] = 0
+		}
+	}
+	return nil
+}
+
+// skip discards n frames from the source.
+func (t *T) skip(n int) error {
+	for n > 0 && !t.eof {
+		m := n
+		if m > t.size {
+			m = t.size
+		}
+		r, e := t.src.Receive(t.rbuf[:m*t.nC])
+		if e == io.EOF {
+			t.eof = true
+			break
+		}
+		if e != nil {
+			return e
+		}
+		n -= r
+	}
+	return nil
+}
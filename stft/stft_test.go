@@ -0,0 +1,203 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package stft
+
+import (
+	"io"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/zikichombo/dsp/fft"
+	"github.com/zikichombo/dsp/wfn"
+	"github.com/zikichombo/sound"
+	"github.com/zikichombo/sound/freq"
+	"github.com/zikichombo/sound/sndbuf"
+)
+
+func TestT(t *testing.T) {
+	for _, c := range []struct{ nC, nF, size, hop, pad int }{
+		{1, 1000, 64, 16, 1},
+		{2, 1000, 64, 32, 2},
+		{3, 777, 50, 20, 1},
+		{2, 500, 32, 40, 3},
+		{1, 10, 64, 16, 2},
+	} {
+		testT(c.nC, c.nF, c.size, c.hop, c.pad, t)
+	}
+}
+
+func testT(nC, nF, size, hop, pad int, t *testing.T) {
+	sr := 8000 * freq.Hertz
+	inter := make([]float64, nC*nF)
+	for i := range inter {
+		inter[i] = rand.Float64()*2 - 1
+	}
+	win := wfn.New(wfn.Hamming, size)
+	a, e := New(sndbuf.FromSliceChans(inter, nC, sr), size, hop, win, pad)
+	if e != nil {
+		t.Fatal(e)
+	}
+	ft := fft.NewReal(size * pad)
+	buf := make([]float64, size*pad)
+	i := 0
+	for {
+		f, e := a.Next()
+		if e == io.EOF {
+			break
+		}
+		if e != nil {
+			t.Fatal(e)
+		}
+		if f.Index != i || f.Offset != i*hop {
+			t.Fatalf("frame %d: got index %d offset %d", i, f.Index, f.Offset)
+		}
+		exp := time.Duration(i*hop) * time.Second / 8000
+		if d := f.Time - exp; d < -time.Microsecond || d > time.Microsecond {
+			t.Errorf("frame %d: got time %s not %s", i, f.Time, exp)
+		}
+		for c := 0; c < nC; c++ {
+			for j := range buf {
+				buf[j] = 0
+				k := f.Offset + j
+				if j < size && k < nF {
+					buf[j] = inter[k*nC+c] * win[j]
+				}
+			}
+			hc := ft.Do(buf)
+			for j, v := range f.HC[c] {
+				if math.Abs(v-hc[j]) > 1e-10 {
+					t.Fatalf("frame %d channel %d bin %d: got %f not %f", i, c, j, v, hc[j])
+				}
+			}
+			s := f.S(c)
+			if s.N() != size*pad {
+				t.Errorf("got S of size %d not %d", s.N(), size*pad)
+			}
+		}
+		i++
+	}
+	if exp := (nF + hop - 1) / hop; i != exp {
+		t.Errorf("nC=%d nF=%d size=%d hop=%d: got %d frames not %d", nC, nF, size, hop, i, exp)
+	}
+}
+
+func TestTErrs(t *testing.T) {
+	d := make([]float64, 100)
+	if _, e := NewSlice(d, 8000*freq.Hertz, 32, 0, nil, 1); e == nil {
+		t.Errorf("expected hop error")
+	}
+	if _, e := NewSlice(d, 8000*freq.Hertz, 32, 8, wfn.New(wfn.Hann, 31), 1); e == nil {
+		t.Errorf("expected window length error")
+	}
+	if _, e := New(noChans{sndbuf.FromSlice(d, 8000*freq.Hertz)}, 32, 8, nil, 1); e == nil {
+		t.Errorf("expected channels error")
+	}
+	a, e := NewSlice(nil, 8000*freq.Hertz, 32, 8, nil, 1)
+	if e != nil {
+		t.Fatal(e)
+	}
+	if _, e := a.Next(); e != io.EOF {
+		t.Errorf("expected io.EOF, got %v", e)
+	}
+}
+
+// noChans is a source claiming to have no channels.
+type noChans struct {
+	sound.Source
+}
+
+func (s noChans) Channels() int {
+	return 0
+}
+
+func TestFramePower(t *testing.T) {
+	for _, n := range []int{1, 2, 15, 64} {
+		f := &Frame{HC: []fft.HalfComplex{make(fft.HalfComplex, n), make(fft.HalfComplex, n)}}
+		for _, hc := range f.HC {
+			for i := range hc {
+				hc[i] = rand.Float64()*2 - 1
+			}
+		}
+		pow := f.Power(nil)
+		if len(pow) != n/2+1 {
+			t.Fatalf("n=%d: got %d bins not %d", n, len(pow), n/2+1)
+		}
+		for i, p := range pow {
+			exp := 0.0
+			for _, hc := range f.HC {
+				c := hc.Cmplx(i)
+				exp += real(c)*real(c) + imag(c)*imag(c)
+			}
+			if math.Abs(p-exp) > 1e-12 {
+				t.Errorf("n=%d bin %d: got %f not %f", n, i, p, exp)
+			}
+		}
+		if res := f.Power(pow); &res[0] != &pow[0] {
+			t.Errorf("n=%d: dst not reused", n)
+		}
+	}
+}
+
+func TestTAllocs(t *testing.T) {
+	d := make([]float64, 1<<16)
+	a, e := NewSlice(d, 8000*freq.Hertz, 512, 128, nil, 2)
+	if e != nil {
+		t.Fatal(e)
+	}
+	a.Next()
+	allocs := testing.AllocsPerRun(100, func() {
+		a.Next()
+	})
+	if allocs != 0 {
+		t.Errorf("got %f allocations per frame", allocs)
+	}
+}
+
+func TestTSetHop(t *testing.T) {
+	d := make([]float64, 500)
+	for i := range d {
+		d[i] = rand.Float64()
+	}
+	size := 32
+	a, e := NewSlice(d, 8000*freq.Hertz, size, 8, nil, 1)
+	if e != nil {
+		t.Fatal(e)
+	}
+	ft := fft.NewReal(size)
+	buf := make([]float64, size)
+	hops := []int{7, 13, 40, 1}
+	off := 0
+	for i := 0; ; i++ {
+		f, e := a.Next()
+		if e == io.EOF {
+			break
+		}
+		if e != nil {
+			t.Fatal(e)
+		}
+		if f.Offset != off {
+			t.Fatalf("frame %d: got offset %d not %d", i, f.Offset, off)
+		}
+		for j := range buf {
+			buf[j] = 0
+			if off+j < len(d) {
+				buf[j] = d[off+j] * a.Win()[j]
+			}
+		}
+		hc := ft.Do(buf)
+		for j, v := range f.HC[0] {
+			if math.Abs(v-hc[j]) > 1e-10 {
+				t.Fatalf("frame %d bin %d: got %f not %f", i, j, v, hc[j])
+			}
+		}
+		h := hops[i%len(hops)]
+		a.SetHop(h)
+		off += h
+	}
+	if off < len(d) {
+		t.Errorf("stopped at %d before %d", off, len(d))
+	}
+}
@@ -0,0 +1,331 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package stft
+
+import (
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/zikichombo/dsp/fft"
+	"github.com/zikichombo/dsp/wfn"
+	"github.com/zikichombo/sound"
+	"github.com/zikichombo/sound/cil"
+	"github.com/zikichombo/sound/freq"
+)
+
+// Framer is a source of frames, such as T.
+type Framer interface {
+	sound.Form
+	// Size returns the number of samples in a frame.
+	Size() int
+	// Hop returns the number of samples between the starts of frames.
+	Hop() int
+	// N returns the size of the transforms of frames.
+	N() int
+	// Win returns the analysis window of frames.
+	Win() wfn.T
+	// Next returns the next frame, or io.EOF after the last one.
+	Next() (*Frame, error)
+	// Close closes the framer.
+	Close() error
+}
+
+type mapper struct {
+	Framer
+	fn func(f *Frame) error
+}
+
+func (m *mapper) Next() (*Frame, error) {
+	f, e := m.Framer.Next()
+	if e != nil {
+		return nil, e
+	}
+	if e := m.fn(f); e != nil {
+		return nil, e
+	}
+	return f, nil
+}
+
+// Map returns a Framer giving the frames of fr after calling fn on each of
+// them.  fn may modify the spectra of the frame.  If fn returns a non-nil
+// error, then Next returns it.
+func Map(fr Framer, fn func(f *Frame) error) Framer {
+	return &mapper{Framer: fr, fn: fn}
+}
+
+// COLA returns the sum c of copies of w shifted by multiples of hop, and
+// whether or not that sum is constant to within a relative tolerance tol,
+// in which case w satisfies the constant overlap-add condition for hop.
+// If hop < 1 or w is empty, then COLA returns (0, false).
+func COLA(w wfn.T, hop int, tol float64) (float64, bool) {
+	if hop < 1 || len(w) == 0 {
+		return 0, false
+	}
+	env := make([]float64, hop)
+	for i, v := range w {
+		env[i%hop] += v
+	}
+	min, max := math.Inf(1), 0.0
+	for _, v := range env {
+		min = math.Min(min, v)
+		max = math.Max(max, v)
+	}
+	return (min + max) / 2, max-min <= tol*math.Abs(max)
+}
+
+// olaEnv returns the sum of copies of a*s shifted by multiples of hop for
+// one period of hop samples.
+func olaEnv(a, s wfn.T, hop int) []float64 {
+	env := make([]float64, hop)
+	for i := range a {
+		env[i%hop] += a[i] * s[i]
+	}
+	return env
+}
+
+// Synth resynthesizes sound from a sequence of frames by weighted
+// overlap-add.
+//
+// The inverse transform of each frame is multiplied by a synthesis window
+// and added to the output at the offset of the frame.  The output is
+// normalized by the sum of the products of analysis and synthesis windows
+// at each sample, so that resynthesis of unmodified frames from a primed T
+// gives back its source to within rounding, whether or not the windows
+// satisfy the constant overlap-add condition.
+//
+// Synth implements sound.Source.
+type Synth struct {
+	fr   Framer
+	nC   int
+	size int
+	hop  int
+	ana  wfn.T
+	syn  wfn.T
+	ft   *fft.RealBatch
+	gain float64
+	hcs  []fft.HalfComplex
+	acc  [][]float64 // per channel, output for [pos, pos+size)
+	env  []float64   // window sums for [pos, pos+size)
+	tiny float64
+	pos  int
+	next int // offset of next frame
+	nFr  int
+	end  int
+	q    [][]float64 // per channel, samples ready for Receive
+	qi   int
+	eof  bool
+	err  error
+}
+
+// NewSynth creates a new Synth of the frames given by fr, using the
+// synthesis window syn.  If syn is nil, then fr.Win() is used.
+//
+// NewSynth returns a non-nil error if fr has no channels, if syn does not
+// have length fr.Size(), or if the overlap-add of the windows is not
+// bounded away from 0, which is the case for example when fr.Hop() >
+// fr.Size().  The hop of fr must not change after NewSynth is called, see
+// T.SetHop.
+func NewSynth(fr Framer, syn wfn.T) (*Synth, error) {
+	nC, n := fr.Channels(), fr.N()
+	if nC < 1 {
+		return nil, fmt.Errorf("invalid channels %d", nC)
+	}
+	size, hop, ana := fr.Size(), fr.Hop(), fr.Win()
+	if syn == nil {
+		syn = ana
+	}
+	if len(syn) != size {
+		return nil, fmt.Errorf("wrong window length %d != %d", len(syn), size)
+	}
+	env := olaEnv(ana, syn, hop)
+	min, max := math.Inf(1), 0.0
+	for _, v := range env {
+		min = math.Min(min, math.Abs(v))
+		max = math.Max(max, math.Abs(v))
+	}
+	if min <= 1e-9*max || max == 0 {
+		return nil, fmt.Errorf("windows do not overlap-add for hop %d", hop)
+	}
+	res := &Synth{
+		fr:   fr,
+		nC:   nC,
+		size: size,
+		hop:  hop,
+		ana:  ana,
+		syn:  syn,
+		ft:   fft.NewRealBatch(n, nC),
+		gain: 1,
+		hcs:  make([]fft.HalfComplex, nC),
+		acc:  make([][]float64, nC),
+		env:  make([]float64, size),
+		tiny: 1e-9 * max,
+		q:    make([][]float64, nC)}
+	for c := 0; c < nC; c++ {
+		res.hcs[c] = fft.HalfComplex(make([]float64, n))
+		res.acc[c] = make([]float64, size)
+		res.q[c] = make([]float64, 0, size)
+	}
+	return res, nil
+}
+
+// Scale sets whether the frames are scaled, as in T.Scale.  It should be
+// set to the same value as for the analyzer giving the frames, the default
+// is true.
+func (s *Synth) Scale(v bool) {
+	// the inverse is always scaled, which undoes the scaling of scaled
+	// frames and leaves unscaled ones multiplied by sqrt(N).
+	s.gain = 1
+	if !v {
+		s.gain = 1 / math.Sqrt(float64(s.ft.N()))
+	}
+}
+
+// Channels returns the number of channels.
+func (s *Synth) Channels() int {
+	return s.nC
+}
+
+// SampleRate returns the sample rate.
+func (s *Synth) SampleRate() freq.T {
+	return s.fr.SampleRate()
+}
+
+// Close closes the framer of s.
+func (s *Synth) Close() error {
+	return s.fr.Close()
+}
+
+// Receive implements sound.Source.
+func (s *Synth) Receive(d []float64) (int, error) {
+	nC := s.nC
+	if len(d)%nC != 0 {
+		return 0, sound.ErrChannelAlignment
+	}
+	if len(d) == 0 {
+		return 0, nil
+	}
+	nF := len(d) / nC
+	f := 0
+	for f < nF {
+		if s.qi == len(s.q[0]) {
+			if s.eof || s.err != nil {
+				break
+			}
+			for c := range s.q {
+				s.q[c] = s.q[c][:0]
+			}
+			s.qi = 0
+			s.err = s.pull()
+			continue
+		}
+		n := len(s.q[0]) - s.qi
+		if n > nF-f {
+			n = nF - f
+		}
+		for c, q := range s.q {
+			copy(d[c*nF+f:c*nF+f+n], q[s.qi:s.qi+n])
+		}
+		s.qi += n
+		f += n
+	}
+	if f == 0 {
+		if s.err != nil {
+			return 0, s.err
+		}
+		return 0, io.EOF
+	}
+	if f < nF {
+		cil.Compact(d, nC, f)
+	}
+	return f, nil
+}
+
+// pull reads the next frame from s.fr, making the output before it ready.
+func (s *Synth) pull() error {
+	fr, e := s.fr.Next()
+	if e == io.EOF {
+		s.eof = true
+		s.finish(s.end)
+		return nil
+	}
+	if e != nil {
+		return e
+	}
+	if h := s.fr.Hop(); h != s.hop {
+		return fmt.Errorf("hop changed from %d to %d", s.hop, h)
+	}
+	if s.nFr == 0 {
+		s.pos = fr.Offset
+	} else if fr.Offset != s.next {
+		return fmt.Errorf("wrong frame offset %d != %d", fr.Offset, s.next)
+	}
+	if len(fr.HC) != s.nC {
+		return fmt.Errorf("wrong channels %d != %d", len(fr.HC), s.nC)
+	}
+	s.finish(fr.Offset)
+	for c, hc := range fr.HC {
+		if len(hc) != len(s.hcs[c]) {
+			return fmt.Errorf("wrong length %d != %d", len(hc), len(s.hcs[c]))
+		}
+		copy(s.hcs[c], hc)
+	}
+	chans, e := s.ft.Inv(s.hcs)
+	if e != nil {
+		return e
+	}
+	for c, ch := range chans {
+		acc := s.acc[c]
+		for i, w := range s.syn {
+			acc[i] += ch[i] * w * s.gain
+		}
+	}
+	for i, w := range s.syn {
+		s.env[i] += s.ana[i] * w
+	}
+	s.nFr++
+	s.next = fr.Offset + s.hop
+	if e := fr.Offset + fr.N; e > s.end {
+		s.end = e
+	}
+	return nil
+}
+
+// finish makes the output from s.pos to `to' ready, normalizing it by the
+// window sums, and moves s.pos to `to'.  Output before sample 0 is dropped.
+func (s *Synth) finish(to int) {
+	for p := s.pos; p < to; p++ {
+		if p < 0 {
+			continue
+		}
+		i := p - s.pos
+		for c, acc := range s.acc {
+			v := 0.0
+			if i < s.size && s.env[i] > s.tiny {
+				v = acc[i] / s.env[i]
+			}
+			s.q[c] = append(s.q[c], v)
+		}
+	}
+	k := to - s.pos
+	if k > s.size {
+		k = s.size
+	}
+	if k <= 0 {
+		return
+	}
+	for _, acc := range s.acc {
+		shift(acc, k)
+	}
+	shift(s.env, k)
+	s.pos = to
+}
+
+// shift moves d left by k, zeroing the end.
+func shift(d []float64, k int) {
+	copy(d, d[k:])
+	for i := len(d) - k; i < len(d); i++ {
+		d[i] = 0
+	}
+}
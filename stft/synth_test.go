@@ -0,0 +1,238 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package stft
+
+import (
+	"io"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/zikichombo/dsp/wfn"
+	"github.com/zikichombo/sound/freq"
+	"github.com/zikichombo/sound/sndbuf"
+)
+
+func TestSynth(t *testing.T) {
+	for _, c := range []struct {
+		nC, nF, size, hop, pad int
+		win                    wfn.T
+	}{
+		{1, 1000, 64, 32, 1, nil},
+		{2, 1000, 64, 16, 2, nil},
+		{3, 777, 60, 20, 1, wfn.New(wfn.Hamming, 60)},
+		{1, 333, 64, 64, 1, wfn.New(wfn.Blackman, 66)[1:65]},
+		{2, 10, 64, 16, 1, nil},
+	} {
+		testSynth(c.nC, c.nF, c.size, c.hop, c.pad, c.win, t)
+	}
+}
+
+func testSynth(nC, nF, size, hop, pad int, win wfn.T, t *testing.T) {
+	inter := make([]float64, nC*nF)
+	for i := range inter {
+		inter[i] = rand.Float64()*2 - 1
+	}
+	a, e := New(sndbuf.FromSliceChans(inter, nC, 8000*freq.Hertz), size, hop, win, pad)
+	if e != nil {
+		t.Fatal(e)
+	}
+	a.SetPrime(true)
+	s, e := NewSynth(Map(a, func(f *Frame) error { return nil }), nil)
+	if e != nil {
+		t.Fatal(e)
+	}
+	if s.Channels() != nC || s.SampleRate() != 8000*freq.Hertz {
+		t.Errorf("wrong form")
+	}
+	buf := make([]float64, 37*nC)
+	i := 0
+	for {
+		n, e := s.Receive(buf)
+		if e == io.EOF {
+			break
+		}
+		if e != nil {
+			t.Fatal(e)
+		}
+		for f := 0; f < n; f++ {
+			for c := 0; c < nC; c++ {
+				exp := inter[(i+f)*nC+c]
+				if math.Abs(buf[c*n+f]-exp) > 1e-10 {
+					t.Fatalf("size=%d hop=%d sample %d channel %d: got %f not %f", size, hop, i+f, c, buf[c*n+f], exp)
+				}
+			}
+		}
+		i += n
+	}
+	if i != nF {
+		t.Errorf("size=%d hop=%d: got %d samples not %d", size, hop, i, nF)
+	}
+}
+
+func TestSynthModify(t *testing.T) {
+	// keeping only dc gives frames of constant 3*0.5 for the hann
+	// window, summing to 3*0.5*2 at quarter overlap, normalized by
+	// the overlap-add of the squared window, 1.5.
+	d := make([]float64, 512)
+	for i := range d {
+		d[i] = 3
+	}
+	a, _ := NewSlice(d, 8000*freq.Hertz, 32, 8, nil, 1)
+	a.SetPrime(true)
+	s, e := NewSynth(Map(a, func(f *Frame) error {
+		for _, hc := range f.HC {
+			for i := 1; i < len(hc); i++ {
+				hc[i] = 0
+			}
+		}
+		return nil
+	}), wfn.T(make([]float64, 32)))
+	if e == nil {
+		t.Errorf("expected overlap-add error")
+	}
+	s, e = NewSynth(Map(a, func(f *Frame) error {
+		for _, hc := range f.HC {
+			for i := 1; i < len(hc); i++ {
+				hc[i] = 0
+			}
+		}
+		return nil
+	}), nil)
+	if e != nil {
+		t.Fatal(e)
+	}
+	buf := make([]float64, 512)
+	n, _ := s.Receive(buf)
+	if n != 512 {
+		t.Fatalf("got %d samples", n)
+	}
+	for i := 32; i < 480; i++ {
+		if math.Abs(buf[i]-2) > 1e-10 {
+			t.Errorf("sample %d: got %f not 2", i, buf[i])
+		}
+	}
+}
+
+func TestSynthUnscaled(t *testing.T) {
+	d := make([]float64, 300)
+	for i := range d {
+		d[i] = rand.Float64()
+	}
+	a, _ := NewSlice(d, 8000*freq.Hertz, 48, 12, nil, 2)
+	a.SetPrime(true)
+	a.Scale(false)
+	s, e := NewSynth(a, nil)
+	if e != nil {
+		t.Fatal(e)
+	}
+	s.Scale(false)
+	buf := make([]float64, 400)
+	n, _ := s.Receive(buf)
+	if n != len(d) {
+		t.Fatalf("got %d samples not %d", n, len(d))
+	}
+	for i, v := range buf[:n] {
+		if math.Abs(v-d[i]) > 1e-10 {
+			t.Errorf("sample %d: got %f not %f", i, v, d[i])
+		}
+	}
+}
+
+// noChansFr is a framer claiming to have no channels.
+type noChansFr struct {
+	Framer
+}
+
+func (f noChansFr) Channels() int {
+	return 0
+}
+
+func TestSynthEmpty(t *testing.T) {
+	a, _ := NewSlice(make([]float64, 100), 8000*freq.Hertz, 32, 8, nil, 1)
+	s, e := NewSynth(a, nil)
+	if e != nil {
+		t.Fatal(e)
+	}
+	if n, e := s.Receive(nil); n != 0 || e != nil {
+		t.Errorf("got %d, %v for empty receive", n, e)
+	}
+	if n, e := s.Receive(make([]float64, 200)); n != 100 || e != nil {
+		t.Errorf("got %d, %v after empty receive", n, e)
+	}
+	if n, e := s.Receive(nil); n != 0 || e != nil {
+		t.Errorf("got %d, %v for empty receive at end", n, e)
+	}
+	if _, e := NewSynth(noChansFr{a}, nil); e == nil {
+		t.Errorf("expected channels error")
+	}
+}
+
+func TestSynthSetHop(t *testing.T) {
+	d := make([]float64, 1024)
+	a, e := NewSlice(d, 8000*freq.Hertz, 64, 16, nil, 1)
+	if e != nil {
+		t.Fatal(e)
+	}
+	s, e := NewSynth(a, nil)
+	if e != nil {
+		t.Fatal(e)
+	}
+	buf := make([]float64, 16)
+	if _, e := s.Receive(buf); e != nil {
+		t.Fatal(e)
+	}
+	a.SetHop(8)
+	for i := 0; i < 100; i++ {
+		if _, e = s.Receive(buf); e != nil {
+			break
+		}
+	}
+	if e == nil || e == io.EOF {
+		t.Errorf("expected hop change error, got %v", e)
+	}
+}
+
+func TestCOLA(t *testing.T) {
+	if c, ok := COLA(wfn.NewPeriodic(wfn.Hann, 64), 32, 1e-12); !ok || math.Abs(c-1) > 1e-12 {
+		t.Errorf("periodic hann at half overlap: got %f %t", c, ok)
+	}
+	if c, ok := COLA(wfn.NewPeriodic(wfn.Hann, 64), 16, 1e-12); !ok || math.Abs(c-2) > 1e-12 {
+		t.Errorf("periodic hann at quarter overlap: got %f %t", c, ok)
+	}
+	if _, ok := COLA(wfn.New(wfn.Hann, 64), 32, 1e-6); ok {
+		t.Errorf("symmetric hann should not satisfy cola")
+	}
+	if _, ok := COLA(wfn.NewPeriodic(wfn.Hann, 64), 24, 1e-6); ok {
+		t.Errorf("hann at hop 24 of 64 should not satisfy cola")
+	}
+	if c, ok := COLA(wfn.NewPeriodic(wfn.Hann, 64), 0, 1e-6); ok || c != 0 {
+		t.Errorf("hop 0: got %f %t", c, ok)
+	}
+	if c, ok := COLA(wfn.NewPeriodic(wfn.Hann, 64), -16, 1e-6); ok || c != 0 {
+		t.Errorf("negative hop: got %f %t", c, ok)
+	}
+	if c, ok := COLA(nil, 16, 1e-6); ok || c != 0 {
+		t.Errorf("empty window: got %f %t", c, ok)
+	}
+}
+
+func TestSynthAllocs(t *testing.T) {
+	d := make([]float64, 1<<16)
+	a, _ := NewSlice(d, 8000*freq.Hertz, 512, 128, nil, 1)
+	s, e := NewSynth(a, nil)
+	if e != nil {
+		t.Fatal(e)
+	}
+	buf := make([]float64, 256)
+	s.Receive(buf)
+	allocs := testing.AllocsPerRun(100, func() {
+		s.Receive(buf)
+	})
+	if allocs != 0 {
+		t.Errorf("got %f allocations", allocs)
+	}
+}
+
+var _ Framer = (*T)(nil)
@@ -0,0 +1,36 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package wfn
+
+import "math"
+
+// Kaiser returns the Kaiser window function with shape parameter beta.
+// Larger beta gives lower side lobes and a wider main lobe, beta 0 gives
+// the rectangular window.
+func Kaiser(beta float64) func(float64) float64 {
+	d := I0(beta)
+	return func(i float64) float64 {
+		x := i / math.Pi
+		// New may give endpoints slightly beyond pi by rounding.
+		if x < -1-1e-9 || x > 1+1e-9 {
+			return 0
+		}
+		return I0(beta*math.Sqrt(math.Max(1-x*x, 0))) / d
+	}
+}
+
+// I0 returns the modified Bessel function of the first kind of order 0.
+func I0(x float64) float64 {
+	h := x / 2
+	res, t := 1.0, 1.0
+	for k := 1; k < 500; k++ {
+		t *= h / float64(k)
+		tt := t * t
+		res += tt
+		if tt < 1e-17*res {
+			break
+		}
+	}
+	return res
+}
@@ -0,0 +1,26 @@
+// Copyright 2018 The ZikiChombo Authors. All rights reserved.  Use of this source
+// code is governed by a license that can be found in the License file.
+
+package wfn
+
+import (
+	"math"
+	"testing"
+)
+
+func TestKaiserEnds(t *testing.T) {
+	for _, beta := range []float64{0, 2, 5, 8.6} {
+		exp := 1 / I0(beta)
+		for n := 2; n <= 256; n++ {
+			w := New(Kaiser(beta), n)
+			for _, v := range []float64{w[0], w[n-1]} {
+				if math.Abs(v-exp) > 1e-12 {
+					t.Errorf("beta %.1f n %d: got end %g not %g", beta, n, v, exp)
+				}
+			}
+		}
+	}
+	if v := Kaiser(5)(1.1 * math.Pi); v != 0 {
+		t.Errorf("got %g outside window", v)
+	}
+}
@@ -22,6 +22,13 @@ func New(f func(float64) float64, n int) T {
 	return T(res)
 }
 
+// NewPeriodic is like New but returns the first n values of a window
+// of n+1 values, so that copies of the result shifted by divisors of n
+// may sum to a constant, as is desirable in overlap-add.
+func NewPeriodic(f func(float64) float64, n int) T {
+	return New(f, n+1)[:n]
+}
+
 // Apply applies the window t to the data t.
 // Apply panics if len(d) > len(t).
 func (t T) Apply(d []float64) {